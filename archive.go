@@ -0,0 +1,383 @@
+package gblob
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+)
+
+var (
+	// ErrInvalidArchive indicates that the data does not end with a valid
+	// archive trailer or that its index is inconsistent.
+	ErrInvalidArchive = errors.New("invalid archive")
+
+	// ErrEntryNotFound indicates that an archive does not contain an entry
+	// with the requested name.
+	ErrEntryNotFound = errors.New("entry not found")
+)
+
+// archiveMagic identifies the trailer of an archive.
+var archiveMagic = []byte("GBAR")
+
+// archiveTrailerSize is the size of the index offset, the index size, the
+// checksum algorithm, the byte order marker and the magic.
+const archiveTrailerSize = 8 + 8 + 1 + 2 + 4
+
+// archiveEntryMinSize is the smallest number of bytes that an ArchiveEntry
+// occupies in the index, which is the case when its name and checksum are
+// empty.
+const archiveEntryMinSize = 8 + 8 + 8 + 8
+
+// ArchiveEntry describes a single entry of an archive.
+type ArchiveEntry struct {
+
+	// Name is the unique name of the entry.
+	Name string
+
+	// Offset is the position of the packed entry data from the start of the
+	// archive.
+	Offset uint64
+
+	// Size is the number of bytes that the packed entry data occupies.
+	Size uint64
+
+	// Checksum is the checksum of the packed entry data, using the checksum
+	// algorithm of the archive.
+	Checksum []byte
+}
+
+// NewArchiveWriter creates a new ArchiveWriter that writes to the specified
+// out Writer in the specified byte order.
+func NewArchiveWriter(out io.Writer, order ByteOrder) (*ArchiveWriter, error) {
+	result := &ArchiveWriter{
+		order:    order,
+		checksum: ChecksumCRC32C,
+		names:    make(map[string]struct{}),
+	}
+	result.out.out = out
+	writer, err := newOrderedWriter(&result.out, order)
+	if err != nil {
+		return nil, err
+	}
+	result.encoder = &PackedEncoder{
+		out:   writer,
+		order: order,
+	}
+	return result, nil
+}
+
+// ArchiveWriter writes many named entries, each encoded by a PackedEncoder,
+// followed by an index that allows an ArchiveReader to decode individual
+// entries without reading the whole archive.
+//
+// The index is stored as a packed []ArchiveEntry after the last entry and is
+// followed by a fixed-size trailer that holds its position, the checksum
+// algorithm, a byte order marker and the "GBAR" magic.
+type ArchiveWriter struct {
+	out      archiveOutput
+	order    ByteOrder
+	encoder  *PackedEncoder
+	checksum ChecksumAlgorithm
+	entries  []ArchiveEntry
+	names    map[string]struct{}
+}
+
+// SetChecksum configures the algorithm that is used to compute the checksum
+// of each entry. The default is ChecksumCRC32C. It needs to be called before
+// any entries are written.
+func (w *ArchiveWriter) SetChecksum(algorithm ChecksumAlgorithm) error {
+	if err := algorithm.validate(); err != nil {
+		return err
+	}
+	w.checksum = algorithm
+	return nil
+}
+
+// Encode writes the specified source value as an entry with the specified
+// name, which needs to be unique within the archive.
+func (w *ArchiveWriter) Encode(name string, source any) error {
+	if _, ok := w.names[name]; ok {
+		return fmt.Errorf("duplicate entry: %s", name)
+	}
+	offset := w.out.offset
+	w.out.hash = w.checksum.newHash()
+	if err := w.encoder.Encode(source); err != nil {
+		return fmt.Errorf("entry %s: %w", name, err)
+	}
+	var checksum []byte
+	if w.out.hash != nil {
+		checksum = w.out.hash.Sum(nil)
+		w.out.hash = nil
+	}
+	w.entries = append(w.entries, ArchiveEntry{
+		Name:     name,
+		Offset:   offset,
+		Size:     w.out.offset - offset,
+		Checksum: checksum,
+	})
+	w.names[name] = struct{}{}
+	return nil
+}
+
+// Close writes the index and the trailer of the archive. It does not close
+// the underlying writer.
+func (w *ArchiveWriter) Close() error {
+	indexOffset := w.out.offset
+	if err := w.encoder.Encode(w.entries); err != nil {
+		return err
+	}
+	writer, err := newOrderedWriter(w.out.out, w.order)
+	if err != nil {
+		return err
+	}
+	if err := writer.WriteUint64(indexOffset); err != nil {
+		return err
+	}
+	if err := writer.WriteUint64(w.out.offset - indexOffset); err != nil {
+		return err
+	}
+	if err := writer.WriteUint8(uint8(w.checksum)); err != nil {
+		return err
+	}
+	if err := writer.WriteUint16(byteOrderMarker); err != nil {
+		return err
+	}
+	return writer.WriteBytes(archiveMagic)
+}
+
+// archiveOutput is an io.Writer that keeps track of the number of bytes
+// written and feeds an optional hash.
+type archiveOutput struct {
+	out    io.Writer
+	offset uint64
+	hash   hash.Hash
+}
+
+func (o *archiveOutput) Write(data []byte) (int, error) {
+	n, err := o.out.Write(data)
+	o.offset += uint64(n)
+	if o.hash != nil {
+		o.hash.Write(data[:n])
+	}
+	return n, err
+}
+
+// OpenArchive reads the index of an archive of the specified size from the
+// in ReaderAt. Entries are only read when they are decoded.
+func OpenArchive(in io.ReaderAt, size int64) (*ArchiveReader, error) {
+	if size < archiveTrailerSize {
+		return nil, ErrInvalidArchive
+	}
+	trailer := make([]byte, archiveTrailerSize)
+	if _, err := in.ReadAt(trailer, size-archiveTrailerSize); err != nil {
+		return nil, err
+	}
+	result, indexOffset, indexSize, err := parseArchiveTrailer(trailer, uint64(size))
+	if err != nil {
+		return nil, err
+	}
+	index := make([]byte, indexSize)
+	if _, err := in.ReadAt(index, int64(indexOffset)); err != nil {
+		return nil, err
+	}
+	if err := result.parseIndex(index, indexOffset); err != nil {
+		return nil, err
+	}
+	result.in = in
+	return result, nil
+}
+
+// OpenArchiveBuffer reads the index of an archive that is held in memory,
+// such as a memory-mapped file. Entries are decoded directly from the data
+// buffer, which allows them to be decoded in zero-copy mode.
+func OpenArchiveBuffer(data []byte) (*ArchiveReader, error) {
+	if len(data) < archiveTrailerSize {
+		return nil, ErrInvalidArchive
+	}
+	trailer := data[len(data)-archiveTrailerSize:]
+	result, indexOffset, indexSize, err := parseArchiveTrailer(trailer, uint64(len(data)))
+	if err != nil {
+		return nil, err
+	}
+	index := data[indexOffset : indexOffset+indexSize]
+	if err := result.parseIndex(index, indexOffset); err != nil {
+		return nil, err
+	}
+	result.data = data
+	return result, nil
+}
+
+// ArchiveReader provides random access to the entries of an archive that
+// was written by an ArchiveWriter.
+type ArchiveReader struct {
+	in       io.ReaderAt
+	data     []byte
+	order    ByteOrder
+	checksum ChecksumAlgorithm
+	entries  []ArchiveEntry
+	lookup   map[string]int
+}
+
+// Entries returns the entries of the archive in the order in which they were
+// written.
+func (r *ArchiveReader) Entries() []ArchiveEntry {
+	return r.entries
+}
+
+// Entry returns the entry with the specified name.
+func (r *ArchiveReader) Entry(name string) (ArchiveEntry, bool) {
+	index, ok := r.lookup[name]
+	if !ok {
+		return ArchiveEntry{}, false
+	}
+	return r.entries[index], true
+}
+
+// Decoder returns a PackedDecoder that reads the entry with the specified
+// name. The checksum of the entry is verified before the decoder is
+// returned, unless the archive was written without checksums.
+func (r *ArchiveReader) Decoder(name string) (*PackedDecoder, error) {
+	entry, ok := r.Entry(name)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrEntryNotFound, name)
+	}
+	var data []byte
+	switch {
+	case r.data != nil:
+		data = r.data[entry.Offset : entry.Offset+entry.Size]
+	case r.checksum != ChecksumNone:
+		data = make([]byte, entry.Size)
+		if _, err := r.in.ReadAt(data, int64(entry.Offset)); err != nil {
+			return nil, err
+		}
+	default:
+		section := io.NewSectionReader(r.in, int64(entry.Offset), int64(entry.Size))
+		reader, err := newOrderedReader(bufio.NewReader(section), r.order)
+		if err != nil {
+			return nil, err
+		}
+		return &PackedDecoder{
+			in:    reader,
+			order: r.order,
+		}, nil
+	}
+	if hash := r.checksum.newHash(); hash != nil {
+		hash.Write(data)
+		if !bytes.Equal(hash.Sum(nil), entry.Checksum) {
+			return nil, fmt.Errorf("entry %s: %w", name, ErrChecksumMismatch)
+		}
+	}
+	reader, err := newOrderedBufferReader(data, r.order)
+	if err != nil {
+		return nil, err
+	}
+	return &PackedDecoder{
+		in:    reader,
+		order: r.order,
+	}, nil
+}
+
+// Decode decodes the entry with the specified name into the specified
+// target value.
+func (r *ArchiveReader) Decode(name string, target any) error {
+	decoder, err := r.Decoder(name)
+	if err != nil {
+		return err
+	}
+	return decoder.Decode(target)
+}
+
+func parseArchiveTrailer(trailer []byte, size uint64) (*ArchiveReader, uint64, uint64, error) {
+	if !bytes.Equal(trailer[19:], archiveMagic) {
+		return nil, 0, 0, ErrInvalidArchive
+	}
+	var order ByteOrder
+	switch {
+	case LittleEndianBlock(trailer[17:19]).Uint16(0) == byteOrderMarker:
+		order = LittleEndian
+	case BigEndianBlock(trailer[17:19]).Uint16(0) == byteOrderMarker:
+		order = BigEndian
+	default:
+		return nil, 0, 0, ErrInvalidByteOrder
+	}
+	reader, err := newOrderedReader(bytes.NewReader(trailer), order)
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	indexOffset, err := reader.ReadUint64()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	indexSize, err := reader.ReadUint64()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	checksum, err := reader.ReadUint8()
+	if err != nil {
+		return nil, 0, 0, err
+	}
+	limit := size - archiveTrailerSize
+	if indexOffset > limit || indexSize > limit-indexOffset {
+		return nil, 0, 0, ErrInvalidArchive
+	}
+	if err := ChecksumAlgorithm(checksum).validate(); err != nil {
+		return nil, 0, 0, err
+	}
+	result := &ArchiveReader{
+		order:    order,
+		checksum: ChecksumAlgorithm(checksum),
+	}
+	return result, indexOffset, indexSize, nil
+}
+
+func (r *ArchiveReader) parseIndex(index []byte, limit uint64) error {
+	reader, err := newOrderedBufferReader(index, r.order)
+	if err != nil {
+		return err
+	}
+	count, err := reader.ReadUint64()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+	if count > uint64(len(index)/archiveEntryMinSize) {
+		return fmt.Errorf("%w: index cannot hold %d entries", ErrInvalidArchive, count)
+	}
+	decoder := &PackedDecoder{
+		in:    reader,
+		order: r.order,
+	}
+	r.entries = make([]ArchiveEntry, count)
+	for i := range r.entries {
+		if err := decoder.Decode(&r.entries[i]); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+		}
+	}
+	r.lookup = make(map[string]int, len(r.entries))
+	for i, entry := range r.entries {
+		if entry.Offset > limit || entry.Size > limit-entry.Offset {
+			return fmt.Errorf("%w: entry %s is out of bounds", ErrInvalidArchive, entry.Name)
+		}
+		if len(entry.Checksum) != r.checksum.Size() {
+			return fmt.Errorf("%w: entry %s has an invalid checksum", ErrInvalidArchive, entry.Name)
+		}
+		if _, ok := r.lookup[entry.Name]; ok {
+			return fmt.Errorf("%w: duplicate entry %s", ErrInvalidArchive, entry.Name)
+		}
+		r.lookup[entry.Name] = i
+	}
+	return nil
+}
+
+func newOrderedBufferReader(data []byte, order ByteOrder) (TypedReader, error) {
+	switch order {
+	case LittleEndian:
+		return NewLittleEndianBufferReader(data), nil
+	case BigEndian:
+		return NewBigEndianBufferReader(data), nil
+	default:
+		return nil, fmt.Errorf("unsupported byte order: %v", order)
+	}
+}
@@ -0,0 +1,204 @@
+package gblob_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+)
+
+var _ = Describe("Archive", func() {
+	type mesh struct {
+		Name     string
+		Vertices []float32
+	}
+
+	type texture struct {
+		Width  uint16
+		Height uint16
+		Data   []byte
+	}
+
+	var (
+		buffer     *bytes.Buffer
+		order      gblob.ByteOrder
+		checksum   gblob.ChecksumAlgorithm
+		sourceMesh mesh
+		sourceTex  texture
+	)
+
+	writeArchive := func() {
+		writer, err := gblob.NewArchiveWriter(buffer, order)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(writer.SetChecksum(checksum)).To(Succeed())
+		Expect(writer.Encode("mesh", sourceMesh)).To(Succeed())
+		Expect(writer.Encode("texture", sourceTex)).To(Succeed())
+		Expect(writer.Close()).To(Succeed())
+	}
+
+	BeforeEach(func() {
+		buffer = new(bytes.Buffer)
+		order = gblob.LittleEndian
+		checksum = gblob.ChecksumCRC32C
+		sourceMesh = mesh{
+			Name:     "cube",
+			Vertices: []float32{1.0, 2.0, 3.0},
+		}
+		sourceTex = texture{
+			Width:  2,
+			Height: 1,
+			Data:   []byte{0x01, 0x02, 0x03, 0x04},
+		}
+	})
+
+	itDecodesEntries := func(open func() (*gblob.ArchiveReader, error)) {
+		It("lists the entries", func() {
+			reader, err := open()
+			Expect(err).ToNot(HaveOccurred())
+
+			entries := reader.Entries()
+			Expect(entries).To(HaveLen(2))
+			Expect(entries[0].Name).To(Equal("mesh"))
+			Expect(entries[0].Offset).To(Equal(uint64(0)))
+			Expect(entries[0].Size).To(Equal(uint64(8 + 4 + 8 + 12)))
+			Expect(entries[0].Checksum).To(HaveLen(checksum.Size()))
+			Expect(entries[1].Name).To(Equal("texture"))
+			Expect(entries[1].Offset).To(Equal(entries[0].Size))
+		})
+
+		It("decodes individual entries", func() {
+			reader, err := open()
+			Expect(err).ToNot(HaveOccurred())
+
+			var targetTex texture
+			Expect(reader.Decode("texture", &targetTex)).To(Succeed())
+			Expect(targetTex).To(Equal(sourceTex))
+
+			var targetMesh mesh
+			Expect(reader.Decode("mesh", &targetMesh)).To(Succeed())
+			Expect(targetMesh).To(Equal(sourceMesh))
+		})
+
+		It("reports missing entries", func() {
+			reader, err := open()
+			Expect(err).ToNot(HaveOccurred())
+
+			var target mesh
+			Expect(reader.Decode("missing", &target)).To(MatchError(gblob.ErrEntryNotFound))
+		})
+	}
+
+	When("reading from a ReaderAt", func() {
+		open := func() (*gblob.ArchiveReader, error) {
+			data := buffer.Bytes()
+			return gblob.OpenArchive(bytes.NewReader(data), int64(len(data)))
+		}
+
+		BeforeEach(func() {
+			writeArchive()
+		})
+
+		itDecodesEntries(open)
+	})
+
+	When("reading from a buffer", func() {
+		open := func() (*gblob.ArchiveReader, error) {
+			return gblob.OpenArchiveBuffer(buffer.Bytes())
+		}
+
+		BeforeEach(func() {
+			writeArchive()
+		})
+
+		itDecodesEntries(open)
+	})
+
+	When("written in Big Endian without checksums", func() {
+		open := func() (*gblob.ArchiveReader, error) {
+			data := buffer.Bytes()
+			return gblob.OpenArchive(bytes.NewReader(data), int64(len(data)))
+		}
+
+		BeforeEach(func() {
+			order = gblob.BigEndian
+			checksum = gblob.ChecksumNone
+			writeArchive()
+		})
+
+		itDecodesEntries(open)
+	})
+
+	It("detects corrupted entries", func() {
+		writeArchive()
+		data := buffer.Bytes()
+		data[9] ^= 0xFF
+
+		reader, err := gblob.OpenArchiveBuffer(data)
+		Expect(err).ToNot(HaveOccurred())
+
+		var target mesh
+		Expect(reader.Decode("mesh", &target)).To(MatchError(gblob.ErrChecksumMismatch))
+		Expect(target).To(Equal(mesh{}))
+
+		var targetTex texture
+		Expect(reader.Decode("texture", &targetTex)).To(Succeed())
+	})
+
+	It("rejects data without a trailer", func() {
+		_, err := gblob.OpenArchiveBuffer(make([]byte, 64))
+		Expect(err).To(MatchError(gblob.ErrInvalidArchive))
+	})
+
+	Describe("corrupted index", func() {
+		var (
+			data  []byte
+			index []byte
+		)
+
+		BeforeEach(func() {
+			writeArchive()
+			data = buffer.Bytes()
+			offset := gblob.LittleEndianBlock(data).Uint64(len(data) - 23)
+			index = data[offset:]
+		})
+
+		It("rejects an excessive entry count", func() {
+			gblob.LittleEndianBlock(index).SetUint64(0, 1<<40)
+			_, err := gblob.OpenArchiveBuffer(data)
+			Expect(err).To(MatchError(gblob.ErrInvalidArchive))
+		})
+
+		It("rejects an excessive name length", func() {
+			gblob.LittleEndianBlock(index).SetUint64(8, 0xFFFFFFFFFFFFFFFF)
+			_, err := gblob.OpenArchiveBuffer(data)
+			Expect(err).To(MatchError(gblob.ErrInvalidArchive))
+
+			_, err = gblob.OpenArchive(bytes.NewReader(data), int64(len(data)))
+			Expect(err).To(MatchError(gblob.ErrInvalidArchive))
+		})
+
+		It("rejects duplicate names", func() {
+			position := bytes.LastIndex(index, []byte("texture"))
+			Expect(position).To(BeNumerically(">", 0))
+			copy(index[position:], "mesh")
+			gblob.LittleEndianBlock(index).SetUint64(position-8, 4)
+			copy(index[position+4:], index[position+7:])
+			data = data[:len(data)-3]
+			indexSize := gblob.LittleEndianBlock(data).Uint64(len(data) - 15)
+			gblob.LittleEndianBlock(data).SetUint64(len(data)-15, indexSize-3)
+
+			_, err := gblob.OpenArchiveBuffer(data)
+			Expect(err).To(MatchError(gblob.ErrInvalidArchive))
+			Expect(err).To(MatchError(ContainSubstring("duplicate entry mesh")))
+		})
+	})
+
+	It("rejects duplicate names", func() {
+		writer, err := gblob.NewArchiveWriter(buffer, order)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(writer.Encode("mesh", sourceMesh)).To(Succeed())
+		Expect(writer.Encode("mesh", sourceMesh)).ToNot(Succeed())
+	})
+})
@@ -317,6 +317,92 @@ func Benchmark_Decoder_PackedDecoder(b *testing.B) {
 	}
 }
 
+func Benchmark_Decoder_PackedDecoderZeroCopy(b *testing.B) {
+	const itemCount = 1024
+
+	type encodeStruct struct {
+		A uint32
+		B string
+		C []byte
+	}
+
+	data := bytes.NewBuffer(make([]byte, 0, itemCount*1024))
+	template := encodeStruct{
+		A: 10,
+		B: "the quick brown fox jumps over the lazy dog",
+		C: make([]byte, 512),
+	}
+	encoder := gblob.NewLittleEndianPackedEncoder(data)
+	for range itemCount {
+		if err := encoder.Encode(template); err != nil {
+			panic(err)
+		}
+	}
+
+	b.ResetTimer()
+
+	for range b.N {
+		decoder := gblob.NewLittleEndianBufferPackedDecoder(data.Bytes())
+		decoder.SetZeroCopy(true)
+
+		for range itemCount {
+			var template encodeStruct
+			if err := decoder.Decode(&template); err != nil {
+				panic(err)
+			}
+			if template.A != 10 {
+				b.Errorf("Field A %d is not equal to 10", template.A)
+			}
+		}
+	}
+}
+
+func Benchmark_Decoder_PackedDecoderReuse(b *testing.B) {
+	const itemCount = 1024
+
+	type encodeStruct struct {
+		A uint32
+		B string
+		C []uint64
+		D map[uint8]uint8
+	}
+
+	data := bytes.NewBuffer(make([]byte, 0, itemCount*1024))
+	template := encodeStruct{
+		A: 10,
+		B: "the quick brown fox jumps over the lazy dog",
+		C: make([]uint64, 64),
+		D: map[uint8]uint8{1: 2, 3: 4},
+	}
+	encoder := gblob.NewLittleEndianPackedEncoder(data)
+	for range itemCount {
+		if err := encoder.Encode(template); err != nil {
+			panic(err)
+		}
+	}
+
+	seeker := bytes.NewReader(data.Bytes())
+	decoder := gblob.NewLittleEndianPackedDecoder(seeker)
+	decoder.SetReuse(true)
+
+	var target encodeStruct
+
+	b.ResetTimer()
+
+	for range b.N {
+		seeker.Reset(data.Bytes())
+
+		for range itemCount {
+			if err := decoder.Decode(&target); err != nil {
+				panic(err)
+			}
+			if target.A != 10 {
+				b.Errorf("Field A %d is not equal to 10", target.A)
+			}
+		}
+	}
+}
+
 func Benchmark_Decoder_GobDecoder(b *testing.B) {
 	const itemCount = 1024
 
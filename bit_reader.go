@@ -0,0 +1,82 @@
+package gblob
+
+import "fmt"
+
+// NewBitReader returns a new BitReader that reads its bits from the specified
+// in TypedReader in the specified bit order.
+func NewBitReader(in TypedReader, order BitOrder) *BitReader {
+	return &BitReader{
+		in:    in,
+		order: order,
+	}
+}
+
+// BitReader reads values with arbitrary bit widths from a TypedReader.
+//
+// Bytes are read from the underlying reader only when more bits are needed.
+// Use Align to discard any remaining bits of the current byte.
+type BitReader struct {
+	in     TypedReader
+	order  BitOrder
+	cur    uint8
+	remain int
+}
+
+// ReadBits reads width bits and returns them as the lowest bits of the
+// result. The width must be between 1 and 64.
+func (r *BitReader) ReadBits(width int) (uint64, error) {
+	if width < 1 || width > 64 {
+		return 0, fmt.Errorf("invalid bit width: %d", width)
+	}
+	var (
+		result uint64
+		shift  int
+	)
+	for width > 0 {
+		if r.remain == 0 {
+			value, err := r.in.ReadUint8()
+			if err != nil {
+				return 0, err
+			}
+			r.cur = value
+			r.remain = 8
+		}
+		chunk := min(r.remain, width)
+		switch r.order {
+		case MSBFirst:
+			bits := (r.cur >> (r.remain - chunk)) & bitMask(chunk)
+			result = result<<chunk | uint64(bits)
+		default:
+			bits := (r.cur >> (8 - r.remain)) & bitMask(chunk)
+			result |= uint64(bits) << shift
+			shift += chunk
+		}
+		r.remain -= chunk
+		width -= chunk
+	}
+	return result, nil
+}
+
+// ReadSignedBits reads width bits and sign-extends them, treating them as
+// a two's complement value. The width must be between 1 and 64.
+func (r *BitReader) ReadSignedBits(width int) (int64, error) {
+	value, err := r.ReadBits(width)
+	if err != nil {
+		return 0, err
+	}
+	shift := 64 - width
+	return int64(value<<shift) >> shift, nil
+}
+
+// ReadBool reads a single bit and returns true if it is set.
+func (r *BitReader) ReadBool() (bool, error) {
+	value, err := r.ReadBits(1)
+	return value != 0, err
+}
+
+// Align discards the remaining bits of the current byte so that the next
+// read starts at a byte boundary.
+func (r *BitReader) Align() {
+	r.cur = 0
+	r.remain = 0
+}
@@ -0,0 +1,160 @@
+package gblob_test
+
+import (
+	"bytes"
+	"io"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+)
+
+var _ = Describe("BitReader", func() {
+	var (
+		buffer *bytes.Buffer
+		reader *gblob.BitReader
+	)
+
+	When("LSBFirst", func() {
+		BeforeEach(func() {
+			buffer = new(bytes.Buffer)
+			reader = gblob.NewBitReader(gblob.NewLittleEndianReader(buffer), gblob.LSBFirst)
+		})
+
+		Specify("ReadBits", func() {
+			buffer.Write([]uint8{
+				0b11001_101,
+				0xBC,
+				0x0A,
+			})
+
+			value, err := reader.ReadBits(3)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal(uint64(0b101)))
+
+			value, err = reader.ReadBits(5)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal(uint64(0b11001)))
+
+			value, err = reader.ReadBits(12)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal(uint64(0xABC)))
+		})
+
+		Specify("ReadSignedBits", func() {
+			buffer.Write([]uint8{
+				0b0010_1101,
+			})
+
+			value, err := reader.ReadSignedBits(4)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal(int64(-3)))
+
+			value, err = reader.ReadSignedBits(4)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal(int64(2)))
+		})
+
+		Specify("ReadBool", func() {
+			buffer.Write([]uint8{
+				0b0000_0101,
+			})
+
+			value, err := reader.ReadBool()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(BeTrue())
+
+			value, err = reader.ReadBool()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(BeFalse())
+
+			value, err = reader.ReadBool()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(BeTrue())
+		})
+
+		Specify("ReadBits 64", func() {
+			buffer.Write([]uint8{
+				0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+			})
+
+			value, err := reader.ReadBits(1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal(uint64(0b1)))
+
+			value, err = reader.ReadBits(64)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal(uint64(0x8000000000000001)))
+		})
+	})
+
+	When("MSBFirst", func() {
+		BeforeEach(func() {
+			buffer = new(bytes.Buffer)
+			reader = gblob.NewBitReader(gblob.NewLittleEndianReader(buffer), gblob.MSBFirst)
+		})
+
+		Specify("ReadBits", func() {
+			buffer.Write([]uint8{
+				0b101_11001,
+				0xAB,
+				0xC0,
+			})
+
+			value, err := reader.ReadBits(3)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal(uint64(0b101)))
+
+			value, err = reader.ReadBits(5)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal(uint64(0b11001)))
+
+			value, err = reader.ReadBits(12)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal(uint64(0xABC)))
+		})
+
+		Specify("ReadSignedBits", func() {
+			buffer.Write([]uint8{
+				0b1101_0010,
+			})
+
+			value, err := reader.ReadSignedBits(4)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal(int64(-3)))
+
+			value, err = reader.ReadSignedBits(4)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(Equal(int64(2)))
+		})
+
+		Specify("Align", func() {
+			buffer.Write([]uint8{
+				0b1010_0000,
+				0x37,
+			})
+
+			value, err := reader.ReadBool()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(value).To(BeTrue())
+
+			reader.Align()
+
+			bits, err := reader.ReadBits(8)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(bits).To(Equal(uint64(0x37)))
+		})
+	})
+
+	Specify("EOF", func() {
+		buffer = bytes.NewBuffer([]uint8{0xFF})
+		reader = gblob.NewBitReader(gblob.NewLittleEndianReader(buffer), gblob.LSBFirst)
+
+		_, err := reader.ReadBits(6)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, err = reader.ReadBits(6)
+		Expect(err).To(MatchError(io.EOF))
+	})
+})
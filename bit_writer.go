@@ -0,0 +1,99 @@
+package gblob
+
+import "fmt"
+
+// BitOrder specifies the order in which bits are packed into a byte.
+type BitOrder uint8
+
+const (
+	// LSBFirst packs bits starting from the least significant bit of each
+	// byte. Values are written starting from their least significant bit.
+	LSBFirst BitOrder = iota
+
+	// MSBFirst packs bits starting from the most significant bit of each
+	// byte. Values are written starting from their most significant bit.
+	MSBFirst
+)
+
+// NewBitWriter returns a new BitWriter that writes its bits to the specified
+// out TypedWriter in the specified bit order.
+func NewBitWriter(out TypedWriter, order BitOrder) *BitWriter {
+	return &BitWriter{
+		out:   out,
+		order: order,
+	}
+}
+
+// BitWriter writes values with arbitrary bit widths to a TypedWriter.
+//
+// Bits are accumulated until a whole byte is formed, which is then written
+// to the underlying writer. Use Align to write any pending bits.
+type BitWriter struct {
+	out   TypedWriter
+	order BitOrder
+	acc   uint8
+	count int
+}
+
+// WriteBits writes the lowest width bits of the specified value. The width
+// must be between 1 and 64.
+func (w *BitWriter) WriteBits(value uint64, width int) error {
+	if width < 1 || width > 64 {
+		return fmt.Errorf("invalid bit width: %d", width)
+	}
+	for width > 0 {
+		chunk := min(8-w.count, width)
+		switch w.order {
+		case MSBFirst:
+			bits := uint8(value>>(width-chunk)) & bitMask(chunk)
+			w.acc |= bits << (8 - w.count - chunk)
+		default:
+			bits := uint8(value) & bitMask(chunk)
+			w.acc |= bits << w.count
+			value >>= chunk
+		}
+		w.count += chunk
+		width -= chunk
+		if w.count == 8 {
+			if err := w.flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// WriteSignedBits writes the lowest width bits of the two's complement
+// representation of the specified value. The width must be between 1 and 64.
+func (w *BitWriter) WriteSignedBits(value int64, width int) error {
+	return w.WriteBits(uint64(value), width)
+}
+
+// WriteBool writes a single bit that is set if the value is true.
+func (w *BitWriter) WriteBool(value bool) error {
+	if value {
+		return w.WriteBits(1, 1)
+	} else {
+		return w.WriteBits(0, 1)
+	}
+}
+
+// Align pads the current byte with zero bits and writes it to the
+// underlying writer. It does nothing if the writer is already aligned.
+func (w *BitWriter) Align() error {
+	if w.count == 0 {
+		return nil
+	}
+	return w.flush()
+}
+
+func (w *BitWriter) flush() error {
+	value := w.acc
+	w.acc = 0
+	w.count = 0
+	return w.out.WriteUint8(value)
+}
+
+func bitMask(width int) uint8 {
+	return uint8(1<<width - 1)
+}
@@ -0,0 +1,114 @@
+package gblob_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+)
+
+var _ = Describe("BitWriter", func() {
+	var (
+		buffer *bytes.Buffer
+		writer *gblob.BitWriter
+	)
+
+	When("LSBFirst", func() {
+		BeforeEach(func() {
+			buffer = new(bytes.Buffer)
+			writer = gblob.NewBitWriter(gblob.NewLittleEndianWriter(buffer), gblob.LSBFirst)
+		})
+
+		Specify("WriteBits", func() {
+			Expect(writer.WriteBits(0b101, 3)).To(Succeed())
+			Expect(writer.WriteBits(0b11001, 5)).To(Succeed())
+			Expect(writer.WriteBits(0xABC, 12)).To(Succeed())
+			Expect(writer.Align()).To(Succeed())
+			Expect(buffer.Bytes()).To(Equal([]uint8{
+				0b11001_101,
+				0xBC,
+				0x0A,
+			}))
+		})
+
+		Specify("WriteSignedBits", func() {
+			Expect(writer.WriteSignedBits(-3, 4)).To(Succeed())
+			Expect(writer.WriteSignedBits(2, 4)).To(Succeed())
+			Expect(buffer.Bytes()).To(Equal([]uint8{
+				0b0010_1101,
+			}))
+		})
+
+		Specify("WriteBool", func() {
+			Expect(writer.WriteBool(true)).To(Succeed())
+			Expect(writer.WriteBool(false)).To(Succeed())
+			Expect(writer.WriteBool(true)).To(Succeed())
+			Expect(writer.Align()).To(Succeed())
+			Expect(buffer.Bytes()).To(Equal([]uint8{
+				0b0000_0101,
+			}))
+		})
+
+		Specify("WriteBits 64", func() {
+			Expect(writer.WriteBits(0b1, 1)).To(Succeed())
+			Expect(writer.WriteBits(0x8000000000000001, 64)).To(Succeed())
+			Expect(writer.Align()).To(Succeed())
+			Expect(buffer.Bytes()).To(Equal([]uint8{
+				0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01,
+			}))
+		})
+	})
+
+	When("MSBFirst", func() {
+		BeforeEach(func() {
+			buffer = new(bytes.Buffer)
+			writer = gblob.NewBitWriter(gblob.NewLittleEndianWriter(buffer), gblob.MSBFirst)
+		})
+
+		Specify("WriteBits", func() {
+			Expect(writer.WriteBits(0b101, 3)).To(Succeed())
+			Expect(writer.WriteBits(0b11001, 5)).To(Succeed())
+			Expect(writer.WriteBits(0xABC, 12)).To(Succeed())
+			Expect(writer.Align()).To(Succeed())
+			Expect(buffer.Bytes()).To(Equal([]uint8{
+				0b101_11001,
+				0xAB,
+				0xC0,
+			}))
+		})
+
+		Specify("WriteSignedBits", func() {
+			Expect(writer.WriteSignedBits(-3, 4)).To(Succeed())
+			Expect(writer.WriteSignedBits(2, 4)).To(Succeed())
+			Expect(buffer.Bytes()).To(Equal([]uint8{
+				0b1101_0010,
+			}))
+		})
+
+		Specify("WriteBool", func() {
+			Expect(writer.WriteBool(true)).To(Succeed())
+			Expect(writer.WriteBool(false)).To(Succeed())
+			Expect(writer.WriteBool(true)).To(Succeed())
+			Expect(writer.Align()).To(Succeed())
+			Expect(buffer.Bytes()).To(Equal([]uint8{
+				0b1010_0000,
+			}))
+		})
+	})
+
+	Specify("Align when aligned", func() {
+		buffer = new(bytes.Buffer)
+		writer = gblob.NewBitWriter(gblob.NewLittleEndianWriter(buffer), gblob.LSBFirst)
+		Expect(writer.Align()).To(Succeed())
+		Expect(buffer.Len()).To(BeZero())
+	})
+
+	Specify("invalid width", func() {
+		buffer = new(bytes.Buffer)
+		writer = gblob.NewBitWriter(gblob.NewLittleEndianWriter(buffer), gblob.LSBFirst)
+		Expect(writer.WriteBits(0, 0)).ToNot(Succeed())
+		Expect(writer.WriteBits(0, 65)).ToNot(Succeed())
+	})
+})
@@ -65,6 +65,201 @@ type Block interface {
 
 	// SetFloat64 places the float64 value at the specified offset.
 	SetFloat64(offset int, value float64)
+
+	// Uint8s reads len(dst) consecutive uint8 values starting at the specified
+	// offset.
+	Uint8s(offset int, dst []uint8)
+
+	// SetUint8s places the uint8 values of src consecutively starting at the
+	// specified offset.
+	SetUint8s(offset int, src []uint8)
+
+	// Uint8sStrided reads len(dst) uint8 values starting at the specified offset,
+	// where each subsequent value is located stride bytes after the previous.
+	Uint8sStrided(offset, stride int, dst []uint8)
+
+	// SetUint8sStrided places the uint8 values of src starting at the specified
+	// offset, where each subsequent value is located stride bytes after the
+	// previous.
+	SetUint8sStrided(offset, stride int, src []uint8)
+
+	// Int8s reads len(dst) consecutive int8 values starting at the specified
+	// offset.
+	Int8s(offset int, dst []int8)
+
+	// SetInt8s places the int8 values of src consecutively starting at the
+	// specified offset.
+	SetInt8s(offset int, src []int8)
+
+	// Int8sStrided reads len(dst) int8 values starting at the specified offset,
+	// where each subsequent value is located stride bytes after the previous.
+	Int8sStrided(offset, stride int, dst []int8)
+
+	// SetInt8sStrided places the int8 values of src starting at the specified
+	// offset, where each subsequent value is located stride bytes after the
+	// previous.
+	SetInt8sStrided(offset, stride int, src []int8)
+
+	// Uint16s reads len(dst) consecutive uint16 values starting at the specified
+	// offset.
+	Uint16s(offset int, dst []uint16)
+
+	// SetUint16s places the uint16 values of src consecutively starting at the
+	// specified offset.
+	SetUint16s(offset int, src []uint16)
+
+	// Uint16sStrided reads len(dst) uint16 values starting at the specified offset,
+	// where each subsequent value is located stride bytes after the previous.
+	Uint16sStrided(offset, stride int, dst []uint16)
+
+	// SetUint16sStrided places the uint16 values of src starting at the specified
+	// offset, where each subsequent value is located stride bytes after the
+	// previous.
+	SetUint16sStrided(offset, stride int, src []uint16)
+
+	// Int16s reads len(dst) consecutive int16 values starting at the specified
+	// offset.
+	Int16s(offset int, dst []int16)
+
+	// SetInt16s places the int16 values of src consecutively starting at the
+	// specified offset.
+	SetInt16s(offset int, src []int16)
+
+	// Int16sStrided reads len(dst) int16 values starting at the specified offset,
+	// where each subsequent value is located stride bytes after the previous.
+	Int16sStrided(offset, stride int, dst []int16)
+
+	// SetInt16sStrided places the int16 values of src starting at the specified
+	// offset, where each subsequent value is located stride bytes after the
+	// previous.
+	SetInt16sStrided(offset, stride int, src []int16)
+
+	// Uint32s reads len(dst) consecutive uint32 values starting at the specified
+	// offset.
+	Uint32s(offset int, dst []uint32)
+
+	// SetUint32s places the uint32 values of src consecutively starting at the
+	// specified offset.
+	SetUint32s(offset int, src []uint32)
+
+	// Uint32sStrided reads len(dst) uint32 values starting at the specified offset,
+	// where each subsequent value is located stride bytes after the previous.
+	Uint32sStrided(offset, stride int, dst []uint32)
+
+	// SetUint32sStrided places the uint32 values of src starting at the specified
+	// offset, where each subsequent value is located stride bytes after the
+	// previous.
+	SetUint32sStrided(offset, stride int, src []uint32)
+
+	// Int32s reads len(dst) consecutive int32 values starting at the specified
+	// offset.
+	Int32s(offset int, dst []int32)
+
+	// SetInt32s places the int32 values of src consecutively starting at the
+	// specified offset.
+	SetInt32s(offset int, src []int32)
+
+	// Int32sStrided reads len(dst) int32 values starting at the specified offset,
+	// where each subsequent value is located stride bytes after the previous.
+	Int32sStrided(offset, stride int, dst []int32)
+
+	// SetInt32sStrided places the int32 values of src starting at the specified
+	// offset, where each subsequent value is located stride bytes after the
+	// previous.
+	SetInt32sStrided(offset, stride int, src []int32)
+
+	// Uint64s reads len(dst) consecutive uint64 values starting at the specified
+	// offset.
+	Uint64s(offset int, dst []uint64)
+
+	// SetUint64s places the uint64 values of src consecutively starting at the
+	// specified offset.
+	SetUint64s(offset int, src []uint64)
+
+	// Uint64sStrided reads len(dst) uint64 values starting at the specified offset,
+	// where each subsequent value is located stride bytes after the previous.
+	Uint64sStrided(offset, stride int, dst []uint64)
+
+	// SetUint64sStrided places the uint64 values of src starting at the specified
+	// offset, where each subsequent value is located stride bytes after the
+	// previous.
+	SetUint64sStrided(offset, stride int, src []uint64)
+
+	// Int64s reads len(dst) consecutive int64 values starting at the specified
+	// offset.
+	Int64s(offset int, dst []int64)
+
+	// SetInt64s places the int64 values of src consecutively starting at the
+	// specified offset.
+	SetInt64s(offset int, src []int64)
+
+	// Int64sStrided reads len(dst) int64 values starting at the specified offset,
+	// where each subsequent value is located stride bytes after the previous.
+	Int64sStrided(offset, stride int, dst []int64)
+
+	// SetInt64sStrided places the int64 values of src starting at the specified
+	// offset, where each subsequent value is located stride bytes after the
+	// previous.
+	SetInt64sStrided(offset, stride int, src []int64)
+
+	// Float32s reads len(dst) consecutive float32 values starting at the specified
+	// offset.
+	Float32s(offset int, dst []float32)
+
+	// SetFloat32s places the float32 values of src consecutively starting at the
+	// specified offset.
+	SetFloat32s(offset int, src []float32)
+
+	// Float32sStrided reads len(dst) float32 values starting at the specified offset,
+	// where each subsequent value is located stride bytes after the previous.
+	Float32sStrided(offset, stride int, dst []float32)
+
+	// SetFloat32sStrided places the float32 values of src starting at the specified
+	// offset, where each subsequent value is located stride bytes after the
+	// previous.
+	SetFloat32sStrided(offset, stride int, src []float32)
+
+	// Float64s reads len(dst) consecutive float64 values starting at the specified
+	// offset.
+	Float64s(offset int, dst []float64)
+
+	// SetFloat64s places the float64 values of src consecutively starting at the
+	// specified offset.
+	SetFloat64s(offset int, src []float64)
+
+	// Float64sStrided reads len(dst) float64 values starting at the specified offset,
+	// where each subsequent value is located stride bytes after the previous.
+	Float64sStrided(offset, stride int, dst []float64)
+
+	// SetFloat64sStrided places the float64 values of src starting at the specified
+	// offset, where each subsequent value is located stride bytes after the
+	// previous.
+	SetFloat64sStrided(offset, stride int, src []float64)
+
+	// Bytes returns count bytes starting at the specified offset. The returned
+	// slice shares memory with the block.
+	Bytes(offset, count int) []byte
+
+	// SetBytes places the bytes of src starting at the specified offset.
+	SetBytes(offset int, src []byte)
+
+	// FixedString returns the string stored in a field of size bytes at the
+	// specified offset. The string ends at the first NUL byte, if any.
+	FixedString(offset, size int) string
+
+	// SetFixedString places the string value in a field of size bytes at the
+	// specified offset. Unused bytes are set to NUL and strings that do not
+	// fit are truncated.
+	SetFixedString(offset, size int, value string)
+
+	// PrefixedString returns the string at the specified offset that is
+	// preceded by a length of the specified width.
+	PrefixedString(offset int, prefix LengthPrefix) string
+
+	// SetPrefixedString places the string value at the specified offset,
+	// preceded by its length in the specified width. The value occupies
+	// int(prefix)+len(value) bytes.
+	SetPrefixedString(offset int, prefix LengthPrefix, value string)
 }
 
 // LittleEndianBlock represents a fixed-size block of bytes that holds
@@ -0,0 +1,14 @@
+//go:build armbe || arm64be || m68k || mips || mips64 || mips64p32 || ppc || ppc64 || s390 || s390x || shbe || sparc || sparc64
+
+package gblob
+
+// NativeEndianBlock represents a fixed-size block of bytes that holds
+// values encoded in the byte order of the host. On this platform it is
+// equivalent to BigEndianBlock.
+type NativeEndianBlock = BigEndianBlock
+
+// nativeLittleEndian indicates whether the host uses Little Endian order.
+const nativeLittleEndian = false
+
+// NativeEndian is the byte order of the host.
+const NativeEndian = BigEndian
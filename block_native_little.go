@@ -0,0 +1,14 @@
+//go:build 386 || amd64 || arm || arm64 || loong64 || mips64le || mipsle || ppc64le || riscv64 || wasm
+
+package gblob
+
+// NativeEndianBlock represents a fixed-size block of bytes that holds
+// values encoded in the byte order of the host. On this platform it is
+// equivalent to LittleEndianBlock.
+type NativeEndianBlock = LittleEndianBlock
+
+// nativeLittleEndian indicates whether the host uses Little Endian order.
+const nativeLittleEndian = true
+
+// NativeEndian is the byte order of the host.
+const NativeEndian = LittleEndian
@@ -0,0 +1,798 @@
+package gblob
+
+import "unsafe"
+
+// Uint8s reads len(dst) consecutive uint8 values starting at the specified
+// offset.
+func (b LittleEndianBlock) Uint8s(offset int, dst []uint8) {
+	copyFromBlock(dst, b[offset:])
+}
+
+// SetUint8s places the uint8 values of src consecutively starting at the
+// specified offset.
+func (b LittleEndianBlock) SetUint8s(offset int, src []uint8) {
+	copyToBlock(b[offset:], src)
+}
+
+// Uint8sStrided reads len(dst) uint8 values starting at the specified offset,
+// where each subsequent value is located stride bytes after the previous.
+func (b LittleEndianBlock) Uint8sStrided(offset, stride int, dst []uint8) {
+	for i := range dst {
+		dst[i] = b.Uint8(offset + i*stride)
+	}
+}
+
+// SetUint8sStrided places the uint8 values of src starting at the specified
+// offset, where each subsequent value is located stride bytes after the
+// previous.
+func (b LittleEndianBlock) SetUint8sStrided(offset, stride int, src []uint8) {
+	for i, value := range src {
+		b.SetUint8(offset+i*stride, value)
+	}
+}
+
+// Int8s reads len(dst) consecutive int8 values starting at the specified
+// offset.
+func (b LittleEndianBlock) Int8s(offset int, dst []int8) {
+	copyFromBlock(dst, b[offset:])
+}
+
+// SetInt8s places the int8 values of src consecutively starting at the
+// specified offset.
+func (b LittleEndianBlock) SetInt8s(offset int, src []int8) {
+	copyToBlock(b[offset:], src)
+}
+
+// Int8sStrided reads len(dst) int8 values starting at the specified offset,
+// where each subsequent value is located stride bytes after the previous.
+func (b LittleEndianBlock) Int8sStrided(offset, stride int, dst []int8) {
+	for i := range dst {
+		dst[i] = b.Int8(offset + i*stride)
+	}
+}
+
+// SetInt8sStrided places the int8 values of src starting at the specified
+// offset, where each subsequent value is located stride bytes after the
+// previous.
+func (b LittleEndianBlock) SetInt8sStrided(offset, stride int, src []int8) {
+	for i, value := range src {
+		b.SetInt8(offset+i*stride, value)
+	}
+}
+
+// Uint16s reads len(dst) consecutive uint16 values starting at the specified
+// offset.
+func (b LittleEndianBlock) Uint16s(offset int, dst []uint16) {
+	if nativeLittleEndian {
+		copyFromBlock(dst, b[offset:])
+		return
+	}
+	for i := range dst {
+		dst[i] = b.Uint16(offset + i*2)
+	}
+}
+
+// SetUint16s places the uint16 values of src consecutively starting at the
+// specified offset.
+func (b LittleEndianBlock) SetUint16s(offset int, src []uint16) {
+	if nativeLittleEndian {
+		copyToBlock(b[offset:], src)
+		return
+	}
+	for i, value := range src {
+		b.SetUint16(offset+i*2, value)
+	}
+}
+
+// Uint16sStrided reads len(dst) uint16 values starting at the specified offset,
+// where each subsequent value is located stride bytes after the previous.
+func (b LittleEndianBlock) Uint16sStrided(offset, stride int, dst []uint16) {
+	for i := range dst {
+		dst[i] = b.Uint16(offset + i*stride)
+	}
+}
+
+// SetUint16sStrided places the uint16 values of src starting at the specified
+// offset, where each subsequent value is located stride bytes after the
+// previous.
+func (b LittleEndianBlock) SetUint16sStrided(offset, stride int, src []uint16) {
+	for i, value := range src {
+		b.SetUint16(offset+i*stride, value)
+	}
+}
+
+// Int16s reads len(dst) consecutive int16 values starting at the specified
+// offset.
+func (b LittleEndianBlock) Int16s(offset int, dst []int16) {
+	if nativeLittleEndian {
+		copyFromBlock(dst, b[offset:])
+		return
+	}
+	for i := range dst {
+		dst[i] = b.Int16(offset + i*2)
+	}
+}
+
+// SetInt16s places the int16 values of src consecutively starting at the
+// specified offset.
+func (b LittleEndianBlock) SetInt16s(offset int, src []int16) {
+	if nativeLittleEndian {
+		copyToBlock(b[offset:], src)
+		return
+	}
+	for i, value := range src {
+		b.SetInt16(offset+i*2, value)
+	}
+}
+
+// Int16sStrided reads len(dst) int16 values starting at the specified offset,
+// where each subsequent value is located stride bytes after the previous.
+func (b LittleEndianBlock) Int16sStrided(offset, stride int, dst []int16) {
+	for i := range dst {
+		dst[i] = b.Int16(offset + i*stride)
+	}
+}
+
+// SetInt16sStrided places the int16 values of src starting at the specified
+// offset, where each subsequent value is located stride bytes after the
+// previous.
+func (b LittleEndianBlock) SetInt16sStrided(offset, stride int, src []int16) {
+	for i, value := range src {
+		b.SetInt16(offset+i*stride, value)
+	}
+}
+
+// Uint32s reads len(dst) consecutive uint32 values starting at the specified
+// offset.
+func (b LittleEndianBlock) Uint32s(offset int, dst []uint32) {
+	if nativeLittleEndian {
+		copyFromBlock(dst, b[offset:])
+		return
+	}
+	for i := range dst {
+		dst[i] = b.Uint32(offset + i*4)
+	}
+}
+
+// SetUint32s places the uint32 values of src consecutively starting at the
+// specified offset.
+func (b LittleEndianBlock) SetUint32s(offset int, src []uint32) {
+	if nativeLittleEndian {
+		copyToBlock(b[offset:], src)
+		return
+	}
+	for i, value := range src {
+		b.SetUint32(offset+i*4, value)
+	}
+}
+
+// Uint32sStrided reads len(dst) uint32 values starting at the specified offset,
+// where each subsequent value is located stride bytes after the previous.
+func (b LittleEndianBlock) Uint32sStrided(offset, stride int, dst []uint32) {
+	for i := range dst {
+		dst[i] = b.Uint32(offset + i*stride)
+	}
+}
+
+// SetUint32sStrided places the uint32 values of src starting at the specified
+// offset, where each subsequent value is located stride bytes after the
+// previous.
+func (b LittleEndianBlock) SetUint32sStrided(offset, stride int, src []uint32) {
+	for i, value := range src {
+		b.SetUint32(offset+i*stride, value)
+	}
+}
+
+// Int32s reads len(dst) consecutive int32 values starting at the specified
+// offset.
+func (b LittleEndianBlock) Int32s(offset int, dst []int32) {
+	if nativeLittleEndian {
+		copyFromBlock(dst, b[offset:])
+		return
+	}
+	for i := range dst {
+		dst[i] = b.Int32(offset + i*4)
+	}
+}
+
+// SetInt32s places the int32 values of src consecutively starting at the
+// specified offset.
+func (b LittleEndianBlock) SetInt32s(offset int, src []int32) {
+	if nativeLittleEndian {
+		copyToBlock(b[offset:], src)
+		return
+	}
+	for i, value := range src {
+		b.SetInt32(offset+i*4, value)
+	}
+}
+
+// Int32sStrided reads len(dst) int32 values starting at the specified offset,
+// where each subsequent value is located stride bytes after the previous.
+func (b LittleEndianBlock) Int32sStrided(offset, stride int, dst []int32) {
+	for i := range dst {
+		dst[i] = b.Int32(offset + i*stride)
+	}
+}
+
+// SetInt32sStrided places the int32 values of src starting at the specified
+// offset, where each subsequent value is located stride bytes after the
+// previous.
+func (b LittleEndianBlock) SetInt32sStrided(offset, stride int, src []int32) {
+	for i, value := range src {
+		b.SetInt32(offset+i*stride, value)
+	}
+}
+
+// Uint64s reads len(dst) consecutive uint64 values starting at the specified
+// offset.
+func (b LittleEndianBlock) Uint64s(offset int, dst []uint64) {
+	if nativeLittleEndian {
+		copyFromBlock(dst, b[offset:])
+		return
+	}
+	for i := range dst {
+		dst[i] = b.Uint64(offset + i*8)
+	}
+}
+
+// SetUint64s places the uint64 values of src consecutively starting at the
+// specified offset.
+func (b LittleEndianBlock) SetUint64s(offset int, src []uint64) {
+	if nativeLittleEndian {
+		copyToBlock(b[offset:], src)
+		return
+	}
+	for i, value := range src {
+		b.SetUint64(offset+i*8, value)
+	}
+}
+
+// Uint64sStrided reads len(dst) uint64 values starting at the specified offset,
+// where each subsequent value is located stride bytes after the previous.
+func (b LittleEndianBlock) Uint64sStrided(offset, stride int, dst []uint64) {
+	for i := range dst {
+		dst[i] = b.Uint64(offset + i*stride)
+	}
+}
+
+// SetUint64sStrided places the uint64 values of src starting at the specified
+// offset, where each subsequent value is located stride bytes after the
+// previous.
+func (b LittleEndianBlock) SetUint64sStrided(offset, stride int, src []uint64) {
+	for i, value := range src {
+		b.SetUint64(offset+i*stride, value)
+	}
+}
+
+// Int64s reads len(dst) consecutive int64 values starting at the specified
+// offset.
+func (b LittleEndianBlock) Int64s(offset int, dst []int64) {
+	if nativeLittleEndian {
+		copyFromBlock(dst, b[offset:])
+		return
+	}
+	for i := range dst {
+		dst[i] = b.Int64(offset + i*8)
+	}
+}
+
+// SetInt64s places the int64 values of src consecutively starting at the
+// specified offset.
+func (b LittleEndianBlock) SetInt64s(offset int, src []int64) {
+	if nativeLittleEndian {
+		copyToBlock(b[offset:], src)
+		return
+	}
+	for i, value := range src {
+		b.SetInt64(offset+i*8, value)
+	}
+}
+
+// Int64sStrided reads len(dst) int64 values starting at the specified offset,
+// where each subsequent value is located stride bytes after the previous.
+func (b LittleEndianBlock) Int64sStrided(offset, stride int, dst []int64) {
+	for i := range dst {
+		dst[i] = b.Int64(offset + i*stride)
+	}
+}
+
+// SetInt64sStrided places the int64 values of src starting at the specified
+// offset, where each subsequent value is located stride bytes after the
+// previous.
+func (b LittleEndianBlock) SetInt64sStrided(offset, stride int, src []int64) {
+	for i, value := range src {
+		b.SetInt64(offset+i*stride, value)
+	}
+}
+
+// Float32s reads len(dst) consecutive float32 values starting at the specified
+// offset.
+func (b LittleEndianBlock) Float32s(offset int, dst []float32) {
+	if nativeLittleEndian {
+		copyFromBlock(dst, b[offset:])
+		return
+	}
+	for i := range dst {
+		dst[i] = b.Float32(offset + i*4)
+	}
+}
+
+// SetFloat32s places the float32 values of src consecutively starting at the
+// specified offset.
+func (b LittleEndianBlock) SetFloat32s(offset int, src []float32) {
+	if nativeLittleEndian {
+		copyToBlock(b[offset:], src)
+		return
+	}
+	for i, value := range src {
+		b.SetFloat32(offset+i*4, value)
+	}
+}
+
+// Float32sStrided reads len(dst) float32 values starting at the specified offset,
+// where each subsequent value is located stride bytes after the previous.
+func (b LittleEndianBlock) Float32sStrided(offset, stride int, dst []float32) {
+	for i := range dst {
+		dst[i] = b.Float32(offset + i*stride)
+	}
+}
+
+// SetFloat32sStrided places the float32 values of src starting at the specified
+// offset, where each subsequent value is located stride bytes after the
+// previous.
+func (b LittleEndianBlock) SetFloat32sStrided(offset, stride int, src []float32) {
+	for i, value := range src {
+		b.SetFloat32(offset+i*stride, value)
+	}
+}
+
+// Float64s reads len(dst) consecutive float64 values starting at the specified
+// offset.
+func (b LittleEndianBlock) Float64s(offset int, dst []float64) {
+	if nativeLittleEndian {
+		copyFromBlock(dst, b[offset:])
+		return
+	}
+	for i := range dst {
+		dst[i] = b.Float64(offset + i*8)
+	}
+}
+
+// SetFloat64s places the float64 values of src consecutively starting at the
+// specified offset.
+func (b LittleEndianBlock) SetFloat64s(offset int, src []float64) {
+	if nativeLittleEndian {
+		copyToBlock(b[offset:], src)
+		return
+	}
+	for i, value := range src {
+		b.SetFloat64(offset+i*8, value)
+	}
+}
+
+// Float64sStrided reads len(dst) float64 values starting at the specified offset,
+// where each subsequent value is located stride bytes after the previous.
+func (b LittleEndianBlock) Float64sStrided(offset, stride int, dst []float64) {
+	for i := range dst {
+		dst[i] = b.Float64(offset + i*stride)
+	}
+}
+
+// SetFloat64sStrided places the float64 values of src starting at the specified
+// offset, where each subsequent value is located stride bytes after the
+// previous.
+func (b LittleEndianBlock) SetFloat64sStrided(offset, stride int, src []float64) {
+	for i, value := range src {
+		b.SetFloat64(offset+i*stride, value)
+	}
+}
+
+// Uint8s reads len(dst) consecutive uint8 values starting at the specified
+// offset.
+func (b BigEndianBlock) Uint8s(offset int, dst []uint8) {
+	copyFromBlock(dst, b[offset:])
+}
+
+// SetUint8s places the uint8 values of src consecutively starting at the
+// specified offset.
+func (b BigEndianBlock) SetUint8s(offset int, src []uint8) {
+	copyToBlock(b[offset:], src)
+}
+
+// Uint8sStrided reads len(dst) uint8 values starting at the specified offset,
+// where each subsequent value is located stride bytes after the previous.
+func (b BigEndianBlock) Uint8sStrided(offset, stride int, dst []uint8) {
+	for i := range dst {
+		dst[i] = b.Uint8(offset + i*stride)
+	}
+}
+
+// SetUint8sStrided places the uint8 values of src starting at the specified
+// offset, where each subsequent value is located stride bytes after the
+// previous.
+func (b BigEndianBlock) SetUint8sStrided(offset, stride int, src []uint8) {
+	for i, value := range src {
+		b.SetUint8(offset+i*stride, value)
+	}
+}
+
+// Int8s reads len(dst) consecutive int8 values starting at the specified
+// offset.
+func (b BigEndianBlock) Int8s(offset int, dst []int8) {
+	copyFromBlock(dst, b[offset:])
+}
+
+// SetInt8s places the int8 values of src consecutively starting at the
+// specified offset.
+func (b BigEndianBlock) SetInt8s(offset int, src []int8) {
+	copyToBlock(b[offset:], src)
+}
+
+// Int8sStrided reads len(dst) int8 values starting at the specified offset,
+// where each subsequent value is located stride bytes after the previous.
+func (b BigEndianBlock) Int8sStrided(offset, stride int, dst []int8) {
+	for i := range dst {
+		dst[i] = b.Int8(offset + i*stride)
+	}
+}
+
+// SetInt8sStrided places the int8 values of src starting at the specified
+// offset, where each subsequent value is located stride bytes after the
+// previous.
+func (b BigEndianBlock) SetInt8sStrided(offset, stride int, src []int8) {
+	for i, value := range src {
+		b.SetInt8(offset+i*stride, value)
+	}
+}
+
+// Uint16s reads len(dst) consecutive uint16 values starting at the specified
+// offset.
+func (b BigEndianBlock) Uint16s(offset int, dst []uint16) {
+	if !nativeLittleEndian {
+		copyFromBlock(dst, b[offset:])
+		return
+	}
+	for i := range dst {
+		dst[i] = b.Uint16(offset + i*2)
+	}
+}
+
+// SetUint16s places the uint16 values of src consecutively starting at the
+// specified offset.
+func (b BigEndianBlock) SetUint16s(offset int, src []uint16) {
+	if !nativeLittleEndian {
+		copyToBlock(b[offset:], src)
+		return
+	}
+	for i, value := range src {
+		b.SetUint16(offset+i*2, value)
+	}
+}
+
+// Uint16sStrided reads len(dst) uint16 values starting at the specified offset,
+// where each subsequent value is located stride bytes after the previous.
+func (b BigEndianBlock) Uint16sStrided(offset, stride int, dst []uint16) {
+	for i := range dst {
+		dst[i] = b.Uint16(offset + i*stride)
+	}
+}
+
+// SetUint16sStrided places the uint16 values of src starting at the specified
+// offset, where each subsequent value is located stride bytes after the
+// previous.
+func (b BigEndianBlock) SetUint16sStrided(offset, stride int, src []uint16) {
+	for i, value := range src {
+		b.SetUint16(offset+i*stride, value)
+	}
+}
+
+// Int16s reads len(dst) consecutive int16 values starting at the specified
+// offset.
+func (b BigEndianBlock) Int16s(offset int, dst []int16) {
+	if !nativeLittleEndian {
+		copyFromBlock(dst, b[offset:])
+		return
+	}
+	for i := range dst {
+		dst[i] = b.Int16(offset + i*2)
+	}
+}
+
+// SetInt16s places the int16 values of src consecutively starting at the
+// specified offset.
+func (b BigEndianBlock) SetInt16s(offset int, src []int16) {
+	if !nativeLittleEndian {
+		copyToBlock(b[offset:], src)
+		return
+	}
+	for i, value := range src {
+		b.SetInt16(offset+i*2, value)
+	}
+}
+
+// Int16sStrided reads len(dst) int16 values starting at the specified offset,
+// where each subsequent value is located stride bytes after the previous.
+func (b BigEndianBlock) Int16sStrided(offset, stride int, dst []int16) {
+	for i := range dst {
+		dst[i] = b.Int16(offset + i*stride)
+	}
+}
+
+// SetInt16sStrided places the int16 values of src starting at the specified
+// offset, where each subsequent value is located stride bytes after the
+// previous.
+func (b BigEndianBlock) SetInt16sStrided(offset, stride int, src []int16) {
+	for i, value := range src {
+		b.SetInt16(offset+i*stride, value)
+	}
+}
+
+// Uint32s reads len(dst) consecutive uint32 values starting at the specified
+// offset.
+func (b BigEndianBlock) Uint32s(offset int, dst []uint32) {
+	if !nativeLittleEndian {
+		copyFromBlock(dst, b[offset:])
+		return
+	}
+	for i := range dst {
+		dst[i] = b.Uint32(offset + i*4)
+	}
+}
+
+// SetUint32s places the uint32 values of src consecutively starting at the
+// specified offset.
+func (b BigEndianBlock) SetUint32s(offset int, src []uint32) {
+	if !nativeLittleEndian {
+		copyToBlock(b[offset:], src)
+		return
+	}
+	for i, value := range src {
+		b.SetUint32(offset+i*4, value)
+	}
+}
+
+// Uint32sStrided reads len(dst) uint32 values starting at the specified offset,
+// where each subsequent value is located stride bytes after the previous.
+func (b BigEndianBlock) Uint32sStrided(offset, stride int, dst []uint32) {
+	for i := range dst {
+		dst[i] = b.Uint32(offset + i*stride)
+	}
+}
+
+// SetUint32sStrided places the uint32 values of src starting at the specified
+// offset, where each subsequent value is located stride bytes after the
+// previous.
+func (b BigEndianBlock) SetUint32sStrided(offset, stride int, src []uint32) {
+	for i, value := range src {
+		b.SetUint32(offset+i*stride, value)
+	}
+}
+
+// Int32s reads len(dst) consecutive int32 values starting at the specified
+// offset.
+func (b BigEndianBlock) Int32s(offset int, dst []int32) {
+	if !nativeLittleEndian {
+		copyFromBlock(dst, b[offset:])
+		return
+	}
+	for i := range dst {
+		dst[i] = b.Int32(offset + i*4)
+	}
+}
+
+// SetInt32s places the int32 values of src consecutively starting at the
+// specified offset.
+func (b BigEndianBlock) SetInt32s(offset int, src []int32) {
+	if !nativeLittleEndian {
+		copyToBlock(b[offset:], src)
+		return
+	}
+	for i, value := range src {
+		b.SetInt32(offset+i*4, value)
+	}
+}
+
+// Int32sStrided reads len(dst) int32 values starting at the specified offset,
+// where each subsequent value is located stride bytes after the previous.
+func (b BigEndianBlock) Int32sStrided(offset, stride int, dst []int32) {
+	for i := range dst {
+		dst[i] = b.Int32(offset + i*stride)
+	}
+}
+
+// SetInt32sStrided places the int32 values of src starting at the specified
+// offset, where each subsequent value is located stride bytes after the
+// previous.
+func (b BigEndianBlock) SetInt32sStrided(offset, stride int, src []int32) {
+	for i, value := range src {
+		b.SetInt32(offset+i*stride, value)
+	}
+}
+
+// Uint64s reads len(dst) consecutive uint64 values starting at the specified
+// offset.
+func (b BigEndianBlock) Uint64s(offset int, dst []uint64) {
+	if !nativeLittleEndian {
+		copyFromBlock(dst, b[offset:])
+		return
+	}
+	for i := range dst {
+		dst[i] = b.Uint64(offset + i*8)
+	}
+}
+
+// SetUint64s places the uint64 values of src consecutively starting at the
+// specified offset.
+func (b BigEndianBlock) SetUint64s(offset int, src []uint64) {
+	if !nativeLittleEndian {
+		copyToBlock(b[offset:], src)
+		return
+	}
+	for i, value := range src {
+		b.SetUint64(offset+i*8, value)
+	}
+}
+
+// Uint64sStrided reads len(dst) uint64 values starting at the specified offset,
+// where each subsequent value is located stride bytes after the previous.
+func (b BigEndianBlock) Uint64sStrided(offset, stride int, dst []uint64) {
+	for i := range dst {
+		dst[i] = b.Uint64(offset + i*stride)
+	}
+}
+
+// SetUint64sStrided places the uint64 values of src starting at the specified
+// offset, where each subsequent value is located stride bytes after the
+// previous.
+func (b BigEndianBlock) SetUint64sStrided(offset, stride int, src []uint64) {
+	for i, value := range src {
+		b.SetUint64(offset+i*stride, value)
+	}
+}
+
+// Int64s reads len(dst) consecutive int64 values starting at the specified
+// offset.
+func (b BigEndianBlock) Int64s(offset int, dst []int64) {
+	if !nativeLittleEndian {
+		copyFromBlock(dst, b[offset:])
+		return
+	}
+	for i := range dst {
+		dst[i] = b.Int64(offset + i*8)
+	}
+}
+
+// SetInt64s places the int64 values of src consecutively starting at the
+// specified offset.
+func (b BigEndianBlock) SetInt64s(offset int, src []int64) {
+	if !nativeLittleEndian {
+		copyToBlock(b[offset:], src)
+		return
+	}
+	for i, value := range src {
+		b.SetInt64(offset+i*8, value)
+	}
+}
+
+// Int64sStrided reads len(dst) int64 values starting at the specified offset,
+// where each subsequent value is located stride bytes after the previous.
+func (b BigEndianBlock) Int64sStrided(offset, stride int, dst []int64) {
+	for i := range dst {
+		dst[i] = b.Int64(offset + i*stride)
+	}
+}
+
+// SetInt64sStrided places the int64 values of src starting at the specified
+// offset, where each subsequent value is located stride bytes after the
+// previous.
+func (b BigEndianBlock) SetInt64sStrided(offset, stride int, src []int64) {
+	for i, value := range src {
+		b.SetInt64(offset+i*stride, value)
+	}
+}
+
+// Float32s reads len(dst) consecutive float32 values starting at the specified
+// offset.
+func (b BigEndianBlock) Float32s(offset int, dst []float32) {
+	if !nativeLittleEndian {
+		copyFromBlock(dst, b[offset:])
+		return
+	}
+	for i := range dst {
+		dst[i] = b.Float32(offset + i*4)
+	}
+}
+
+// SetFloat32s places the float32 values of src consecutively starting at the
+// specified offset.
+func (b BigEndianBlock) SetFloat32s(offset int, src []float32) {
+	if !nativeLittleEndian {
+		copyToBlock(b[offset:], src)
+		return
+	}
+	for i, value := range src {
+		b.SetFloat32(offset+i*4, value)
+	}
+}
+
+// Float32sStrided reads len(dst) float32 values starting at the specified offset,
+// where each subsequent value is located stride bytes after the previous.
+func (b BigEndianBlock) Float32sStrided(offset, stride int, dst []float32) {
+	for i := range dst {
+		dst[i] = b.Float32(offset + i*stride)
+	}
+}
+
+// SetFloat32sStrided places the float32 values of src starting at the specified
+// offset, where each subsequent value is located stride bytes after the
+// previous.
+func (b BigEndianBlock) SetFloat32sStrided(offset, stride int, src []float32) {
+	for i, value := range src {
+		b.SetFloat32(offset+i*stride, value)
+	}
+}
+
+// Float64s reads len(dst) consecutive float64 values starting at the specified
+// offset.
+func (b BigEndianBlock) Float64s(offset int, dst []float64) {
+	if !nativeLittleEndian {
+		copyFromBlock(dst, b[offset:])
+		return
+	}
+	for i := range dst {
+		dst[i] = b.Float64(offset + i*8)
+	}
+}
+
+// SetFloat64s places the float64 values of src consecutively starting at the
+// specified offset.
+func (b BigEndianBlock) SetFloat64s(offset int, src []float64) {
+	if !nativeLittleEndian {
+		copyToBlock(b[offset:], src)
+		return
+	}
+	for i, value := range src {
+		b.SetFloat64(offset+i*8, value)
+	}
+}
+
+// Float64sStrided reads len(dst) float64 values starting at the specified offset,
+// where each subsequent value is located stride bytes after the previous.
+func (b BigEndianBlock) Float64sStrided(offset, stride int, dst []float64) {
+	for i := range dst {
+		dst[i] = b.Float64(offset + i*stride)
+	}
+}
+
+// SetFloat64sStrided places the float64 values of src starting at the specified
+// offset, where each subsequent value is located stride bytes after the
+// previous.
+func (b BigEndianBlock) SetFloat64sStrided(offset, stride int, src []float64) {
+	for i, value := range src {
+		b.SetFloat64(offset+i*stride, value)
+	}
+}
+
+// copyFromBlock copies the raw bytes of src into the memory of dst. It should
+// only be used when the byte order of src matches the byte order of the host.
+func copyFromBlock[T any](dst []T, src []byte) {
+	target := sliceBytes(dst)
+	copy(target, src[:len(target)])
+}
+
+// copyToBlock copies the raw memory of src into dst. It should only be used
+// when the byte order of dst matches the byte order of the host.
+func copyToBlock[T any](dst []byte, src []T) {
+	source := sliceBytes(src)
+	copy(dst[:len(source)], source)
+}
+
+func sliceBytes[T any](slice []T) []byte {
+	if len(slice) == 0 {
+		return nil
+	}
+	var zero T
+	size := len(slice) * int(unsafe.Sizeof(zero))
+	return unsafe.Slice((*byte)(unsafe.Pointer(unsafe.SliceData(slice))), size)
+}
@@ -0,0 +1,147 @@
+package gblob_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+)
+
+var _ = Describe("LittleEndianBlock slices", func() {
+	// pad is just used to test offsets.
+	const pad = uint8(0x00)
+
+	Specify("Uint8s", func() {
+		block := gblob.LittleEndianBlock{pad, pad, 0x13, 0x14}
+		dst := make([]uint8, 2)
+		block.Uint8s(2, dst)
+		Expect(dst).To(Equal([]uint8{0x13, 0x14}))
+	})
+
+	Specify("SetInt8s", func() {
+		block := gblob.LittleEndianBlock{pad, pad, pad, pad}
+		block.SetInt8s(2, []int8{0x13, -1})
+		Expect([]uint8(block[2:])).To(Equal([]uint8{0x13, 0xFF}))
+	})
+
+	Specify("Uint16s", func() {
+		block := gblob.LittleEndianBlock{pad, pad, 0x52, 0x13, 0x44, 0x37}
+		dst := make([]uint16, 2)
+		block.Uint16s(2, dst)
+		Expect(dst).To(Equal([]uint16{0x1352, 0x3744}))
+	})
+
+	Specify("SetUint16s", func() {
+		block := gblob.LittleEndianBlock{pad, pad, pad, pad, pad, pad}
+		block.SetUint16s(2, []uint16{0x1352, 0x3744})
+		Expect([]uint8(block[2:])).To(Equal([]uint8{0x52, 0x13, 0x44, 0x37}))
+	})
+
+	Specify("Int64s", func() {
+		block := gblob.LittleEndianBlock{
+			0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			0xFE, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+		}
+		dst := make([]int64, 2)
+		block.Int64s(0, dst)
+		Expect(dst).To(Equal([]int64{1, -2}))
+	})
+
+	Specify("Float32s", func() {
+		block := gblob.LittleEndianBlock{pad, pad, 0xCD, 0xCC, 0xAC, 0x40, 0x9A, 0x99, 0x99, 0x3F}
+		dst := make([]float32, 2)
+		block.Float32s(2, dst)
+		Expect(dst).To(Equal([]float32{5.4, 1.2}))
+	})
+
+	Specify("SetFloat32s", func() {
+		block := make(gblob.LittleEndianBlock, 10)
+		block.SetFloat32s(2, []float32{5.4, 1.2})
+		Expect([]uint8(block[2:])).To(Equal([]uint8{0xCD, 0xCC, 0xAC, 0x40, 0x9A, 0x99, 0x99, 0x3F}))
+	})
+
+	Specify("Float32sStrided", func() {
+		block := gblob.LittleEndianBlock{
+			pad, pad, 0xCD, 0xCC, 0xAC, 0x40, pad, pad,
+			pad, pad, 0x9A, 0x99, 0x99, 0x3F, pad, pad,
+		}
+		dst := make([]float32, 2)
+		block.Float32sStrided(2, 8, dst)
+		Expect(dst).To(Equal([]float32{5.4, 1.2}))
+	})
+
+	Specify("SetFloat32sStrided", func() {
+		block := make(gblob.LittleEndianBlock, 16)
+		block.SetFloat32sStrided(2, 8, []float32{5.4, 1.2})
+		Expect([]uint8(block)).To(Equal([]uint8{
+			pad, pad, 0xCD, 0xCC, 0xAC, 0x40, pad, pad,
+			pad, pad, 0x9A, 0x99, 0x99, 0x3F, pad, pad,
+		}))
+	})
+
+	Specify("out of range", func() {
+		block := make(gblob.LittleEndianBlock, 6)
+		Expect(func() {
+			block.Uint32s(2, make([]uint32, 2))
+		}).To(Panic())
+		Expect(func() {
+			block.SetUint32s(2, make([]uint32, 2))
+		}).To(Panic())
+	})
+})
+
+var _ = Describe("BigEndianBlock slices", func() {
+	// pad is just used to test offsets.
+	const pad = uint8(0x00)
+
+	Specify("Uint16s", func() {
+		block := gblob.BigEndianBlock{pad, pad, 0x13, 0x52, 0x37, 0x44}
+		dst := make([]uint16, 2)
+		block.Uint16s(2, dst)
+		Expect(dst).To(Equal([]uint16{0x1352, 0x3744}))
+	})
+
+	Specify("SetUint16s", func() {
+		block := gblob.BigEndianBlock{pad, pad, pad, pad, pad, pad}
+		block.SetUint16s(2, []uint16{0x1352, 0x3744})
+		Expect([]uint8(block[2:])).To(Equal([]uint8{0x13, 0x52, 0x37, 0x44}))
+	})
+
+	Specify("Float64s", func() {
+		block := gblob.BigEndianBlock{
+			0x40, 0x15, 0x99, 0x99, 0x99, 0x99, 0x99, 0x9A,
+			0x3F, 0xF3, 0x33, 0x33, 0x33, 0x33, 0x33, 0x33,
+		}
+		dst := make([]float64, 2)
+		block.Float64s(0, dst)
+		Expect(dst).To(Equal([]float64{5.4, 1.2}))
+	})
+
+	Specify("SetInt32s", func() {
+		block := make(gblob.BigEndianBlock, 8)
+		block.SetInt32s(0, []int32{1, -2})
+		Expect([]uint8(block)).To(Equal([]uint8{
+			0x00, 0x00, 0x00, 0x01,
+			0xFF, 0xFF, 0xFF, 0xFE,
+		}))
+	})
+
+	Specify("Uint32sStrided", func() {
+		block := gblob.BigEndianBlock{
+			0x37, 0x44, 0x13, 0x52, pad, pad,
+			0x01, 0x02, 0x03, 0x04, pad, pad,
+		}
+		dst := make([]uint32, 2)
+		block.Uint32sStrided(0, 6, dst)
+		Expect(dst).To(Equal([]uint32{0x37441352, 0x01020304}))
+	})
+
+	Specify("SetUint32sStrided", func() {
+		block := make(gblob.BigEndianBlock, 12)
+		block.SetUint32sStrided(0, 6, []uint32{0x37441352, 0x01020304})
+		Expect([]uint8(block)).To(Equal([]uint8{
+			0x37, 0x44, 0x13, 0x52, pad, pad,
+			0x01, 0x02, 0x03, 0x04, pad, pad,
+		}))
+	})
+})
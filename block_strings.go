@@ -0,0 +1,158 @@
+package gblob
+
+import (
+	"bytes"
+	"fmt"
+)
+
+// LengthPrefix specifies the number of bytes that are used to store the
+// length of a variable-sized value.
+type LengthPrefix uint8
+
+const (
+	// LengthPrefix8 stores the length as a uint8.
+	LengthPrefix8 LengthPrefix = 1
+
+	// LengthPrefix16 stores the length as a uint16.
+	LengthPrefix16 LengthPrefix = 2
+
+	// LengthPrefix32 stores the length as a uint32.
+	LengthPrefix32 LengthPrefix = 4
+
+	// LengthPrefix64 stores the length as a uint64. This is the format that
+	// is used by PackedEncoder.
+	LengthPrefix64 LengthPrefix = 8
+)
+
+// Bytes returns count bytes starting at the specified offset. The returned
+// slice shares memory with the block.
+func (b LittleEndianBlock) Bytes(offset, count int) []byte {
+	return b[offset : offset+count : offset+count]
+}
+
+// SetBytes places the bytes of src starting at the specified offset.
+func (b LittleEndianBlock) SetBytes(offset int, src []byte) {
+	copy(b[offset:offset+len(src)], src)
+}
+
+// FixedString returns the string stored in a field of size bytes at the
+// specified offset. The string ends at the first NUL byte, if any.
+func (b LittleEndianBlock) FixedString(offset, size int) string {
+	return blockFixedString(b, offset, size)
+}
+
+// SetFixedString places the string value in a field of size bytes at the
+// specified offset. Unused bytes are set to NUL and strings that do not
+// fit are truncated.
+func (b LittleEndianBlock) SetFixedString(offset, size int, value string) {
+	blockSetFixedString(b, offset, size, value)
+}
+
+// PrefixedString returns the string at the specified offset that is
+// preceded by a length of the specified width.
+func (b LittleEndianBlock) PrefixedString(offset int, prefix LengthPrefix) string {
+	return blockPrefixedString(b, offset, prefix)
+}
+
+// SetPrefixedString places the string value at the specified offset,
+// preceded by its length in the specified width. The value occupies
+// int(prefix)+len(value) bytes.
+func (b LittleEndianBlock) SetPrefixedString(offset int, prefix LengthPrefix, value string) {
+	blockSetPrefixedString(b, offset, prefix, value)
+}
+
+// Bytes returns count bytes starting at the specified offset. The returned
+// slice shares memory with the block.
+func (b BigEndianBlock) Bytes(offset, count int) []byte {
+	return b[offset : offset+count : offset+count]
+}
+
+// SetBytes places the bytes of src starting at the specified offset.
+func (b BigEndianBlock) SetBytes(offset int, src []byte) {
+	copy(b[offset:offset+len(src)], src)
+}
+
+// FixedString returns the string stored in a field of size bytes at the
+// specified offset. The string ends at the first NUL byte, if any.
+func (b BigEndianBlock) FixedString(offset, size int) string {
+	return blockFixedString(b, offset, size)
+}
+
+// SetFixedString places the string value in a field of size bytes at the
+// specified offset. Unused bytes are set to NUL and strings that do not
+// fit are truncated.
+func (b BigEndianBlock) SetFixedString(offset, size int, value string) {
+	blockSetFixedString(b, offset, size, value)
+}
+
+// PrefixedString returns the string at the specified offset that is
+// preceded by a length of the specified width.
+func (b BigEndianBlock) PrefixedString(offset int, prefix LengthPrefix) string {
+	return blockPrefixedString(b, offset, prefix)
+}
+
+// SetPrefixedString places the string value at the specified offset,
+// preceded by its length in the specified width. The value occupies
+// int(prefix)+len(value) bytes.
+func (b BigEndianBlock) SetPrefixedString(offset int, prefix LengthPrefix, value string) {
+	blockSetPrefixedString(b, offset, prefix, value)
+}
+
+func blockFixedString[T blockBuffer](b T, offset, size int) string {
+	field := b[offset : offset+size]
+	if end := bytes.IndexByte(field, 0x00); end >= 0 {
+		field = field[:end]
+	}
+	return string(field)
+}
+
+func blockSetFixedString[T blockBuffer](b T, offset, size int, value string) {
+	field := b[offset : offset+size]
+	count := copy(field, value)
+	clear(field[count:])
+}
+
+func blockPrefixedString[T blockBuffer](b T, offset int, prefix LengthPrefix) string {
+	length := blockLength(b, offset, prefix)
+	start := offset + int(prefix)
+	return string(b[start : start+length])
+}
+
+func blockSetPrefixedString[T blockBuffer](b T, offset int, prefix LengthPrefix, value string) {
+	blockSetLength(b, offset, prefix, len(value))
+	start := offset + int(prefix)
+	copy(b[start:start+len(value)], value)
+}
+
+func blockLength[T blockBuffer](b T, offset int, prefix LengthPrefix) int {
+	switch prefix {
+	case LengthPrefix8:
+		return int(b.Uint8(offset))
+	case LengthPrefix16:
+		return int(b.Uint16(offset))
+	case LengthPrefix32:
+		return int(b.Uint32(offset))
+	case LengthPrefix64:
+		return int(b.Uint64(offset))
+	default:
+		panic(fmt.Errorf("unsupported length prefix: %d", prefix))
+	}
+}
+
+func blockSetLength[T blockBuffer](b T, offset int, prefix LengthPrefix, length int) {
+	if prefix < LengthPrefix64 && uint64(length) >= 1<<(8*uint64(prefix)) {
+		panic(fmt.Errorf("length %d does not fit in %d byte prefix", length, prefix))
+	}
+	switch prefix {
+	case LengthPrefix8:
+		b.SetUint8(offset, uint8(length))
+	case LengthPrefix16:
+		b.SetUint16(offset, uint16(length))
+	case LengthPrefix32:
+		b.SetUint32(offset, uint32(length))
+	case LengthPrefix64:
+		b.SetUint64(offset, uint64(length))
+	default:
+		panic(fmt.Errorf("unsupported length prefix: %d", prefix))
+	}
+}
@@ -0,0 +1,82 @@
+package gblob_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+)
+
+var _ = Describe("LittleEndianBlock strings", func() {
+	// pad is just used to test offsets.
+	const pad = uint8(0x00)
+
+	Specify("Bytes", func() {
+		block := gblob.LittleEndianBlock{pad, pad, 0x13, 0x14, 0x15}
+		Expect(block.Bytes(2, 2)).To(Equal([]uint8{0x13, 0x14}))
+	})
+
+	Specify("SetBytes", func() {
+		block := gblob.LittleEndianBlock{pad, pad, pad, pad, pad}
+		block.SetBytes(2, []uint8{0x13, 0x14})
+		Expect([]uint8(block)).To(Equal([]uint8{pad, pad, 0x13, 0x14, pad}))
+	})
+
+	Specify("FixedString", func() {
+		block := gblob.LittleEndianBlock{pad, 'a', 'b', 'c', 0x00, 'x'}
+		Expect(block.FixedString(1, 5)).To(Equal("abc"))
+		Expect(block.FixedString(1, 2)).To(Equal("ab"))
+	})
+
+	Specify("SetFixedString", func() {
+		block := gblob.LittleEndianBlock{pad, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
+		block.SetFixedString(1, 4, "ab")
+		Expect([]uint8(block)).To(Equal([]uint8{pad, 'a', 'b', 0x00, 0x00, 0xFF}))
+
+		block.SetFixedString(1, 4, "abcdef")
+		Expect([]uint8(block)).To(Equal([]uint8{pad, 'a', 'b', 'c', 'd', 0xFF}))
+	})
+
+	Specify("PrefixedString", func() {
+		block := gblob.LittleEndianBlock{pad, 0x03, 0x00, 'a', 'b', 'c'}
+		Expect(block.PrefixedString(1, gblob.LengthPrefix16)).To(Equal("abc"))
+	})
+
+	Specify("SetPrefixedString", func() {
+		block := make(gblob.LittleEndianBlock, 12)
+		block.SetPrefixedString(1, gblob.LengthPrefix64, "abc")
+		Expect([]uint8(block)).To(Equal([]uint8{
+			pad,
+			0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+			'a', 'b', 'c',
+		}))
+	})
+
+	Specify("SetPrefixedString overflow", func() {
+		block := make(gblob.LittleEndianBlock, 512)
+		Expect(func() {
+			block.SetPrefixedString(0, gblob.LengthPrefix8, string(make([]byte, 256)))
+		}).To(Panic())
+	})
+})
+
+var _ = Describe("BigEndianBlock strings", func() {
+	// pad is just used to test offsets.
+	const pad = uint8(0x00)
+
+	Specify("FixedString", func() {
+		block := gblob.BigEndianBlock{pad, 'a', 'b', 'c', 0x00, 'x'}
+		Expect(block.FixedString(1, 5)).To(Equal("abc"))
+	})
+
+	Specify("PrefixedString", func() {
+		block := gblob.BigEndianBlock{pad, 0x00, 0x00, 0x00, 0x03, 'a', 'b', 'c'}
+		Expect(block.PrefixedString(1, gblob.LengthPrefix32)).To(Equal("abc"))
+	})
+
+	Specify("SetPrefixedString", func() {
+		block := make(gblob.BigEndianBlock, 6)
+		block.SetPrefixedString(1, gblob.LengthPrefix16, "abc")
+		Expect([]uint8(block)).To(Equal([]uint8{pad, 0x00, 0x03, 'a', 'b', 'c'}))
+	})
+})
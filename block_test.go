@@ -1,6 +1,8 @@
 package gblob_test
 
 import (
+	"encoding/binary"
+
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
@@ -236,3 +238,29 @@ var _ = Describe("BigEndianBlock", func() {
 		Expect([]uint8(block[2:])).To(Equal([]uint8{0x40, 0x17, 0xB8, 0x51, 0xEB, 0x85, 0x1E, 0xB8}))
 	})
 })
+
+var _ = Describe("NativeEndianBlock", func() {
+	Specify("Uint32", func() {
+		block := make(gblob.NativeEndianBlock, 4)
+		binary.NativeEndian.PutUint32(block, 0x37441352)
+		Expect(block.Uint32(0)).To(Equal(uint32(0x37441352)))
+	})
+
+	Specify("SetUint32", func() {
+		block := make(gblob.NativeEndianBlock, 4)
+		block.SetUint32(0, 0x37441352)
+		Expect(binary.NativeEndian.Uint32(block)).To(Equal(uint32(0x37441352)))
+	})
+
+	Specify("Uint64", func() {
+		block := make(gblob.NativeEndianBlock, 8)
+		binary.NativeEndian.PutUint64(block, 0x3744135277665544)
+		Expect(block.Uint64(0)).To(Equal(uint64(0x3744135277665544)))
+	})
+
+	Specify("SetUint64", func() {
+		block := make(gblob.NativeEndianBlock, 8)
+		block.SetUint64(0, 0x3744135277665544)
+		Expect(binary.NativeEndian.Uint64(block)).To(Equal(uint64(0x3744135277665544)))
+	})
+})
@@ -0,0 +1,673 @@
+package gblob
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// cborMaxDepth is the deepest nesting of arrays, maps and tags that is
+// decoded, so that deeply nested input cannot exhaust the stack.
+const cborMaxDepth = 1000
+
+// CBORTag represents a tagged CBOR value whose tag is not known to the
+// CBORDecoder, when decoded into an empty interface.
+type CBORTag struct {
+
+	// Number holds the tag number.
+	Number uint64
+
+	// Content holds the decoded tag content.
+	Content any
+}
+
+// NewCBORDecoder creates a new CBORDecoder that reads from the specified in
+// Reader.
+func NewCBORDecoder(in io.Reader) *CBORDecoder {
+	return &CBORDecoder{
+		in: NewBigEndianReader(in),
+	}
+}
+
+// CBORDecoder decodes arbitrary Go objects from CBOR form, as specified by
+// RFC 8949.
+//
+// Both definite and indefinite-length items are supported. Struct fields are
+// matched to map keys by name, falling back to a case-insensitive match, or by
+// field number for structs that implement PackedTagged. Map entries that do
+// not match a field are skipped. A time.Time can be decoded from tag 0 or 1
+// and a big.Int from an integer or from tag 2 or 3. Other tags are ignored,
+// unless the target is an empty interface.
+//
+// Values that are decoded into an empty interface are represented by nil,
+// bool, uint64, int64, *big.Int, float64, string, []byte, []any, map[any]any,
+// time.Time and CBORTag values.
+//
+// Items that are nested more than 1000 levels deep are rejected.
+type CBORDecoder struct {
+	in    TypedReader
+	depth int
+}
+
+// Decode decodes the specified target value from the Reader. The target
+// needs to be a non-nil pointer.
+func (d *CBORDecoder) Decode(target any) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return fmt.Errorf("target needs to be a non-nil pointer")
+	}
+	head, err := d.readHead()
+	if err != nil {
+		return err
+	}
+	if !head.isNull() && value.Type().Implements(decodableType) {
+		return d.decodeDecodable(head, value.Interface().(PackedDecodable))
+	}
+	return d.decodeValue(head, value.Elem())
+}
+
+type cborHead struct {
+	major      uint8
+	info       uint8
+	argument   uint64
+	indefinite bool
+}
+
+func (h cborHead) isNull() bool {
+	return h.major == cborSimple && (h.info == cborNull || h.info == cborUndefined)
+}
+
+func (h cborHead) isBreak() bool {
+	return h.major == cborSimple && h.indefinite
+}
+
+func (h cborHead) length() (int, error) {
+	if h.argument > math.MaxInt32 {
+		return 0, fmt.Errorf("length %d is too large", h.argument)
+	}
+	return int(h.argument), nil
+}
+
+func (d *CBORDecoder) readHead() (cborHead, error) {
+	initial, err := d.in.ReadUint8()
+	if err != nil {
+		return cborHead{}, err
+	}
+	head := cborHead{
+		major: initial >> 5,
+		info:  initial & 0x1F,
+	}
+	switch {
+	case head.info < 24:
+		head.argument = uint64(head.info)
+	case head.info == 24:
+		v, err := d.in.ReadUint8()
+		head.argument = uint64(v)
+		return head, eofAsUnexpected(err)
+	case head.info == 25:
+		v, err := d.in.ReadUint16()
+		head.argument = uint64(v)
+		return head, eofAsUnexpected(err)
+	case head.info == 26:
+		v, err := d.in.ReadUint32()
+		head.argument = uint64(v)
+		return head, eofAsUnexpected(err)
+	case head.info == 27:
+		v, err := d.in.ReadUint64()
+		head.argument = v
+		return head, eofAsUnexpected(err)
+	case head.info == cborBreak:
+		switch head.major {
+		case cborBytes, cborText, cborArray, cborMap, cborSimple:
+			head.indefinite = true
+		default:
+			return cborHead{}, fmt.Errorf("invalid indefinite length for major type %d", head.major)
+		}
+	default:
+		return cborHead{}, fmt.Errorf("reserved additional information %d", head.info)
+	}
+	return head, nil
+}
+
+func (d *CBORDecoder) readNextHead() (cborHead, error) {
+	head, err := d.readHead()
+	return head, eofAsUnexpected(err)
+}
+
+func (d *CBORDecoder) decodeNext(value reflect.Value) error {
+	head, err := d.readNextHead()
+	if err != nil {
+		return err
+	}
+	return d.decodeValue(head, value)
+}
+
+func (d *CBORDecoder) decodeValue(head cborHead, value reflect.Value) error {
+	if head.isBreak() {
+		return errors.New("unexpected break")
+	}
+	if head.isNull() {
+		value.SetZero()
+		return nil
+	}
+	if value.Kind() == reflect.Pointer && value.IsNil() {
+		value.Set(reflect.New(value.Type().Elem()))
+	}
+	if decodeHookOf(value) == hookLazy {
+		lazy, _ := asLazyDecodable(value)
+		return lazy.decodeEager(func(target any) error {
+			return d.decodeValue(head, reflect.ValueOf(target).Elem())
+		})
+	}
+	if value.Type().Implements(decodableType) {
+		return d.decodeDecodable(head, value.Interface().(PackedDecodable))
+	}
+	switch value.Type() {
+	case timeType:
+		return d.decodeTime(head, value)
+	case bigIntType:
+		number, err := d.decodeBigInt(head)
+		if err != nil {
+			return err
+		}
+		value.Set(reflect.ValueOf(number).Elem())
+		return nil
+	}
+	if head.major == cborTag && value.Kind() != reflect.Interface {
+		if err := d.enter(); err != nil {
+			return err
+		}
+		defer d.leave()
+		return d.decodeNext(value)
+	}
+	switch kind := value.Kind(); kind {
+	case reflect.Pointer:
+		return d.decodeValue(head, value.Elem())
+	case reflect.Interface:
+		if value.NumMethod() > 0 {
+			return fmt.Errorf("unsupported interface type: %v", value.Type())
+		}
+		v, err := d.decodeAny(head)
+		if err != nil {
+			return err
+		}
+		if v != nil {
+			value.Set(reflect.ValueOf(v))
+		}
+		return nil
+	case reflect.Bool:
+		switch {
+		case head.major == cborSimple && head.info == cborTrue:
+			value.SetBool(true)
+		case head.major == cborSimple && head.info == cborFalse:
+			value.SetBool(false)
+		default:
+			return unexpectedCBORType(head, value.Type())
+		}
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		var v int64
+		switch {
+		case head.major == cborUint && head.argument <= math.MaxInt64:
+			v = int64(head.argument)
+		case head.major == cborNegative && head.argument <= math.MaxInt64:
+			v = -1 - int64(head.argument)
+		case head.major == cborUint || head.major == cborNegative:
+			return fmt.Errorf("integer overflows %v", value.Type())
+		default:
+			return unexpectedCBORType(head, value.Type())
+		}
+		if value.OverflowInt(v) {
+			return fmt.Errorf("value %d overflows %v", v, value.Type())
+		}
+		value.SetInt(v)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		switch head.major {
+		case cborUint:
+			if value.OverflowUint(head.argument) {
+				return fmt.Errorf("value %d overflows %v", head.argument, value.Type())
+			}
+			value.SetUint(head.argument)
+			return nil
+		case cborNegative:
+			return fmt.Errorf("negative integer overflows %v", value.Type())
+		default:
+			return unexpectedCBORType(head, value.Type())
+		}
+	case reflect.Float32, reflect.Float64:
+		v, err := d.readFloat(head)
+		if err != nil {
+			return unexpectedCBORType(head, value.Type())
+		}
+		value.SetFloat(v)
+		return nil
+	case reflect.String:
+		if head.major != cborText && head.major != cborBytes {
+			return unexpectedCBORType(head, value.Type())
+		}
+		data, err := d.readString(head)
+		if err != nil {
+			return err
+		}
+		value.SetString(string(data))
+		return nil
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Uint8 && head.major == cborBytes { // fast track
+			data, err := d.readString(head)
+			if err != nil {
+				return err
+			}
+			value.Set(reflect.ValueOf(data).Convert(value.Type()))
+			return nil
+		}
+		if head.major != cborArray {
+			return unexpectedCBORType(head, value.Type())
+		}
+		elements := reflect.MakeSlice(value.Type(), 0, 0)
+		err := d.forEachItem(head, func(item cborHead) error {
+			element := reflect.New(value.Type().Elem()).Elem()
+			if err := d.decodeValue(item, element); err != nil {
+				return err
+			}
+			elements = reflect.Append(elements, element)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		value.Set(elements)
+		return nil
+	case reflect.Array:
+		if head.major != cborArray {
+			return unexpectedCBORType(head, value.Type())
+		}
+		index := 0
+		err := d.forEachItem(head, func(item cborHead) error {
+			defer func() {
+				index++
+			}()
+			if index >= value.Len() {
+				_, err := d.decodeAny(item)
+				return err
+			}
+			return d.decodeValue(item, value.Index(index))
+		})
+		if err != nil {
+			return err
+		}
+		for ; index < value.Len(); index++ {
+			value.Index(index).SetZero()
+		}
+		return nil
+	case reflect.Map:
+		if head.major != cborMap {
+			return unexpectedCBORType(head, value.Type())
+		}
+		value.Set(reflect.MakeMap(value.Type()))
+		return d.forEachItem(head, func(item cborHead) error {
+			entryKey := reflect.New(value.Type().Key()).Elem()
+			if err := d.decodeValue(item, entryKey); err != nil {
+				return err
+			}
+			entryValue := reflect.New(value.Type().Elem()).Elem()
+			if err := d.decodeNext(entryValue); err != nil {
+				return err
+			}
+			value.SetMapIndex(entryKey, entryValue)
+			return nil
+		})
+	case reflect.Struct:
+		if head.major != cborMap {
+			return unexpectedCBORType(head, value.Type())
+		}
+		return d.decodeStruct(head, value)
+	default:
+		return fmt.Errorf("unsupported type: %v", kind)
+	}
+}
+
+func (d *CBORDecoder) decodeStruct(head cborHead, value reflect.Value) error {
+	var fields []taggedField
+	if isTagged(value.Type()) {
+		var err error
+		if fields, err = taggedFieldsOf(value.Type()); err != nil {
+			return err
+		}
+	}
+	structType := value.Type()
+	findField := func(key any) (reflect.Value, bool) {
+		switch key := key.(type) {
+		case uint64:
+			for _, field := range fields {
+				if uint64(field.number) == key {
+					return value.Field(field.index), true
+				}
+			}
+		case string:
+			if fields != nil {
+				break
+			}
+			if field, ok := structType.FieldByName(key); ok && field.IsExported() && len(field.Index) == 1 {
+				return value.Field(field.Index[0]), true
+			}
+			for i := range structType.NumField() {
+				if field := structType.Field(i); field.IsExported() && strings.EqualFold(field.Name, key) {
+					return value.Field(i), true
+				}
+			}
+		}
+		return reflect.Value{}, false
+	}
+	return d.forEachItem(head, func(item cborHead) error {
+		key, err := d.decodeAny(item)
+		if err != nil {
+			return err
+		}
+		fieldValue, ok := findField(key)
+		if !ok {
+			return d.skipNext()
+		}
+		return d.decodeNext(fieldValue)
+	})
+}
+
+func (d *CBORDecoder) decodeTime(head cborHead, value reflect.Value) error {
+	if head.major != cborTag {
+		return unexpectedCBORType(head, value.Type())
+	}
+	content, err := d.readNextHead()
+	if err != nil {
+		return err
+	}
+	result, err := d.readTime(head.argument, content)
+	if err != nil {
+		return err
+	}
+	value.Set(reflect.ValueOf(result))
+	return nil
+}
+
+func (d *CBORDecoder) readTime(tag uint64, content cborHead) (time.Time, error) {
+	switch {
+	case tag == cborTagDateTime && content.major == cborText:
+		data, err := d.readString(content)
+		if err != nil {
+			return time.Time{}, err
+		}
+		return time.Parse(time.RFC3339Nano, string(data))
+	case tag == cborTagEpochTime && content.major == cborUint:
+		if content.argument > math.MaxInt64 {
+			return time.Time{}, fmt.Errorf("epoch time %d is out of range", content.argument)
+		}
+		return time.Unix(int64(content.argument), 0).UTC(), nil
+	case tag == cborTagEpochTime && content.major == cborNegative:
+		if content.argument > math.MaxInt64 {
+			return time.Time{}, fmt.Errorf("epoch time -%d is out of range", content.argument)
+		}
+		return time.Unix(-1-int64(content.argument), 0).UTC(), nil
+	case tag == cborTagEpochTime && content.major == cborSimple:
+		seconds, err := d.readFloat(content)
+		if err != nil {
+			return time.Time{}, err
+		}
+		whole, fraction := math.Modf(seconds)
+		return time.Unix(int64(whole), int64(math.Round(fraction*1e9))).UTC(), nil
+	default:
+		return time.Time{}, fmt.Errorf("unsupported time encoding: tag %d with major type %d", tag, content.major)
+	}
+}
+
+func (d *CBORDecoder) decodeBigInt(head cborHead) (*big.Int, error) {
+	switch head.major {
+	case cborUint:
+		return new(big.Int).SetUint64(head.argument), nil
+	case cborNegative:
+		number := new(big.Int).SetUint64(head.argument)
+		return number.Sub(big.NewInt(-1), number), nil
+	case cborTag:
+		content, err := d.readNextHead()
+		if err != nil {
+			return nil, err
+		}
+		return d.readBigInt(head.argument, content)
+	default:
+		return nil, unexpectedCBORType(head, bigIntType)
+	}
+}
+
+func (d *CBORDecoder) readBigInt(tag uint64, content cborHead) (*big.Int, error) {
+	if content.major != cborBytes {
+		return nil, fmt.Errorf("unsupported bignum encoding: major type %d", content.major)
+	}
+	data, err := d.readString(content)
+	if err != nil {
+		return nil, err
+	}
+	number := new(big.Int).SetBytes(data)
+	switch tag {
+	case cborTagPositiveBig:
+		return number, nil
+	case cborTagNegativeBig:
+		return number.Sub(big.NewInt(-1), number), nil
+	default:
+		return nil, fmt.Errorf("unsupported bignum tag: %d", tag)
+	}
+}
+
+func (d *CBORDecoder) decodeDecodable(head cborHead, decodable PackedDecodable) error {
+	if head.major != cborBytes {
+		return unexpectedCBORType(head, reflect.TypeOf(decodable))
+	}
+	data, err := d.readString(head)
+	if err != nil {
+		return err
+	}
+	return decodable.DecodePacked(NewBigEndianReader(bytes.NewReader(data)))
+}
+
+func (d *CBORDecoder) decodeAny(head cborHead) (any, error) {
+	switch head.major {
+	case cborUint:
+		return head.argument, nil
+	case cborNegative:
+		if head.argument <= math.MaxInt64 {
+			return -1 - int64(head.argument), nil
+		}
+		return d.decodeBigInt(head)
+	case cborBytes:
+		return d.readString(head)
+	case cborText:
+		data, err := d.readString(head)
+		return string(data), err
+	case cborArray:
+		result := make([]any, 0)
+		err := d.forEachItem(head, func(item cborHead) error {
+			element, err := d.decodeAny(item)
+			result = append(result, element)
+			return err
+		})
+		return result, err
+	case cborMap:
+		result := make(map[any]any)
+		err := d.forEachItem(head, func(item cborHead) error {
+			key, err := d.decodeAny(item)
+			if err != nil {
+				return err
+			}
+			if data, ok := key.([]byte); ok {
+				key = string(data)
+			}
+			if key == nil || !reflect.TypeOf(key).Comparable() {
+				return fmt.Errorf("unsupported map key type: %T", key)
+			}
+			valueHead, err := d.readNextHead()
+			if err != nil {
+				return err
+			}
+			result[key], err = d.decodeAny(valueHead)
+			return err
+		})
+		return result, err
+	case cborTag:
+		if err := d.enter(); err != nil {
+			return nil, err
+		}
+		defer d.leave()
+		content, err := d.readNextHead()
+		if err != nil {
+			return nil, err
+		}
+		switch head.argument {
+		case cborTagDateTime, cborTagEpochTime:
+			return d.readTime(head.argument, content)
+		case cborTagPositiveBig, cborTagNegativeBig:
+			return d.readBigInt(head.argument, content)
+		}
+		value, err := d.decodeAny(content)
+		if err != nil {
+			return nil, err
+		}
+		return CBORTag{
+			Number:  head.argument,
+			Content: value,
+		}, nil
+	default:
+		switch {
+		case head.isBreak():
+			return nil, errors.New("unexpected break")
+		case head.isNull():
+			return nil, nil
+		case head.info == cborTrue:
+			return true, nil
+		case head.info == cborFalse:
+			return false, nil
+		}
+		return d.readFloat(head)
+	}
+}
+
+func (d *CBORDecoder) skipNext() error {
+	head, err := d.readNextHead()
+	if err != nil {
+		return err
+	}
+	_, err = d.decodeAny(head)
+	return err
+}
+
+// enter records that a nested item is being decoded and fails if the maximum
+// depth is exceeded. Each successful call needs to be followed by leave.
+func (d *CBORDecoder) enter() error {
+	if d.depth >= cborMaxDepth {
+		return fmt.Errorf("nesting exceeds maximum depth of %d", cborMaxDepth)
+	}
+	d.depth++
+	return nil
+}
+
+func (d *CBORDecoder) leave() {
+	d.depth--
+}
+
+func (d *CBORDecoder) forEachItem(head cborHead, fn func(item cborHead) error) error {
+	if err := d.enter(); err != nil {
+		return err
+	}
+	defer d.leave()
+	if !head.indefinite {
+		count, err := head.length()
+		if err != nil {
+			return err
+		}
+		for i := 0; i < count; i++ {
+			item, err := d.readNextHead()
+			if err != nil {
+				return err
+			}
+			if err := fn(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for {
+		item, err := d.readNextHead()
+		if err != nil {
+			return err
+		}
+		if item.isBreak() {
+			return nil
+		}
+		if err := fn(item); err != nil {
+			return err
+		}
+	}
+}
+
+func (d *CBORDecoder) readString(head cborHead) ([]byte, error) {
+	if !head.indefinite {
+		count, err := head.length()
+		if err != nil {
+			return nil, err
+		}
+		return readChunked(d.in, count)
+	}
+	var result []byte
+	for {
+		chunk, err := d.readNextHead()
+		if err != nil {
+			return nil, err
+		}
+		if chunk.isBreak() {
+			return result, nil
+		}
+		if chunk.major != head.major || chunk.indefinite {
+			return nil, fmt.Errorf("invalid chunk of major type %d in indefinite-length string", chunk.major)
+		}
+		data, err := d.readString(chunk)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, data...)
+	}
+}
+
+func (d *CBORDecoder) readFloat(head cborHead) (float64, error) {
+	switch {
+	case head.major == cborUint:
+		return float64(head.argument), nil
+	case head.major == cborNegative:
+		return -1 - float64(head.argument), nil
+	case head.major != cborSimple:
+		return 0, unexpectedCBORType(head, nil)
+	case head.info == cborFloat16:
+		return float16Value(uint16(head.argument)), nil
+	case head.info == cborFloat32:
+		return float64(math.Float32frombits(uint32(head.argument))), nil
+	case head.info == cborFloat64:
+		return math.Float64frombits(head.argument), nil
+	default:
+		return 0, fmt.Errorf("unsupported simple value %d", head.argument)
+	}
+}
+
+func unexpectedCBORType(head cborHead, t reflect.Type) error {
+	if t == nil {
+		return fmt.Errorf("unexpected CBOR major type %d", head.major)
+	}
+	return fmt.Errorf("unexpected CBOR major type %d for %v", head.major, t)
+}
+
+func eofAsUnexpected(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
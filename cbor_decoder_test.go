@@ -0,0 +1,175 @@
+package gblob_test
+
+import (
+	"bytes"
+	"io"
+	"math"
+	"math/big"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+	"github.com/mokiat/gog"
+)
+
+var _ = Describe("CBORDecoder", func() {
+	var (
+		buffer  *bytes.Buffer
+		decoder *gblob.CBORDecoder
+	)
+
+	seq := func(values ...uint8) []uint8 {
+		return values
+	}
+
+	BeforeEach(func() {
+		buffer = new(bytes.Buffer)
+		decoder = gblob.NewCBORDecoder(buffer)
+	})
+
+	// See RFC 8949, Appendix A.
+	DescribeTable("types",
+		func(data []byte, target any, expected any) {
+			buffer.Write(data)
+			Expect(decoder.Decode(target)).To(Succeed())
+			Expect(target).To(Equal(expected))
+		},
+		Entry("uint8", seq(0x18, 0x18), gog.PtrOf(uint8(0)), gog.PtrOf(uint8(24))),
+		Entry("uint64", seq(0x1B, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF), gog.PtrOf(uint64(0)), gog.PtrOf(uint64(math.MaxUint64))),
+		Entry("int16", seq(0x39, 0x03, 0xE7), gog.PtrOf(int16(0)), gog.PtrOf(int16(-1000))),
+		Entry("half to float32", seq(0xF9, 0x3E, 0x00), gog.PtrOf(float32(0)), gog.PtrOf(float32(1.5))),
+		Entry("half subnormal", seq(0xF9, 0x00, 0x01), gog.PtrOf(0.0), gog.PtrOf(5.960464477539063e-8)),
+		Entry("half negative", seq(0xF9, 0xC4, 0x00), gog.PtrOf(0.0), gog.PtrOf(-4.0)),
+		Entry("half infinity", seq(0xF9, 0xFC, 0x00), gog.PtrOf(0.0), gog.PtrOf(math.Inf(-1))),
+		Entry("float32", seq(0xFA, 0x47, 0xC3, 0x50, 0x00), gog.PtrOf(0.0), gog.PtrOf(100000.0)),
+		Entry("float64", seq(0xFB, 0x3F, 0xF1, 0x99, 0x99, 0x99, 0x99, 0x99, 0x9A), gog.PtrOf(0.0), gog.PtrOf(1.1)),
+		Entry("int to float", seq(0x20), gog.PtrOf(0.0), gog.PtrOf(-1.0)),
+		Entry("bool", seq(0xF5), gog.PtrOf(false), gog.PtrOf(true)),
+		Entry("null", seq(0xF6), gog.PtrOf(gog.PtrOf(uint8(1))), gog.PtrOf((*uint8)(nil))),
+		Entry("undefined", seq(0xF7), gog.PtrOf(uint8(1)), gog.PtrOf(uint8(0))),
+		Entry("text", seq(0x64, 0x49, 0x45, 0x54, 0x46), gog.PtrOf(""), gog.PtrOf("IETF")),
+		Entry("indefinite text", seq(0x7F, 0x65, 's', 't', 'r', 'e', 'a', 0x64, 'm', 'i', 'n', 'g', 0xFF), gog.PtrOf(""), gog.PtrOf("streaming")),
+		Entry("bytes", seq(0x44, 0x01, 0x02, 0x03, 0x04), gog.PtrOf([]byte(nil)), gog.PtrOf([]byte{0x01, 0x02, 0x03, 0x04})),
+		Entry("indefinite bytes", seq(0x5F, 0x42, 0x01, 0x02, 0x43, 0x03, 0x04, 0x05, 0xFF), gog.PtrOf([]byte(nil)), gog.PtrOf([]byte{0x01, 0x02, 0x03, 0x04, 0x05})),
+		Entry("array", seq(0x83, 0x01, 0x02, 0x03), gog.PtrOf([]uint16(nil)), gog.PtrOf([]uint16{1, 2, 3})),
+		Entry("indefinite array", seq(0x9F, 0x81, 0x01, 0x82, 0x02, 0x03, 0xFF), gog.PtrOf([][]int(nil)), gog.PtrOf([][]int{{1}, {2, 3}})),
+		Entry("fixed array", seq(0x83, 0x01, 0x02, 0x03), gog.PtrOf([2]uint8{}), gog.PtrOf([2]uint8{1, 2})),
+		Entry("map", seq(0xA2, 0x01, 0x02, 0x03, 0x04), gog.PtrOf(map[int]int(nil)), gog.PtrOf(map[int]int{1: 2, 3: 4})),
+		Entry("indefinite map", seq(0xBF, 0x63, 'F', 'u', 'n', 0xF5, 0x63, 'A', 'm', 't', 0x21, 0xFF), gog.PtrOf(map[string]any(nil)), gog.PtrOf(map[string]any{"Fun": true, "Amt": int64(-2)})),
+		Entry("ignored tag", seq(0xD8, 0x20, 0x61, 'a'), gog.PtrOf(""), gog.PtrOf("a")),
+		Entry("date/time",
+			seq(0xC0, 0x74, '2', '0', '1', '3', '-', '0', '3', '-', '2', '1', 'T', '2', '0', ':', '0', '4', ':', '0', '0', 'Z'),
+			gog.PtrOf(time.Time{}),
+			gog.PtrOf(time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC)),
+		),
+		Entry("epoch time",
+			seq(0xC1, 0x1A, 0x51, 0x4B, 0x67, 0xB0),
+			gog.PtrOf(time.Time{}),
+			gog.PtrOf(time.Date(2013, 3, 21, 20, 4, 0, 0, time.UTC)),
+		),
+		Entry("epoch time with fraction",
+			seq(0xC1, 0xFB, 0x41, 0xD4, 0x52, 0xD9, 0xEC, 0x20, 0x00, 0x00),
+			gog.PtrOf(time.Time{}),
+			gog.PtrOf(time.Date(2013, 3, 21, 20, 4, 0, 500000000, time.UTC)),
+		),
+		Entry("positive bignum",
+			seq(0xC2, 0x49, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00),
+			new(big.Int),
+			new(big.Int).Lsh(big.NewInt(1), 64),
+		),
+		Entry("negative bignum",
+			seq(0xC3, 0x49, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00),
+			new(big.Int),
+			new(big.Int).Sub(big.NewInt(-1), new(big.Int).Lsh(big.NewInt(1), 64)),
+		),
+		Entry("integer to bignum", seq(0x38, 0x63), new(big.Int), big.NewInt(-100)),
+		Entry("struct",
+			seq(0xA3, 0x61, 'x', 0x20, 0x61, 'Y', 0xF9, 0x38, 0x00, 0x61, 'Z', 0x80),
+			&cborPoint{},
+			&cborPoint{X: -1, Y: 0.5},
+		),
+		Entry("tagged struct",
+			seq(0xA3, 0x01, 0x07, 0x09, 0x61, 'x', 0x03, 0x61, 'n'),
+			&cborTagged{},
+			&cborTagged{ID: 7, Name: "n"},
+		),
+		Entry("custom",
+			seq(0x42, 0x12, 0x34),
+			&msgpackCustom{},
+			&msgpackCustom{Value: 0x1234},
+		),
+		Entry("lazy",
+			seq(0x19, 0x12, 0x34),
+			&gblob.Lazy[uint16]{},
+			gog.PtrOf(gblob.NewLazy(uint16(0x1234))),
+		),
+		Entry("lazy slice",
+			seq(0x82, 0x01, 0x02),
+			&gblob.LazySlice[uint16]{},
+			gog.PtrOf(gblob.NewLazySlice([]uint16{1, 2})),
+		),
+		Entry("any",
+			seq(0xA3,
+				0x61, 'a', 0x82, 0x20, 0xC2, 0x41, 0x01,
+				0x41, 'b', 0xD8, 0x20, 0xF9, 0x3C, 0x00,
+				0x01, 0xF6,
+			),
+			gog.PtrOf(any(nil)),
+			gog.PtrOf(any(map[any]any{
+				"a":       []any{int64(-1), big.NewInt(1)},
+				"b":       gblob.CBORTag{Number: 32, Content: 1.0},
+				uint64(1): nil,
+			})),
+		),
+	)
+
+	DescribeTable("errors",
+		func(data []byte, target any, expected error) {
+			buffer.Write(data)
+			err := decoder.Decode(target)
+			Expect(err).To(HaveOccurred())
+			if expected != nil {
+				Expect(err).To(MatchError(expected))
+			}
+		},
+		Entry("overflow", seq(0x19, 0x01, 0x00), gog.PtrOf(uint8(0)), nil),
+		Entry("negative to unsigned", seq(0x20), gog.PtrOf(uint32(0)), nil),
+		Entry("text to bool", seq(0x61, 'a'), gog.PtrOf(false), nil),
+		Entry("reserved", seq(0x1C), gog.PtrOf(any(nil)), nil),
+		Entry("invalid indefinite", seq(0x1F), gog.PtrOf(any(nil)), nil),
+		Entry("invalid chunk", seq(0x5F, 0x61, 'a', 0xFF), gog.PtrOf([]byte(nil)), nil),
+		Entry("truncated head", seq(0x19, 0x01), gog.PtrOf(uint16(0)), io.ErrUnexpectedEOF),
+		Entry("truncated array", seq(0x82, 0x01), gog.PtrOf([]uint8(nil)), io.ErrUnexpectedEOF),
+		Entry("empty", seq(), gog.PtrOf(uint8(0)), io.EOF),
+		Entry("huge string", seq(0x5A, 0x7F, 0xFF, 0xFF, 0xFF, 'a'), gog.PtrOf([]byte(nil)), io.ErrUnexpectedEOF),
+		Entry("deeply nested array", bytes.Repeat(seq(0x81), 100000), gog.PtrOf(any(nil)), nil),
+		Entry("deeply nested map", bytes.Repeat(seq(0xA1, 0x00), 100000), gog.PtrOf(any(nil)), nil),
+		Entry("deeply nested tag", bytes.Repeat(seq(0xD8, 0x20), 100000), gog.PtrOf(""), nil),
+	)
+
+	Specify("nesting within the limit", func() {
+		buffer.Write(bytes.Repeat(seq(0x81), 999))
+		buffer.Write(seq(0x01))
+		var target any
+		Expect(decoder.Decode(&target)).To(Succeed())
+	})
+
+	Specify("round trip", func() {
+		source := map[string]cborTagged{
+			"first": {
+				ID:    0xABCD,
+				Point: &cborPoint{X: -300, Y: 2.25},
+				Name:  "round trip",
+			},
+		}
+		encoder := gblob.NewCBOREncoder(buffer)
+		encoder.SetCanonical(true)
+		Expect(encoder.Encode(source)).To(Succeed())
+
+		var target map[string]cborTagged
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(source))
+	})
+})
@@ -0,0 +1,434 @@
+package gblob
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/big"
+	"reflect"
+	"slices"
+	"time"
+)
+
+const (
+	cborUint     = 0
+	cborNegative = 1
+	cborBytes    = 2
+	cborText     = 3
+	cborArray    = 4
+	cborMap      = 5
+	cborTag      = 6
+	cborSimple   = 7
+)
+
+const (
+	cborFalse     = 20
+	cborTrue      = 21
+	cborNull      = 22
+	cborUndefined = 23
+	cborFloat16   = 25
+	cborFloat32   = 26
+	cborFloat64   = 27
+	cborBreak     = 31
+)
+
+const (
+	cborTagDateTime    = 0
+	cborTagEpochTime   = 1
+	cborTagPositiveBig = 2
+	cborTagNegativeBig = 3
+)
+
+var (
+	timeType   = reflect.TypeFor[time.Time]()
+	bigIntType = reflect.TypeFor[big.Int]()
+)
+
+// NewCBOREncoder creates a new CBOREncoder that writes to the specified out
+// Writer.
+func NewCBOREncoder(out io.Writer) *CBOREncoder {
+	return &CBOREncoder{
+		out: NewBigEndianWriter(out),
+	}
+}
+
+// CBOREncoder encodes arbitrary Go objects in CBOR form, as specified by
+// RFC 8949.
+//
+// Structs are encoded as maps from field name to value, where only exported
+// fields are included. Structs that implement PackedTagged are encoded as maps
+// from field number to value instead. Nil pointers are encoded as null.
+// Types that implement PackedEncodable are encoded as byte strings that hold
+// their Big Endian packed form. Lazy values are encoded inline and need to be
+// loaded.
+//
+// A time.Time is encoded with tag 1 (epoch-based date/time) and a big.Int
+// that does not fit into a CBOR integer is encoded with tag 2 or 3 (bignum).
+type CBOREncoder struct {
+	out       TypedWriter
+	canonical bool
+}
+
+// SetCanonical configures whether the output follows the core deterministic
+// encoding requirements of RFC 8949. In this mode, map keys are sorted by
+// their encoded form, floating-point values use the shortest form that
+// preserves their value and indefinite-length items are not allowed.
+func (e *CBOREncoder) SetCanonical(canonical bool) {
+	e.canonical = canonical
+}
+
+// Encode encodes the specified source value into the Writer.
+func (e *CBOREncoder) Encode(source any) error {
+	if source == nil {
+		return e.writeHead(cborSimple, cborNull)
+	}
+	return e.encodeValue(reflect.ValueOf(source))
+}
+
+// StartArray starts an indefinite-length array. Each subsequent call to
+// Encode adds an element to the array, until End is called.
+func (e *CBOREncoder) StartArray() error {
+	return e.startIndefinite(cborArray)
+}
+
+// StartMap starts an indefinite-length map. Subsequent calls to Encode
+// alternate between keys and values, until End is called.
+func (e *CBOREncoder) StartMap() error {
+	return e.startIndefinite(cborMap)
+}
+
+// End ends the innermost indefinite-length item.
+func (e *CBOREncoder) End() error {
+	return e.out.WriteUint8(cborSimple<<5 | cborBreak)
+}
+
+func (e *CBOREncoder) startIndefinite(major uint8) error {
+	if e.canonical {
+		return errors.New("indefinite-length items are not allowed in canonical mode")
+	}
+	return e.out.WriteUint8(major<<5 | cborBreak)
+}
+
+func (e *CBOREncoder) encodeValue(value reflect.Value) error {
+	if value.Kind() == reflect.Pointer && value.IsNil() {
+		return e.writeHead(cborSimple, cborNull)
+	}
+	if encodeHookOf(value.Type()) == hookLazy {
+		loaded, err := value.Interface().(lazyEncodable).loadedValue()
+		if err != nil {
+			return err
+		}
+		return e.Encode(loaded)
+	}
+	if value.Type().Implements(encodableType) {
+		return e.encodeEncodable(value.Interface().(PackedEncodable))
+	}
+	switch value.Type() {
+	case timeType:
+		return e.encodeTime(value.Interface().(time.Time))
+	case bigIntType:
+		number := value.Interface().(big.Int)
+		return e.encodeBigInt(&number)
+	}
+	switch kind := value.Kind(); kind {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return e.writeHead(cborSimple, cborNull)
+		}
+		return e.encodeValue(value.Elem())
+	case reflect.Bool:
+		if value.Bool() {
+			return e.writeHead(cborSimple, cborTrue)
+		}
+		return e.writeHead(cborSimple, cborFalse)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return e.writeHead(cborUint, value.Uint())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return e.encodeInt(value.Int())
+	case reflect.Float32, reflect.Float64:
+		return e.encodeFloat(value.Float(), kind == reflect.Float32)
+	case reflect.String:
+		if err := e.writeHead(cborText, uint64(value.Len())); err != nil {
+			return err
+		}
+		return e.out.WriteBytes([]byte(value.String()))
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Uint8 { // fast track
+			if err := e.writeHead(cborBytes, uint64(value.Len())); err != nil {
+				return err
+			}
+			return e.out.WriteBytes(value.Bytes())
+		}
+		fallthrough
+	case reflect.Array:
+		count := value.Len()
+		if err := e.writeHead(cborArray, uint64(count)); err != nil {
+			return err
+		}
+		for i := 0; i < count; i++ {
+			if err := e.encodeValue(value.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Map:
+		entries := make([]cborEntry, 0, value.Len())
+		iterator := value.MapRange()
+		for iterator.Next() {
+			entries = append(entries, cborEntry{
+				key:   iterator.Key(),
+				value: iterator.Value(),
+			})
+		}
+		return e.encodeEntries(entries)
+	case reflect.Struct:
+		if isTagged(value.Type()) {
+			return e.encodeTaggedStruct(value)
+		}
+		structType := value.Type()
+		entries := make([]cborEntry, 0, structType.NumField())
+		for i := range structType.NumField() {
+			field := structType.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			entries = append(entries, cborEntry{
+				key:   reflect.ValueOf(field.Name),
+				value: value.Field(i),
+			})
+		}
+		return e.encodeEntries(entries)
+	default:
+		return fmt.Errorf("unsupported type: %v", kind)
+	}
+}
+
+func (e *CBOREncoder) encodeTaggedStruct(value reflect.Value) error {
+	fields, err := taggedFieldsOf(value.Type())
+	if err != nil {
+		return err
+	}
+	entries := make([]cborEntry, 0, len(fields))
+	for _, field := range fields {
+		fieldValue := value.Field(field.index)
+		if fieldValue.Kind() == reflect.Pointer && fieldValue.IsNil() {
+			continue
+		}
+		entries = append(entries, cborEntry{
+			key:   reflect.ValueOf(uint64(field.number)),
+			value: fieldValue,
+		})
+	}
+	return e.encodeEntries(entries)
+}
+
+type cborEntry struct {
+	key     reflect.Value
+	value   reflect.Value
+	encoded []byte
+}
+
+func (e *CBOREncoder) encodeEntries(entries []cborEntry) error {
+	if err := e.writeHead(cborMap, uint64(len(entries))); err != nil {
+		return err
+	}
+	if !e.canonical {
+		for _, entry := range entries {
+			if err := e.encodeValue(entry.key); err != nil {
+				return err
+			}
+			if err := e.encodeValue(entry.value); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	for i := range entries {
+		var buffer bytes.Buffer
+		nested := &CBOREncoder{
+			out:       NewBigEndianWriter(&buffer),
+			canonical: true,
+		}
+		if err := nested.encodeValue(entries[i].key); err != nil {
+			return err
+		}
+		entries[i].encoded = buffer.Bytes()
+	}
+	slices.SortFunc(entries, func(a, b cborEntry) int {
+		return bytes.Compare(a.encoded, b.encoded)
+	})
+	for i, entry := range entries {
+		if i > 0 && bytes.Equal(entry.encoded, entries[i-1].encoded) {
+			return fmt.Errorf("duplicate map key: %v", entry.key)
+		}
+		if err := e.out.WriteBytes(entry.encoded); err != nil {
+			return err
+		}
+		if err := e.encodeValue(entry.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *CBOREncoder) encodeInt(value int64) error {
+	if value < 0 {
+		return e.writeHead(cborNegative, uint64(-1-value))
+	}
+	return e.writeHead(cborUint, uint64(value))
+}
+
+func (e *CBOREncoder) encodeFloat(value float64, single bool) error {
+	if e.canonical {
+		if bits, ok := float16Of(value); ok {
+			if err := e.out.WriteUint8(cborSimple<<5 | cborFloat16); err != nil {
+				return err
+			}
+			return e.out.WriteUint16(bits)
+		}
+		single = float64(float32(value)) == value
+	}
+	if single {
+		if err := e.out.WriteUint8(cborSimple<<5 | cborFloat32); err != nil {
+			return err
+		}
+		return e.out.WriteFloat32(float32(value))
+	}
+	if err := e.out.WriteUint8(cborSimple<<5 | cborFloat64); err != nil {
+		return err
+	}
+	return e.out.WriteFloat64(value)
+}
+
+func (e *CBOREncoder) encodeTime(value time.Time) error {
+	if err := e.writeHead(cborTag, cborTagEpochTime); err != nil {
+		return err
+	}
+	if value.Nanosecond() == 0 {
+		return e.encodeInt(value.Unix())
+	}
+	return e.encodeFloat(float64(value.UnixNano())/1e9, false)
+}
+
+func (e *CBOREncoder) encodeBigInt(value *big.Int) error {
+	if value.IsUint64() {
+		return e.writeHead(cborUint, value.Uint64())
+	}
+	tag := uint64(cborTagPositiveBig)
+	if value.Sign() < 0 {
+		// Negative values are stored as -1 - n.
+		value = new(big.Int).Sub(big.NewInt(-1), value)
+		if value.IsUint64() {
+			return e.writeHead(cborNegative, value.Uint64())
+		}
+		tag = cborTagNegativeBig
+	}
+	if err := e.writeHead(cborTag, tag); err != nil {
+		return err
+	}
+	data := value.Bytes()
+	if err := e.writeHead(cborBytes, uint64(len(data))); err != nil {
+		return err
+	}
+	return e.out.WriteBytes(data)
+}
+
+func (e *CBOREncoder) encodeEncodable(encodable PackedEncodable) error {
+	var buffer bytes.Buffer
+	if err := encodable.EncodePacked(NewBigEndianWriter(&buffer)); err != nil {
+		return err
+	}
+	if err := e.writeHead(cborBytes, uint64(buffer.Len())); err != nil {
+		return err
+	}
+	return e.out.WriteBytes(buffer.Bytes())
+}
+
+func (e *CBOREncoder) writeHead(major uint8, argument uint64) error {
+	switch {
+	case argument < 24:
+		return e.out.WriteUint8(major<<5 | uint8(argument))
+	case argument <= math.MaxUint8:
+		if err := e.out.WriteUint8(major<<5 | 24); err != nil {
+			return err
+		}
+		return e.out.WriteUint8(uint8(argument))
+	case argument <= math.MaxUint16:
+		if err := e.out.WriteUint8(major<<5 | 25); err != nil {
+			return err
+		}
+		return e.out.WriteUint16(uint16(argument))
+	case argument <= math.MaxUint32:
+		if err := e.out.WriteUint8(major<<5 | 26); err != nil {
+			return err
+		}
+		return e.out.WriteUint32(uint32(argument))
+	default:
+		if err := e.out.WriteUint8(major<<5 | 27); err != nil {
+			return err
+		}
+		return e.out.WriteUint64(argument)
+	}
+}
+
+// float16Of returns the IEEE 754 half-precision representation of the
+// specified value and true, or false if the value cannot be represented
+// exactly.
+func float16Of(value float64) (uint16, bool) {
+	var sign uint16
+	if math.Signbit(value) {
+		sign = 0x8000
+	}
+	switch {
+	case math.IsNaN(value):
+		return 0x7E00, true
+	case math.IsInf(value, 0):
+		return sign | 0x7C00, true
+	case value == 0:
+		return sign, true
+	}
+	fraction, exponent := math.Frexp(math.Abs(value))
+	exponent-- // normalize fraction to [1, 2)
+	switch {
+	case exponent > 15:
+		return 0, false
+	case exponent >= -14:
+		mantissa := (fraction*2 - 1) * 1024
+		if mantissa != math.Trunc(mantissa) {
+			return 0, false
+		}
+		return sign | uint16(exponent+15)<<10 | uint16(mantissa), true
+	default:
+		mantissa := math.Ldexp(math.Abs(value), 24)
+		if mantissa != math.Trunc(mantissa) {
+			return 0, false
+		}
+		return sign | uint16(mantissa), true
+	}
+}
+
+// float16Value returns the value of the specified IEEE 754 half-precision
+// representation.
+func float16Value(bits uint16) float64 {
+	exponent := int(bits>>10) & 0x1F
+	mantissa := float64(bits & 0x03FF)
+	var value float64
+	switch exponent {
+	case 0:
+		value = math.Ldexp(mantissa, -24)
+	case 0x1F:
+		if mantissa == 0 {
+			value = math.Inf(1)
+		} else {
+			value = math.NaN()
+		}
+	default:
+		value = math.Ldexp(mantissa+1024, exponent-25)
+	}
+	if bits&0x8000 != 0 {
+		value = -value
+	}
+	return value
+}
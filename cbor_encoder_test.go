@@ -0,0 +1,142 @@
+package gblob_test
+
+import (
+	"bytes"
+	"math"
+	"math/big"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+	"github.com/mokiat/gog"
+)
+
+type cborPoint struct {
+	X      int16
+	Y      float32
+	hidden uint8
+}
+
+type cborTagged struct {
+	ID    uint16     `gblob:"1"`
+	Point *cborPoint `gblob:"2"`
+	Name  string     `gblob:"3"`
+}
+
+func (cborTagged) PackedTagged() {}
+
+var _ = Describe("CBOREncoder", func() {
+	var (
+		buffer  *bytes.Buffer
+		encoder *gblob.CBOREncoder
+	)
+
+	seq := func(values ...uint8) []uint8 {
+		return values
+	}
+
+	bigInt := func(value string) *big.Int {
+		result, ok := new(big.Int).SetString(value, 10)
+		Expect(ok).To(BeTrue())
+		return result
+	}
+
+	BeforeEach(func() {
+		buffer = new(bytes.Buffer)
+		encoder = gblob.NewCBOREncoder(buffer)
+	})
+
+	// See RFC 8949, Appendix A.
+	DescribeTable("types",
+		func(source any, expected []byte) {
+			Expect(encoder.Encode(source)).To(Succeed())
+			Expect(buffer.Bytes()).To(Equal(expected))
+		},
+		Entry("0", uint8(0), seq(0x00)),
+		Entry("23", uint16(23), seq(0x17)),
+		Entry("24", uint32(24), seq(0x18, 0x18)),
+		Entry("1000", int(1000), seq(0x19, 0x03, 0xE8)),
+		Entry("1000000", int64(1000000), seq(0x1A, 0x00, 0x0F, 0x42, 0x40)),
+		Entry("18446744073709551615", uint64(math.MaxUint64), seq(0x1B, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF)),
+		Entry("-1", int8(-1), seq(0x20)),
+		Entry("-100", int32(-100), seq(0x38, 0x63)),
+		Entry("-1000", int64(-1000), seq(0x39, 0x03, 0xE7)),
+		Entry("18446744073709551616", bigInt("18446744073709551616"), seq(0xC2, 0x49, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00)),
+		Entry("-18446744073709551616", bigInt("-18446744073709551616"), seq(0x3B, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF)),
+		Entry("-18446744073709551617", bigInt("-18446744073709551617"), seq(0xC3, 0x49, 0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00)),
+		Entry("float32", float32(100000.0), seq(0xFA, 0x47, 0xC3, 0x50, 0x00)),
+		Entry("float64", 1.1, seq(0xFB, 0x3F, 0xF1, 0x99, 0x99, 0x99, 0x99, 0x99, 0x9A)),
+		Entry("false", false, seq(0xF4)),
+		Entry("true", true, seq(0xF5)),
+		Entry("null", nil, seq(0xF6)),
+		Entry("nil pointer", (*uint8)(nil), seq(0xF6)),
+		Entry("time", time.Unix(1363896240, 0), seq(0xC1, 0x1A, 0x51, 0x4B, 0x67, 0xB0)),
+		Entry("time with fraction", time.Unix(1363896240, 500000000), seq(0xC1, 0xFB, 0x41, 0xD4, 0x52, 0xD9, 0xEC, 0x20, 0x00, 0x00)),
+		Entry("bytes", []byte{0x01, 0x02, 0x03, 0x04}, seq(0x44, 0x01, 0x02, 0x03, 0x04)),
+		Entry("text", "IETF", seq(0x64, 0x49, 0x45, 0x54, 0x46)),
+		Entry("unicode text", "ü", seq(0x62, 0xC3, 0xBC)),
+		Entry("array", []uint8{}, seq(0x40)),
+		Entry("nested array", []any{1, []int{2, 3}, [2]int{4, 5}}, seq(0x83, 0x01, 0x82, 0x02, 0x03, 0x82, 0x04, 0x05)),
+		Entry("map", map[string]string{"a": "A"}, seq(0xA1, 0x61, 0x61, 0x61, 0x41)),
+		Entry("pointer", gog.PtrOf(uint8(5)), seq(0x05)),
+		Entry("struct",
+			cborPoint{X: -1, Y: 0.5},
+			seq(0xA2, 0x61, 'X', 0x20, 0x61, 'Y', 0xFA, 0x3F, 0x00, 0x00, 0x00),
+		),
+		Entry("tagged struct",
+			cborTagged{ID: 7, Name: "n"},
+			seq(0xA2, 0x01, 0x07, 0x03, 0x61, 'n'),
+		),
+		Entry("custom",
+			msgpackCustom{Value: 0x1234},
+			seq(0x42, 0x12, 0x34),
+		),
+		Entry("lazy",
+			gblob.NewLazy(uint16(0x1234)),
+			seq(0x19, 0x12, 0x34),
+		),
+		Entry("lazy slice",
+			gblob.NewLazySlice([]uint16{1, 2}),
+			seq(0x82, 0x01, 0x02),
+		),
+	)
+
+	DescribeTable("canonical",
+		func(source any, expected []byte) {
+			encoder.SetCanonical(true)
+			Expect(encoder.Encode(source)).To(Succeed())
+			Expect(buffer.Bytes()).To(Equal(expected))
+		},
+		Entry("0.0", 0.0, seq(0xF9, 0x00, 0x00)),
+		Entry("-0.0", math.Copysign(0, -1), seq(0xF9, 0x80, 0x00)),
+		Entry("1.0", 1.0, seq(0xF9, 0x3C, 0x00)),
+		Entry("1.5", float32(1.5), seq(0xF9, 0x3E, 0x00)),
+		Entry("65504.0", 65504.0, seq(0xF9, 0x7B, 0xFF)),
+		Entry("5.960464477539063e-8", 5.960464477539063e-8, seq(0xF9, 0x00, 0x01)),
+		Entry("0.00006103515625", 0.00006103515625, seq(0xF9, 0x04, 0x00)),
+		Entry("-4.0", -4.0, seq(0xF9, 0xC4, 0x00)),
+		Entry("100000.0", 100000.0, seq(0xFA, 0x47, 0xC3, 0x50, 0x00)),
+		Entry("1.1", 1.1, seq(0xFB, 0x3F, 0xF1, 0x99, 0x99, 0x99, 0x99, 0x99, 0x9A)),
+		Entry("Infinity", math.Inf(1), seq(0xF9, 0x7C, 0x00)),
+		Entry("NaN", math.NaN(), seq(0xF9, 0x7E, 0x00)),
+		Entry("sorted keys",
+			map[any]uint8{"aa": 1, "b": 2, uint8(10): 3, int8(-1): 4, false: 5},
+			seq(0xA5, 0x0A, 0x03, 0x20, 0x04, 0x61, 'b', 0x02, 0x62, 'a', 'a', 0x01, 0xF4, 0x05),
+		),
+	)
+
+	Specify("indefinite-length items", func() {
+		Expect(encoder.StartMap()).To(Succeed())
+		Expect(encoder.Encode("a")).To(Succeed())
+		Expect(encoder.StartArray()).To(Succeed())
+		Expect(encoder.Encode(1)).To(Succeed())
+		Expect(encoder.End()).To(Succeed())
+		Expect(encoder.End()).To(Succeed())
+		Expect(buffer.Bytes()).To(Equal(seq(0xBF, 0x61, 'a', 0x9F, 0x01, 0xFF, 0xFF)))
+
+		encoder.SetCanonical(true)
+		Expect(encoder.StartArray()).ToNot(Succeed())
+	})
+})
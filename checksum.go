@@ -0,0 +1,288 @@
+package gblob
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"hash/crc64"
+	"io"
+	"math"
+)
+
+// ErrChecksumMismatch indicates that the checksum of the data does not match
+// the checksum that was stored alongside it.
+var ErrChecksumMismatch = errors.New("checksum mismatch")
+
+var (
+	crc32cTable = crc32.MakeTable(crc32.Castagnoli)
+	crc64Table  = crc64.MakeTable(crc64.ECMA)
+)
+
+// ChecksumAlgorithm specifies the algorithm that is used to verify the
+// integrity of data.
+//
+// Checksums are always stored in Big Endian order, regardless of the byte
+// order of the data that they protect.
+type ChecksumAlgorithm uint8
+
+const (
+	// ChecksumNone disables checksums.
+	ChecksumNone ChecksumAlgorithm = iota
+
+	// ChecksumCRC32 uses a 32 bit CRC with the IEEE polynomial.
+	ChecksumCRC32
+
+	// ChecksumCRC32C uses a 32 bit CRC with the Castagnoli polynomial.
+	ChecksumCRC32C
+
+	// ChecksumCRC64 uses a 64 bit CRC with the ECMA polynomial.
+	ChecksumCRC64
+)
+
+// Size returns the number of bytes that a checksum of this algorithm
+// occupies.
+func (a ChecksumAlgorithm) Size() int {
+	switch a {
+	case ChecksumCRC32, ChecksumCRC32C:
+		return crc32.Size
+	case ChecksumCRC64:
+		return crc64.Size
+	default:
+		return 0
+	}
+}
+
+func (a ChecksumAlgorithm) validate() error {
+	if a > ChecksumCRC64 {
+		return fmt.Errorf("unsupported checksum algorithm: %d", a)
+	}
+	return nil
+}
+
+// newHash returns a hash of the algorithm or nil for ChecksumNone. The
+// algorithm needs to have been validated.
+func (a ChecksumAlgorithm) newHash() hash.Hash {
+	switch a {
+	case ChecksumCRC32:
+		return crc32.NewIEEE()
+	case ChecksumCRC32C:
+		return crc32.New(crc32cTable)
+	case ChecksumCRC64:
+		return crc64.New(crc64Table)
+	default:
+		return nil
+	}
+}
+
+// NewChecksumWriter returns a new ChecksumWriter that writes to the specified
+// out Writer and computes a checksum using the specified algorithm.
+func NewChecksumWriter(out io.Writer, algorithm ChecksumAlgorithm) (*ChecksumWriter, error) {
+	if err := algorithm.validate(); err != nil {
+		return nil, err
+	}
+	return &ChecksumWriter{
+		out:  out,
+		hash: algorithm.newHash(),
+	}, nil
+}
+
+// ChecksumWriter is an io.Writer that passes all data to an underlying
+// writer and appends a checksum of that data once closed.
+//
+// It can be used below a TypedWriter or PackedEncoder to protect a whole
+// file.
+type ChecksumWriter struct {
+	out  io.Writer
+	hash hash.Hash
+}
+
+// Write writes the specified data to the underlying writer.
+func (w *ChecksumWriter) Write(data []byte) (int, error) {
+	n, err := w.out.Write(data)
+	if w.hash != nil {
+		w.hash.Write(data[:n])
+	}
+	return n, err
+}
+
+// Close writes the checksum of all data written so far. It does not close
+// the underlying writer.
+func (w *ChecksumWriter) Close() error {
+	if w.hash == nil {
+		return nil
+	}
+	_, err := w.out.Write(w.hash.Sum(nil))
+	return err
+}
+
+// NewChecksumReader returns a new ChecksumReader that reads from the
+// specified in Reader and verifies its checksum using the specified
+// algorithm.
+func NewChecksumReader(in io.Reader, algorithm ChecksumAlgorithm) (*ChecksumReader, error) {
+	if err := algorithm.validate(); err != nil {
+		return nil, err
+	}
+	return &ChecksumReader{
+		in:        in,
+		algorithm: algorithm,
+	}, nil
+}
+
+// ChecksumReader is an io.Reader that reads data that was written through
+// a ChecksumWriter.
+//
+// The whole input is read and verified on the first call to Read, so that no
+// data is returned unless it matches its checksum. If verification fails,
+// ErrChecksumMismatch is returned instead.
+type ChecksumReader struct {
+	in        io.Reader
+	algorithm ChecksumAlgorithm
+	data      bytes.Reader
+	verified  bool
+	err       error
+}
+
+// Read reads verified data into the specified target.
+func (r *ChecksumReader) Read(target []byte) (int, error) {
+	if !r.verified {
+		r.err = r.verify()
+		r.verified = true
+	}
+	if r.err != nil {
+		return 0, r.err
+	}
+	return r.data.Read(target)
+}
+
+func (r *ChecksumReader) verify() error {
+	data, err := io.ReadAll(r.in)
+	if err != nil {
+		return err
+	}
+	size := r.algorithm.Size()
+	if len(data) < size {
+		return io.ErrUnexpectedEOF
+	}
+	payload, sum := data[:len(data)-size], data[len(data)-size:]
+	if hash := r.algorithm.newHash(); hash != nil {
+		hash.Write(payload)
+		if !bytes.Equal(hash.Sum(nil), sum) {
+			return ErrChecksumMismatch
+		}
+	}
+	r.data.Reset(payload)
+	return nil
+}
+
+// hashSink is an io.Writer that feeds a replaceable hash.
+type hashSink struct {
+	hash hash.Hash
+}
+
+func (s *hashSink) Write(data []byte) (int, error) {
+	return s.hash.Write(data)
+}
+
+// encodeChecksummed encodes the specified source value into a buffer and
+// writes it with its length and checksum.
+func (e *PackedEncoder) encodeChecksummed(source any) error {
+	var buffer bytes.Buffer
+	writer, err := newOrderedWriter(&buffer, e.order)
+	if err != nil {
+		return err
+	}
+	encoder := *e
+	encoder.out = writer
+	encoder.checksum = ChecksumNone
+	if err := encoder.Encode(source); err != nil {
+		return err
+	}
+	e.steps = encoder.steps
+	sum, err := checksumOf(e.checksum, e.order, buffer.Bytes())
+	if err != nil {
+		return err
+	}
+	if err := e.out.WriteUint64(uint64(buffer.Len())); err != nil {
+		return err
+	}
+	if err := e.out.WriteBytes(buffer.Bytes()); err != nil {
+		return err
+	}
+	return e.out.WriteBytes(sum)
+}
+
+// decodeChecksummed reads a value that was written by encodeChecksummed,
+// verifies it and only then decodes it into the specified target.
+func (d *PackedDecoder) decodeChecksummed(target any) error {
+	length, err := d.in.ReadUint64()
+	if err != nil {
+		return err
+	}
+	if length > math.MaxInt {
+		return fmt.Errorf("checksummed value length %d exceeds maximum: %w", length, io.ErrUnexpectedEOF)
+	}
+	base, err := d.position()
+	if err != nil {
+		// Values that need the position, such as lazy values, fail to
+		// decode instead of recording a wrong one.
+		base = -1
+	}
+	data, err := d.readChecksummed(int(length))
+	if err != nil {
+		return err
+	}
+	expected := make([]byte, d.checksum.Size())
+	if err := d.in.ReadBytes(expected); err != nil {
+		return eofAsUnexpected(err)
+	}
+	sum, err := checksumOf(d.checksum, d.order, data)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(sum, expected) {
+		return ErrChecksumMismatch
+	}
+	reader, err := newOrderedBufferReader(data, d.order)
+	if err != nil {
+		return err
+	}
+	decoder := *d
+	decoder.in = offsetReader{
+		TypedReader: reader,
+		base:        base,
+	}
+	decoder.checksum = ChecksumNone
+	err = decoder.Decode(target)
+	d.scratch, d.steps = decoder.scratch, decoder.steps
+	return err
+}
+
+// readChecksummed reads the specified number of bytes. When reading from a
+// stream, the result is allocated as data arrives, so that it is never sized
+// from the untrusted length alone.
+func (d *PackedDecoder) readChecksummed(length int) ([]byte, error) {
+	if reader, ok := d.in.(sliceReader); ok {
+		data, err := reader.readSlice(length)
+		return data, eofAsUnexpected(err)
+	}
+	return readChunked(d.in, length)
+}
+
+// checksumOf returns the checksum of the specified data, which also covers
+// its uint64 length in the specified byte order.
+func checksumOf(algorithm ChecksumAlgorithm, order ByteOrder, data []byte) ([]byte, error) {
+	hash := algorithm.newHash()
+	writer, err := newOrderedWriter(hash, order)
+	if err != nil {
+		return nil, err
+	}
+	if err := writer.WriteUint64(uint64(len(data))); err != nil {
+		return nil, err
+	}
+	if err := writer.WriteBytes(data); err != nil {
+		return nil, err
+	}
+	return hash.Sum(nil), nil
+}
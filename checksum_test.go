@@ -0,0 +1,208 @@
+package gblob_test
+
+import (
+	"bytes"
+	"io"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+)
+
+var _ = Describe("ChecksumWriter", func() {
+	var buffer *bytes.Buffer
+
+	BeforeEach(func() {
+		buffer = new(bytes.Buffer)
+	})
+
+	Specify("CRC32", func() {
+		writer, err := gblob.NewChecksumWriter(buffer, gblob.ChecksumCRC32)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = writer.Write([]byte("hello"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(writer.Close()).To(Succeed())
+		Expect(buffer.Bytes()).To(Equal([]uint8{
+			'h', 'e', 'l', 'l', 'o',
+			0x36, 0x10, 0xA6, 0x86, // checksum
+		}))
+	})
+
+	Specify("CRC32C", func() {
+		writer, err := gblob.NewChecksumWriter(buffer, gblob.ChecksumCRC32C)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = writer.Write([]byte("123456789"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(writer.Close()).To(Succeed())
+		Expect(buffer.Bytes()[9:]).To(Equal([]uint8{
+			0xE3, 0x06, 0x92, 0x83, // checksum
+		}))
+	})
+
+	Specify("CRC64", func() {
+		writer, err := gblob.NewChecksumWriter(buffer, gblob.ChecksumCRC64)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = writer.Write([]byte("123456789"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(writer.Close()).To(Succeed())
+		Expect(buffer.Len()).To(Equal(9 + 8))
+	})
+
+	Specify("unsupported algorithm", func() {
+		_, err := gblob.NewChecksumWriter(buffer, gblob.ChecksumAlgorithm(200))
+		Expect(err).To(MatchError(ContainSubstring("unsupported checksum algorithm")))
+	})
+})
+
+var _ = Describe("ChecksumReader", func() {
+	encode := func(algorithm gblob.ChecksumAlgorithm, value any) []byte {
+		var buffer bytes.Buffer
+		writer, err := gblob.NewChecksumWriter(&buffer, algorithm)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gblob.NewLittleEndianPackedEncoder(writer).Encode(value)).To(Succeed())
+		Expect(writer.Close()).To(Succeed())
+		return buffer.Bytes()
+	}
+
+	DescribeTable("round trip",
+		func(algorithm gblob.ChecksumAlgorithm) {
+			data := encode(algorithm, []string{"hello", "world"})
+
+			var target []string
+			reader, err := gblob.NewChecksumReader(bytes.NewReader(data), algorithm)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(gblob.NewLittleEndianPackedDecoder(reader).Decode(&target)).To(Succeed())
+			Expect(target).To(Equal([]string{"hello", "world"}))
+		},
+		Entry("CRC32", gblob.ChecksumCRC32),
+		Entry("CRC32C", gblob.ChecksumCRC32C),
+		Entry("CRC64", gblob.ChecksumCRC64),
+	)
+
+	Specify("corrupted data", func() {
+		data := encode(gblob.ChecksumCRC32C, []string{"hello", "world"})
+		data[10] ^= 0x01
+
+		var target []string
+		reader, err := gblob.NewChecksumReader(bytes.NewReader(data), gblob.ChecksumCRC32C)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gblob.NewLittleEndianPackedDecoder(reader).Decode(&target)).To(MatchError(gblob.ErrChecksumMismatch))
+		Expect(target).To(BeNil())
+	})
+
+	Specify("missing checksum", func() {
+		reader, err := gblob.NewChecksumReader(bytes.NewReader([]byte{0x01, 0x02}), gblob.ChecksumCRC64)
+		Expect(err).ToNot(HaveOccurred())
+		_, err = reader.Read(make([]byte, 1))
+		Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+	})
+
+	Specify("unsupported algorithm", func() {
+		_, err := gblob.NewChecksumReader(bytes.NewReader(nil), gblob.ChecksumAlgorithm(200))
+		Expect(err).To(MatchError(ContainSubstring("unsupported checksum algorithm")))
+	})
+})
+
+var _ = Describe("Checksummed PackedEncoder", func() {
+	type record struct {
+		Name   string
+		Values []uint32
+		Data   gblob.Lazy[string]
+	}
+
+	var (
+		buffer *bytes.Buffer
+		source record
+	)
+
+	BeforeEach(func() {
+		buffer = new(bytes.Buffer)
+		source = record{
+			Name:   "record",
+			Values: []uint32{1, 2, 3},
+			Data:   gblob.NewLazy("payload"),
+		}
+		encoder := gblob.NewLittleEndianPackedEncoder(buffer)
+		Expect(encoder.SetChecksum(gblob.ChecksumCRC32C)).To(Succeed())
+		Expect(encoder.Encode(source)).To(Succeed())
+		Expect(encoder.Encode(uint16(0xCAFE))).To(Succeed())
+	})
+
+	Specify("round trip", func() {
+		decoder := gblob.NewLittleEndianPackedDecoder(buffer)
+		Expect(decoder.SetChecksum(gblob.ChecksumCRC32C)).To(Succeed())
+
+		var target record
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target.Name).To(Equal(source.Name))
+		Expect(target.Values).To(Equal(source.Values))
+		data, err := target.Data.Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(data).To(Equal("payload"))
+
+		var trailer uint16
+		Expect(decoder.Decode(&trailer)).To(Succeed())
+		Expect(trailer).To(Equal(uint16(0xCAFE)))
+	})
+
+	Specify("unsupported algorithm", func() {
+		encoder := gblob.NewLittleEndianPackedEncoder(buffer)
+		Expect(encoder.SetChecksum(gblob.ChecksumAlgorithm(200))).ToNot(Succeed())
+		decoder := gblob.NewLittleEndianPackedDecoder(buffer)
+		Expect(decoder.SetChecksum(gblob.ChecksumAlgorithm(200))).ToNot(Succeed())
+	})
+
+	Specify("lazy offsets refer to the whole input", func() {
+		data := bytes.Clone(buffer.Bytes())
+		decoder := gblob.NewLittleEndianPackedDecoder(buffer)
+		Expect(decoder.SetChecksum(gblob.ChecksumCRC32C)).To(Succeed())
+
+		var target record
+		Expect(decoder.Decode(&target)).To(Succeed())
+		value, err := target.Data.LoadFrom(bytes.NewReader(data))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(value).To(Equal("payload"))
+	})
+
+	Specify("corrupted data", func() {
+		buffer.Bytes()[8+4] ^= 0x01
+
+		decoder := gblob.NewLittleEndianPackedDecoder(buffer)
+		Expect(decoder.SetChecksum(gblob.ChecksumCRC32C)).To(Succeed())
+
+		var target record
+		Expect(decoder.Decode(&target)).To(MatchError(gblob.ErrChecksumMismatch))
+		Expect(target).To(Equal(record{}))
+
+		By("continuing with the next value")
+		var trailer uint16
+		Expect(decoder.Decode(&trailer)).To(Succeed())
+		Expect(trailer).To(Equal(uint16(0xCAFE)))
+	})
+
+	DescribeTable("corrupted length",
+		func(top uint8) {
+			buffer.Bytes()[7] = top
+
+			decoder := gblob.NewLittleEndianPackedDecoder(buffer)
+			Expect(decoder.SetChecksum(gblob.ChecksumCRC32C)).To(Succeed())
+
+			var target record
+			Expect(decoder.Decode(&target)).To(MatchError(io.ErrUnexpectedEOF))
+		},
+		Entry("large", uint8(0x7F)),
+		Entry("beyond int", uint8(0xFF)),
+	)
+
+	Specify("corrupted length in a buffer", func() {
+		data := buffer.Bytes()
+		data[7] = 0x7F
+
+		decoder := gblob.NewLittleEndianBufferPackedDecoder(data)
+		Expect(decoder.SetChecksum(gblob.ChecksumCRC32C)).To(Succeed())
+
+		var target record
+		Expect(decoder.Decode(&target)).To(MatchError(io.ErrUnexpectedEOF))
+	})
+})
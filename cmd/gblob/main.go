@@ -0,0 +1,179 @@
+// Command gblob provides tooling for working with gblob packed data.
+//
+// Usage:
+//
+//	gblob compat [-big-endian] <stored> <current>
+//	gblob json [-big-endian] [-hex-bytes] <packed>
+//	gblob pack [-big-endian] [-hex-bytes] -schema <file> <json>
+//
+// The compat subcommand reads the Schema at the start of each of the two
+// files (as written by a self-describing PackedEncoder or by encoding a
+// Schema directly) and reports whether data written with the stored schema
+// can be decoded into the current one. It exits with status 1 if any
+// incompatibilities are found.
+//
+// The json subcommand renders a file that was written by a self-describing
+// PackedEncoder as JSON. The pack subcommand does the reverse, using the
+// Schema at the start of the specified schema file, and writes the
+// self-describing packed output to stdout.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/mokiat/gblob"
+)
+
+// errIncompatible indicates that the compared schemas are not compatible.
+var errIncompatible = errors.New("schemas are not compatible")
+
+func main() {
+	if err := run(os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		if errors.Is(err, errIncompatible) {
+			os.Exit(1)
+		}
+		os.Exit(2)
+	}
+}
+
+func run(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("missing subcommand; usage: gblob compat|json|pack [flags] <files>")
+	}
+	switch command := args[0]; command {
+	case "compat":
+		return runCompat(args[1:], out)
+	case "json":
+		return runJSON(args[1:], out)
+	case "pack":
+		return runPack(args[1:], out)
+	default:
+		return fmt.Errorf("unknown subcommand: %s", command)
+	}
+}
+
+func runCompat(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("compat", flag.ContinueOnError)
+	bigEndian := flags.Bool("big-endian", false, "read the schemas in Big Endian order")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return fmt.Errorf("expected two files; usage: gblob compat [-big-endian] <stored> <current>")
+	}
+	stored, err := readSchema(flags.Arg(0), *bigEndian)
+	if err != nil {
+		return err
+	}
+	current, err := readSchema(flags.Arg(1), *bigEndian)
+	if err != nil {
+		return err
+	}
+	incompatibilities := gblob.CheckCompatibility(stored, current)
+	if len(incompatibilities) == 0 {
+		fmt.Fprintln(out, "compatible")
+		return nil
+	}
+	for _, incompatibility := range incompatibilities {
+		fmt.Fprintln(out, incompatibility)
+	}
+	return errIncompatible
+}
+
+func runJSON(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("json", flag.ContinueOnError)
+	bigEndian := flags.Bool("big-endian", false, "read the packed data in Big Endian order")
+	hexBytes := flags.Bool("hex-bytes", false, "render byte data as hex instead of base64")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("expected one file; usage: gblob json [-big-endian] [-hex-bytes] <packed>")
+	}
+	schema, err := readSchema(flags.Arg(0), *bigEndian)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	var decoder *gblob.PackedDecoder
+	if *bigEndian {
+		decoder = gblob.NewBigEndianPackedDecoder(file)
+	} else {
+		decoder = gblob.NewLittleEndianPackedDecoder(file)
+	}
+	decoder.SetSelfDescribing(true)
+	tree, err := decoder.DecodeSchema(nil)
+	if err != nil {
+		return fmt.Errorf("error reading value from %s: %w", flags.Arg(0), err)
+	}
+	encoder := gblob.NewJSONEncoder(out)
+	encoder.SetHexBytes(*hexBytes)
+	return encoder.EncodeSchema(schema, tree)
+}
+
+func runPack(args []string, out io.Writer) error {
+	flags := flag.NewFlagSet("pack", flag.ContinueOnError)
+	bigEndian := flags.Bool("big-endian", false, "write the packed data in Big Endian order")
+	hexBytes := flags.Bool("hex-bytes", false, "expect byte data as hex instead of base64")
+	schemaPath := flags.String("schema", "", "file that starts with the schema of the value")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 || *schemaPath == "" {
+		return fmt.Errorf("expected schema and JSON files; usage: gblob pack [-big-endian] [-hex-bytes] -schema <file> <json>")
+	}
+	schema, err := readSchema(*schemaPath, *bigEndian)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	decoder := gblob.NewJSONDecoder(file)
+	decoder.SetHexBytes(*hexBytes)
+	tree, err := decoder.DecodeSchema(schema)
+	if err != nil {
+		return fmt.Errorf("error reading JSON from %s: %w", flags.Arg(0), err)
+	}
+	var encoder *gblob.PackedEncoder
+	if *bigEndian {
+		encoder = gblob.NewBigEndianPackedEncoder(out)
+	} else {
+		encoder = gblob.NewLittleEndianPackedEncoder(out)
+	}
+	encoder.SetSelfDescribing(true)
+	return encoder.EncodeSchema(schema, tree)
+}
+
+func readSchema(path string, bigEndian bool) (*gblob.Schema, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var reader gblob.TypedReader
+	if bigEndian {
+		reader = gblob.NewBigEndianReader(file)
+	} else {
+		reader = gblob.NewLittleEndianReader(file)
+	}
+	var schema gblob.Schema
+	if err := schema.DecodePacked(reader); err != nil {
+		return nil, fmt.Errorf("error reading schema from %s: %w", path, err)
+	}
+	return &schema, nil
+}
@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+)
+
+type customValue struct {
+	Value uint16
+}
+
+func (c customValue) EncodePacked(writer gblob.TypedWriter) error {
+	return writer.WriteUint16(c.Value)
+}
+
+func (c *customValue) DecodePacked(reader gblob.TypedReader) error {
+	var err error
+	c.Value, err = reader.ReadUint16()
+	return err
+}
+
+var _ = Describe("Command", func() {
+	type itemV1 struct {
+		Name  string
+		Count uint32
+	}
+
+	type itemV2 struct {
+		Name  string
+		Count uint32
+		Price float32
+	}
+
+	var (
+		dir string
+		out *bytes.Buffer
+	)
+
+	BeforeEach(func() {
+		dir = GinkgoT().TempDir()
+		out = new(bytes.Buffer)
+	})
+
+	writePacked := func(name string, value any) string {
+		var buffer bytes.Buffer
+		encoder := gblob.NewLittleEndianPackedEncoder(&buffer)
+		encoder.SetSelfDescribing(true)
+		Expect(encoder.Encode(value)).To(Succeed())
+		path := filepath.Join(dir, name)
+		Expect(os.WriteFile(path, buffer.Bytes(), 0o644)).To(Succeed())
+		return path
+	}
+
+	writeFile := func(name string, data []byte) string {
+		path := filepath.Join(dir, name)
+		Expect(os.WriteFile(path, data, 0o644)).To(Succeed())
+		return path
+	}
+
+	Describe("run", func() {
+		It("rejects missing and unknown subcommands", func() {
+			Expect(run(nil, out)).To(MatchError(ContainSubstring("missing subcommand")))
+			Expect(run([]string{"unknown"}, out)).To(MatchError(ContainSubstring("unknown subcommand")))
+		})
+	})
+
+	Describe("compat", func() {
+		It("reports compatible schemas", func() {
+			stored := writePacked("stored.bin", itemV1{Name: "apple", Count: 3})
+			current := writePacked("current.bin", itemV1{})
+			Expect(runCompat([]string{stored, current}, out)).To(Succeed())
+			Expect(out.String()).To(Equal("compatible\n"))
+		})
+
+		It("reports incompatible schemas", func() {
+			stored := writePacked("stored.bin", itemV1{})
+			current := writePacked("current.bin", itemV2{})
+			Expect(runCompat([]string{stored, current}, out)).To(MatchError(errIncompatible))
+			Expect(out.String()).To(ContainSubstring("added field"))
+		})
+
+		It("requires two files", func() {
+			stored := writePacked("stored.bin", itemV1{})
+			Expect(runCompat([]string{stored}, out)).To(MatchError(ContainSubstring("expected two files")))
+		})
+
+		It("reports missing files", func() {
+			stored := writePacked("stored.bin", itemV1{})
+			Expect(runCompat([]string{stored, filepath.Join(dir, "missing.bin")}, out)).ToNot(Succeed())
+		})
+	})
+
+	Describe("json", func() {
+		It("renders packed data", func() {
+			path := writePacked("item.bin", itemV1{Name: "apple", Count: 3})
+			Expect(runJSON([]string{path}, out)).To(Succeed())
+			Expect(out.String()).To(MatchJSON(`{"Name":"apple","Count":3}`))
+		})
+
+		It("requires one file", func() {
+			Expect(runJSON(nil, out)).To(MatchError(ContainSubstring("expected one file")))
+		})
+
+		It("reports truncated data", func() {
+			path := writePacked("item.bin", itemV1{Name: "apple", Count: 3})
+			data, err := os.ReadFile(path)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(os.WriteFile(path, data[:len(data)-2], 0o644)).To(Succeed())
+			Expect(runJSON([]string{path}, out)).To(MatchError(ContainSubstring("error reading value")))
+		})
+	})
+
+	Describe("json and pack", func() {
+		type record struct {
+			Name   string
+			Data   []byte
+			Custom customValue
+			Lookup map[string]int32
+		}
+
+		It("round trips through JSON", func() {
+			source := record{
+				Name:   "record",
+				Data:   []byte{0x01, 0x02, 0x03},
+				Custom: customValue{Value: 0x1234},
+				Lookup: map[string]int32{"a": -1},
+			}
+			packed := writePacked("record.bin", source)
+			Expect(runJSON([]string{packed}, out)).To(Succeed())
+			first := out.String()
+
+			input := writeFile("record.json", out.Bytes())
+			out.Reset()
+			Expect(runPack([]string{"-schema", packed, input}, out)).To(Succeed())
+			repacked := writeFile("repacked.bin", out.Bytes())
+
+			var target record
+			decoder := gblob.NewLittleEndianPackedDecoder(bytes.NewReader(out.Bytes()))
+			decoder.SetSelfDescribing(true)
+			Expect(decoder.Decode(&target)).To(Succeed())
+			Expect(target).To(Equal(source))
+
+			out.Reset()
+			Expect(runJSON([]string{repacked}, out)).To(Succeed())
+			Expect(out.String()).To(MatchJSON(first))
+		})
+	})
+
+	Describe("pack", func() {
+		It("packs JSON according to a schema", func() {
+			schema := writePacked("schema.bin", itemV1{})
+			input := writeFile("item.json", []byte(`{"Name":"pear","Count":7}`))
+			Expect(runPack([]string{"-schema", schema, input}, out)).To(Succeed())
+
+			var target itemV1
+			decoder := gblob.NewLittleEndianPackedDecoder(out)
+			decoder.SetSelfDescribing(true)
+			Expect(decoder.Decode(&target)).To(Succeed())
+			Expect(target).To(Equal(itemV1{Name: "pear", Count: 7}))
+		})
+
+		It("writes Big Endian output", func() {
+			var buffer bytes.Buffer
+			encoder := gblob.NewBigEndianPackedEncoder(&buffer)
+			encoder.SetSelfDescribing(true)
+			Expect(encoder.Encode(itemV1{})).To(Succeed())
+			schema := writeFile("schema.bin", buffer.Bytes())
+			input := writeFile("item.json", []byte(`{"Name":"pear","Count":7}`))
+			Expect(runPack([]string{"-big-endian", "-schema", schema, input}, out)).To(Succeed())
+
+			var target itemV1
+			decoder := gblob.NewBigEndianPackedDecoder(out)
+			decoder.SetSelfDescribing(true)
+			Expect(decoder.Decode(&target)).To(Succeed())
+			Expect(target).To(Equal(itemV1{Name: "pear", Count: 7}))
+		})
+
+		It("rejects JSON that does not match the schema", func() {
+			schema := writePacked("schema.bin", itemV1{})
+			input := writeFile("item.json", []byte(`{"Name":"pear","Weight":7}`))
+			Expect(runPack([]string{"-schema", schema, input}, out)).To(MatchError(ContainSubstring("error reading JSON")))
+		})
+
+		It("requires a schema", func() {
+			input := writeFile("item.json", []byte(`{}`))
+			Expect(runPack([]string{input}, out)).To(MatchError(ContainSubstring("expected schema and JSON files")))
+		})
+	})
+})
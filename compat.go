@@ -0,0 +1,212 @@
+package gblob
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// IncompatibilityReason specifies why a stored value cannot be decoded into
+// a current type.
+type IncompatibilityReason uint8
+
+const (
+	// ReasonKindChange indicates that the kind of the value has changed.
+	ReasonKindChange IncompatibilityReason = iota
+
+	// ReasonWidthChange indicates that a numeric value has changed its size.
+	ReasonWidthChange
+
+	// ReasonLengthChange indicates that an array has changed its length.
+	ReasonLengthChange
+
+	// ReasonReorder indicates that a struct field has changed its position.
+	ReasonReorder
+
+	// ReasonRemovedField indicates that a struct field has been removed.
+	ReasonRemovedField
+
+	// ReasonAddedField indicates that a struct field has been added.
+	ReasonAddedField
+)
+
+// String returns a string representation of the reason.
+func (r IncompatibilityReason) String() string {
+	switch r {
+	case ReasonKindChange:
+		return "kind change"
+	case ReasonWidthChange:
+		return "width change"
+	case ReasonLengthChange:
+		return "length change"
+	case ReasonReorder:
+		return "reorder"
+	case ReasonRemovedField:
+		return "removed field"
+	case ReasonAddedField:
+		return "added field"
+	default:
+		return fmt.Sprintf("IncompatibilityReason(%d)", uint8(r))
+	}
+}
+
+// Incompatibility describes a single difference between two schemas that
+// prevents packed data from being decoded.
+type Incompatibility struct {
+
+	// Path identifies the value within the schema (e.g. "Mesh.Vertices[]").
+	Path string
+
+	// Reason specifies the type of the incompatibility.
+	Reason IncompatibilityReason
+
+	// Details contains a human-readable description of the difference.
+	Details string
+}
+
+// String returns a string representation of the incompatibility.
+func (i Incompatibility) String() string {
+	path := i.Path
+	if path == "" {
+		path = "<root>"
+	}
+	return fmt.Sprintf("%s: %s (%s)", path, i.Reason, i.Details)
+}
+
+// CheckCompatibility checks whether packed data that was written with the
+// stored schema can be decoded by a PackedDecoder into a type with the
+// current schema. It returns all found incompatibilities, which is empty
+// when the data is binary-compatible.
+//
+// Struct fields are matched by position, as is the case with the default
+// packed encoding. A field that is renamed but keeps its position and kind
+// is considered compatible.
+func CheckCompatibility(stored, current *Schema) []Incompatibility {
+	var result []Incompatibility
+	checkCompatibility(stored, current, "", &result)
+	return result
+}
+
+// CheckTypeCompatibility is like CheckCompatibility but works with Go types.
+func CheckTypeCompatibility(stored, current reflect.Type) ([]Incompatibility, error) {
+	storedSchema, err := SchemaOf(stored)
+	if err != nil {
+		return nil, err
+	}
+	currentSchema, err := SchemaOf(current)
+	if err != nil {
+		return nil, err
+	}
+	return CheckCompatibility(storedSchema, currentSchema), nil
+}
+
+func checkCompatibility(stored, current *Schema, path string, result *[]Incompatibility) {
+	report := func(reason IncompatibilityReason, format string, args ...any) {
+		*result = append(*result, Incompatibility{
+			Path:    path,
+			Reason:  reason,
+			Details: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if stored.Kind != current.Kind {
+		if isWidthChange(stored.Kind, current.Kind) {
+			report(ReasonWidthChange, "%v changed to %v", stored.Kind, current.Kind)
+		} else {
+			report(ReasonKindChange, "%v changed to %v", stored.Kind, current.Kind)
+		}
+		return
+	}
+	switch stored.Kind {
+	case KindCustom:
+		if stored.Name != current.Name {
+			report(ReasonKindChange, "%s changed to %s", stored.Name, current.Name)
+		}
+	case KindArray:
+		if stored.Length != current.Length {
+			report(ReasonLengthChange, "length %d changed to %d", stored.Length, current.Length)
+		}
+		checkCompatibility(stored.Elem, current.Elem, path+"[]", result)
+	case KindSlice:
+		checkCompatibility(stored.Elem, current.Elem, path+"[]", result)
+	case KindMap:
+		checkCompatibility(stored.Key, current.Key, path+"[key]", result)
+		checkCompatibility(stored.Elem, current.Elem, path+"[]", result)
+	case KindStruct:
+		checkStructCompatibility(stored, current, path, result)
+	}
+}
+
+func checkStructCompatibility(stored, current *Schema, path string, result *[]Incompatibility) {
+	storedIndices := make(map[string]int, len(stored.Fields))
+	for i, field := range stored.Fields {
+		storedIndices[field.Name] = i
+	}
+	currentIndices := make(map[string]int, len(current.Fields))
+	for i, field := range current.Fields {
+		currentIndices[field.Name] = i
+	}
+	isRename := func(index int) bool {
+		if index >= len(stored.Fields) || index >= len(current.Fields) {
+			return false
+		}
+		_, storedMoved := currentIndices[stored.Fields[index].Name]
+		_, currentMoved := storedIndices[current.Fields[index].Name]
+		return !storedMoved && !currentMoved
+	}
+	for i, field := range stored.Fields {
+		fieldPath := joinPath(path, field.Name)
+		j, ok := currentIndices[field.Name]
+		switch {
+		case ok && i == j:
+			checkCompatibility(field.Schema, current.Fields[j].Schema, fieldPath, result)
+		case ok:
+			*result = append(*result, Incompatibility{
+				Path:    fieldPath,
+				Reason:  ReasonReorder,
+				Details: fmt.Sprintf("position %d changed to %d", i, j),
+			})
+		case isRename(i):
+			checkCompatibility(field.Schema, current.Fields[i].Schema, fieldPath, result)
+		default:
+			*result = append(*result, Incompatibility{
+				Path:    fieldPath,
+				Reason:  ReasonRemovedField,
+				Details: fmt.Sprintf("field at position %d was removed", i),
+			})
+		}
+	}
+	for j, field := range current.Fields {
+		if _, ok := storedIndices[field.Name]; ok || isRename(j) {
+			continue
+		}
+		*result = append(*result, Incompatibility{
+			Path:    joinPath(path, field.Name),
+			Reason:  ReasonAddedField,
+			Details: fmt.Sprintf("field at position %d was added", j),
+		})
+	}
+}
+
+func isWidthChange(from, to Kind) bool {
+	numericClass := func(kind Kind) int {
+		switch kind {
+		case KindUint8, KindUint16, KindUint32, KindUint64:
+			return 1
+		case KindInt8, KindInt16, KindInt32, KindInt64:
+			return 2
+		case KindFloat32, KindFloat64:
+			return 3
+		default:
+			return 0
+		}
+	}
+	fromClass := numericClass(from)
+	return fromClass != 0 && fromClass == numericClass(to)
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
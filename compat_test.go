@@ -0,0 +1,112 @@
+package gblob_test
+
+import (
+	"reflect"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+)
+
+var _ = Describe("CheckCompatibility", func() {
+	check := func(stored, current reflect.Type) []gblob.Incompatibility {
+		result, err := gblob.CheckTypeCompatibility(stored, current)
+		Expect(err).ToNot(HaveOccurred())
+		return result
+	}
+
+	Specify("identical", func() {
+		type Item struct {
+			A uint32
+			B []string
+			C map[uint8]float64
+		}
+		Expect(check(reflect.TypeFor[Item](), reflect.TypeFor[*Item]())).To(BeEmpty())
+	})
+
+	Specify("renamed field", func() {
+		type Old struct {
+			A uint32
+			B string
+		}
+		type New struct {
+			A     uint32
+			Title string
+		}
+		Expect(check(reflect.TypeFor[Old](), reflect.TypeFor[New]())).To(BeEmpty())
+	})
+
+	Specify("kind and width changes", func() {
+		type Old struct {
+			A uint16
+			B string
+			C [3]float32
+			D []int32
+		}
+		type New struct {
+			A uint32
+			B int64
+			C [4]float32
+			D []float32
+		}
+		Expect(check(reflect.TypeFor[Old](), reflect.TypeFor[New]())).To(Equal([]gblob.Incompatibility{
+			{Path: "A", Reason: gblob.ReasonWidthChange, Details: "uint16 changed to uint32"},
+			{Path: "B", Reason: gblob.ReasonKindChange, Details: "string changed to int64"},
+			{Path: "C", Reason: gblob.ReasonLengthChange, Details: "length 3 changed to 4"},
+			{Path: "D[]", Reason: gblob.ReasonKindChange, Details: "int32 changed to float32"},
+		}))
+	})
+
+	Specify("reordered, removed and added fields", func() {
+		type Inner struct {
+			X float32
+			Y float32
+		}
+		type Old struct {
+			A       uint8
+			B       uint8
+			Removed uint8
+			Inner   Inner
+		}
+		type New struct {
+			B     uint8
+			A     uint8
+			Inner struct {
+				X     float32
+				Y     float32
+				Added float32
+			}
+		}
+		Expect(check(reflect.TypeFor[Old](), reflect.TypeFor[New]())).To(Equal([]gblob.Incompatibility{
+			{Path: "A", Reason: gblob.ReasonReorder, Details: "position 0 changed to 1"},
+			{Path: "B", Reason: gblob.ReasonReorder, Details: "position 1 changed to 0"},
+			{Path: "Removed", Reason: gblob.ReasonRemovedField, Details: "field at position 2 was removed"},
+			{Path: "Inner", Reason: gblob.ReasonReorder, Details: "position 3 changed to 2"},
+		}))
+	})
+
+	Specify("nested added field", func() {
+		type Old struct {
+			Items map[string]struct{ X float32 }
+		}
+		type New struct {
+			Items map[string]struct{ X, Y float32 }
+		}
+		Expect(check(reflect.TypeFor[Old](), reflect.TypeFor[New]())).To(Equal([]gblob.Incompatibility{
+			{Path: "Items[].Y", Reason: gblob.ReasonAddedField, Details: "field at position 1 was added"},
+		}))
+	})
+
+	Specify("stored schema", func() {
+		stored := &gblob.Schema{
+			Kind: gblob.KindSlice,
+			Elem: &gblob.Schema{Kind: gblob.KindUint8},
+		}
+		current, err := gblob.SchemaFor[string]()
+		Expect(err).ToNot(HaveOccurred())
+		result := gblob.CheckCompatibility(stored, current)
+		Expect(result).To(HaveLen(1))
+		Expect(result[0].String()).To(Equal("<root>: kind change (slice changed to string)"))
+	})
+})
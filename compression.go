@@ -0,0 +1,233 @@
+package gblob
+
+import (
+	"bytes"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// compressionMagic identifies the header of a compressed container.
+var compressionMagic = []byte("GBLZ")
+
+// CompressionMethod identifies the algorithm that is used to compress the
+// data of a compressed container.
+type CompressionMethod uint8
+
+const (
+	// CompressionNone stores the data uncompressed.
+	CompressionNone CompressionMethod = iota
+
+	// CompressionGzip uses the gzip format.
+	CompressionGzip
+
+	// CompressionZlib uses the zlib format.
+	CompressionZlib
+
+	// CompressionFlate uses the raw DEFLATE format.
+	CompressionFlate
+)
+
+// Compressor provides the streams of a compression method. Implementations
+// can be registered through RegisterCompressor.
+type Compressor interface {
+
+	// NewWriter returns a writer that compresses data into the specified
+	// out Writer. Closing it needs to flush all data but not close out.
+	NewWriter(out io.Writer) (io.WriteCloser, error)
+
+	// NewReader returns a reader that decompresses data from the specified
+	// in Reader.
+	NewReader(in io.Reader) (io.ReadCloser, error)
+}
+
+var (
+	compressorsMu sync.RWMutex
+	compressors   = map[CompressionMethod]Compressor{
+		CompressionNone:  noneCompressor{},
+		CompressionGzip:  gzipCompressor{},
+		CompressionZlib:  zlibCompressor{},
+		CompressionFlate: flateCompressor{},
+	}
+)
+
+// RegisterCompressor registers the specified compressor for the specified
+// method, replacing any previous one. This allows methods outside of the
+// standard library, such as zstd or lz4, to be used. Custom methods should
+// use values of 16 and above.
+func RegisterCompressor(method CompressionMethod, compressor Compressor) {
+	compressorsMu.Lock()
+	defer compressorsMu.Unlock()
+	compressors[method] = compressor
+}
+
+func compressorOf(method CompressionMethod) (Compressor, error) {
+	compressorsMu.RLock()
+	defer compressorsMu.RUnlock()
+	compressor, ok := compressors[method]
+	if !ok {
+		return nil, fmt.Errorf("unsupported compression method: %d", method)
+	}
+	return compressor, nil
+}
+
+// NewCompressedPackedEncoder writes a compressed container header to the
+// out Writer and returns a CompressedPackedEncoder that compresses its output
+// with the specified method.
+//
+// The header consists of the "GBLZ" magic, the uint8 compression method and
+// a byte order marker, so that NewCompressedPackedDecoder can detect both.
+func NewCompressedPackedEncoder(out io.Writer, order ByteOrder, method CompressionMethod) (*CompressedPackedEncoder, error) {
+	compressor, err := compressorOf(method)
+	if err != nil {
+		return nil, err
+	}
+	header, err := newOrderedWriter(out, order)
+	if err != nil {
+		return nil, err
+	}
+	if err := header.WriteBytes(compressionMagic); err != nil {
+		return nil, err
+	}
+	if err := header.WriteUint8(uint8(method)); err != nil {
+		return nil, err
+	}
+	if err := header.WriteUint16(byteOrderMarker); err != nil {
+		return nil, err
+	}
+	stream, err := compressor.NewWriter(out)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := newOrderedWriter(stream, order)
+	if err != nil {
+		return nil, err
+	}
+	return &CompressedPackedEncoder{
+		PackedEncoder: &PackedEncoder{
+			out:   writer,
+			order: order,
+		},
+		stream: stream,
+	}, nil
+}
+
+// CompressedPackedEncoder is a PackedEncoder whose output is compressed. It
+// needs to be closed once all values have been encoded.
+type CompressedPackedEncoder struct {
+	*PackedEncoder
+	stream io.WriteCloser
+}
+
+// Close flushes the compressed stream. It does not close the underlying
+// writer.
+func (e *CompressedPackedEncoder) Close() error {
+	return e.stream.Close()
+}
+
+// NewCompressedPackedDecoder reads a compressed container header from the in
+// Reader and returns a CompressedPackedDecoder over the decompressed data,
+// using the compression method and byte order that are stored in the header.
+func NewCompressedPackedDecoder(in io.Reader) (*CompressedPackedDecoder, error) {
+	var header [7]byte
+	if _, err := io.ReadFull(in, header[:]); err != nil {
+		return nil, err
+	}
+	if !bytes.Equal(header[:4], compressionMagic) {
+		return nil, ErrInvalidMagic
+	}
+	var order ByteOrder
+	switch marker := header[5:7]; {
+	case LittleEndianBlock(marker).Uint16(0) == byteOrderMarker:
+		order = LittleEndian
+	case BigEndianBlock(marker).Uint16(0) == byteOrderMarker:
+		order = BigEndian
+	default:
+		return nil, ErrInvalidByteOrder
+	}
+	compressor, err := compressorOf(CompressionMethod(header[4]))
+	if err != nil {
+		return nil, err
+	}
+	stream, err := compressor.NewReader(in)
+	if err != nil {
+		return nil, err
+	}
+	reader, err := newOrderedReader(stream, order)
+	if err != nil {
+		stream.Close()
+		return nil, err
+	}
+	return &CompressedPackedDecoder{
+		PackedDecoder: &PackedDecoder{
+			in:    reader,
+			order: order,
+		},
+		stream: stream,
+	}, nil
+}
+
+// CompressedPackedDecoder is a PackedDecoder whose input is compressed. It
+// needs to be closed once all values have been decoded.
+type CompressedPackedDecoder struct {
+	*PackedDecoder
+	stream io.ReadCloser
+}
+
+// Close releases the resources of the decompression stream. It does not close
+// the underlying reader.
+func (d *CompressedPackedDecoder) Close() error {
+	return d.stream.Close()
+}
+
+type noneCompressor struct{}
+
+func (noneCompressor) NewWriter(out io.Writer) (io.WriteCloser, error) {
+	return nopWriteCloser{out}, nil
+}
+
+func (noneCompressor) NewReader(in io.Reader) (io.ReadCloser, error) {
+	return io.NopCloser(in), nil
+}
+
+type gzipCompressor struct{}
+
+func (gzipCompressor) NewWriter(out io.Writer) (io.WriteCloser, error) {
+	return gzip.NewWriter(out), nil
+}
+
+func (gzipCompressor) NewReader(in io.Reader) (io.ReadCloser, error) {
+	return gzip.NewReader(in)
+}
+
+type zlibCompressor struct{}
+
+func (zlibCompressor) NewWriter(out io.Writer) (io.WriteCloser, error) {
+	return zlib.NewWriter(out), nil
+}
+
+func (zlibCompressor) NewReader(in io.Reader) (io.ReadCloser, error) {
+	return zlib.NewReader(in)
+}
+
+type flateCompressor struct{}
+
+func (flateCompressor) NewWriter(out io.Writer) (io.WriteCloser, error) {
+	return flate.NewWriter(out, flate.DefaultCompression)
+}
+
+func (flateCompressor) NewReader(in io.Reader) (io.ReadCloser, error) {
+	return flate.NewReader(in), nil
+}
+
+// nopWriteCloser is an io.WriteCloser whose Close does nothing.
+type nopWriteCloser struct {
+	io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+	return nil
+}
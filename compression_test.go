@@ -0,0 +1,149 @@
+package gblob_test
+
+import (
+	"bytes"
+	"io"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+)
+
+var _ = Describe("Compression", func() {
+	type document struct {
+		Title string
+		Lines []string
+	}
+
+	var (
+		buffer *bytes.Buffer
+		source document
+	)
+
+	BeforeEach(func() {
+		buffer = new(bytes.Buffer)
+		source = document{
+			Title: "report",
+			Lines: make([]string, 100),
+		}
+		for i := range source.Lines {
+			source.Lines[i] = strings.Repeat("line ", 10)
+		}
+	})
+
+	DescribeTable("round trip",
+		func(order gblob.ByteOrder, method gblob.CompressionMethod) {
+			encoder, err := gblob.NewCompressedPackedEncoder(buffer, order, method)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(encoder.Encode(source)).To(Succeed())
+			Expect(encoder.Encode(uint32(0xCAFE))).To(Succeed())
+			Expect(encoder.Close()).To(Succeed())
+
+			Expect(buffer.Bytes()[:4]).To(Equal([]byte("GBLZ")))
+			Expect(buffer.Bytes()[4]).To(Equal(uint8(method)))
+			if method != gblob.CompressionNone {
+				Expect(buffer.Len()).To(BeNumerically("<", 1000))
+			}
+
+			decoder, err := gblob.NewCompressedPackedDecoder(buffer)
+			Expect(err).ToNot(HaveOccurred())
+			var target document
+			Expect(decoder.Decode(&target)).To(Succeed())
+			Expect(target).To(Equal(source))
+			var trailer uint32
+			Expect(decoder.Decode(&trailer)).To(Succeed())
+			Expect(trailer).To(Equal(uint32(0xCAFE)))
+			Expect(decoder.Close()).To(Succeed())
+		},
+		Entry("none", gblob.LittleEndian, gblob.CompressionNone),
+		Entry("gzip", gblob.LittleEndian, gblob.CompressionGzip),
+		Entry("zlib", gblob.BigEndian, gblob.CompressionZlib),
+		Entry("flate", gblob.BigEndian, gblob.CompressionFlate),
+	)
+
+	It("supports custom compressors", func() {
+		const method = gblob.CompressionMethod(16)
+		var closed bool
+		gblob.RegisterCompressor(method, xorCompressor{
+			closed: &closed,
+		})
+
+		encoder, err := gblob.NewCompressedPackedEncoder(buffer, gblob.LittleEndian, method)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(encoder.Encode(uint16(0x1234))).To(Succeed())
+		Expect(encoder.Close()).To(Succeed())
+		Expect(buffer.Bytes()[7:]).To(Equal([]byte{0x34 ^ 0xFF, 0x12 ^ 0xFF}))
+
+		decoder, err := gblob.NewCompressedPackedDecoder(buffer)
+		Expect(err).ToNot(HaveOccurred())
+		var target uint16
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(uint16(0x1234)))
+		Expect(closed).To(BeFalse())
+		Expect(decoder.Close()).To(Succeed())
+		Expect(closed).To(BeTrue())
+	})
+
+	It("rejects unknown methods", func() {
+		_, err := gblob.NewCompressedPackedEncoder(buffer, gblob.LittleEndian, gblob.CompressionMethod(200))
+		Expect(err).To(HaveOccurred())
+
+		buffer.Write([]byte{'G', 'B', 'L', 'Z', 200, 0xFF, 0xFE})
+		_, err = gblob.NewCompressedPackedDecoder(buffer)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rejects data without a container header", func() {
+		buffer.Write([]byte{'G', 'Z', 'I', 'P', 0x01, 0xFF, 0xFE})
+		_, err := gblob.NewCompressedPackedDecoder(buffer)
+		Expect(err).To(MatchError(gblob.ErrInvalidMagic))
+	})
+})
+
+// xorCompressor is a trivial Compressor that inverts all bits. It records
+// whether a reader has been closed.
+type xorCompressor struct {
+	closed *bool
+}
+
+func (xorCompressor) NewWriter(out io.Writer) (io.WriteCloser, error) {
+	return xorStream{out: out}, nil
+}
+
+func (c xorCompressor) NewReader(in io.Reader) (io.ReadCloser, error) {
+	return xorStream{
+		in:     in,
+		closed: c.closed,
+	}, nil
+}
+
+type xorStream struct {
+	in     io.Reader
+	out    io.Writer
+	closed *bool
+}
+
+func (s xorStream) Write(data []byte) (int, error) {
+	inverted := make([]byte, len(data))
+	for i, b := range data {
+		inverted[i] = b ^ 0xFF
+	}
+	return s.out.Write(inverted)
+}
+
+func (s xorStream) Read(data []byte) (int, error) {
+	n, err := s.in.Read(data)
+	for i := range n {
+		data[i] ^= 0xFF
+	}
+	return n, err
+}
+
+func (s xorStream) Close() error {
+	if s.closed != nil {
+		*s.closed = true
+	}
+	return nil
+}
@@ -0,0 +1,321 @@
+package gblob
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// ErrAuthenticationFailed indicates that encrypted data could not be
+// authenticated, because it was modified or the key is wrong.
+var ErrAuthenticationFailed = errors.New("authentication failed")
+
+// encryptionMagic identifies the header of an encrypted stream.
+var encryptionMagic = []byte("GBAE")
+
+const (
+	// encryptionSaltSize is the size of the random salt that is used to
+	// derive a separate key for each stream.
+	encryptionSaltSize = 16
+
+	// encryptionHeaderSize is the size of the magic, the algorithm, the
+	// chunk size and the salt.
+	encryptionHeaderSize = 4 + 1 + 4 + encryptionSaltSize
+
+	// encryptionFinalFlag marks the length of the final chunk of a stream.
+	encryptionFinalFlag = uint32(1) << 31
+
+	// defaultEncryptionChunkSize is the default maximum number of plaintext
+	// bytes per chunk.
+	defaultEncryptionChunkSize = 64 * 1024
+
+	// maxEncryptionChunkSize is the largest chunk size that is accepted, so
+	// that a corrupted header cannot cause a large allocation.
+	maxEncryptionChunkSize = 16 * 1024 * 1024
+)
+
+// EncryptionAlgorithm specifies the AEAD algorithm that is used to encrypt
+// and authenticate data.
+type EncryptionAlgorithm uint8
+
+const (
+	// EncryptionAESGCM uses AES in Galois/Counter Mode. The key needs to be
+	// 16, 24 or 32 bytes long.
+	EncryptionAESGCM EncryptionAlgorithm = iota + 1
+
+	// EncryptionChaCha20Poly1305 uses ChaCha20-Poly1305. The key needs to be
+	// 32 bytes long.
+	EncryptionChaCha20Poly1305
+)
+
+func (a EncryptionAlgorithm) newAEAD(key []byte) (cipher.AEAD, error) {
+	switch a {
+	case EncryptionAESGCM:
+		block, err := aes.NewCipher(key)
+		if err != nil {
+			return nil, err
+		}
+		return cipher.NewGCM(block)
+	case EncryptionChaCha20Poly1305:
+		return chacha20poly1305.New(key)
+	default:
+		return nil, fmt.Errorf("unsupported encryption algorithm: %d", a)
+	}
+}
+
+// newEncryptionAEAD derives the key of a single stream from the specified
+// key and the salt of the stream header.
+func newEncryptionAEAD(algorithm EncryptionAlgorithm, key, header []byte) (cipher.AEAD, error) {
+	salt := header[encryptionHeaderSize-encryptionSaltSize:]
+	streamKey, err := hkdf.Key(sha256.New, key, salt, "gblob encryption", len(key))
+	if err != nil {
+		return nil, err
+	}
+	return algorithm.newAEAD(streamKey)
+}
+
+// encryptionNonce returns the nonce of the chunk with the specified index,
+// which consists of the big endian index followed by a flag that marks the
+// final chunk.
+func encryptionNonce(nonce []byte, index uint64, final bool) []byte {
+	clear(nonce)
+	BigEndianBlock(nonce).SetUint64(len(nonce)-9, index)
+	if final {
+		nonce[len(nonce)-1] = 0x01
+	}
+	return nonce
+}
+
+// NewEncryptedWriter returns a new EncryptedWriter that encrypts data into
+// the specified out Writer using the specified algorithm and key.
+func NewEncryptedWriter(out io.Writer, algorithm EncryptionAlgorithm, key []byte) (*EncryptedWriter, error) {
+	if _, err := algorithm.newAEAD(key); err != nil {
+		return nil, err
+	}
+	return &EncryptedWriter{
+		out:       out,
+		algorithm: algorithm,
+		key:       key,
+		chunkSize: defaultEncryptionChunkSize,
+	}, nil
+}
+
+// EncryptedWriter is an io.Writer that encrypts and authenticates all data
+// that is written to it. It can be used below a PackedEncoder or
+// FrameEncoder to protect a whole file.
+//
+// The output starts with a header that holds the "GBAE" magic, the algorithm,
+// the chunk size and a random salt, from which a key for the stream is
+// derived. The data follows in chunks, each preceded by its uint32 length and
+// sealed separately, so that neither side needs to hold the whole stream in
+// memory. The header and the position of each chunk are authenticated, which
+// means that chunks cannot be reordered, dropped or truncated unnoticed.
+type EncryptedWriter struct {
+	out       io.Writer
+	algorithm EncryptionAlgorithm
+	key       []byte
+	chunkSize int
+	aead      cipher.AEAD
+	header    []byte
+	nonce     []byte
+	plain     []byte
+	sealed    []byte
+	index     uint64
+	closed    bool
+}
+
+// SetChunkSize configures the maximum number of bytes that are encrypted
+// together. The default is 64 KiB and the maximum is 16 MiB. It needs to be
+// called before any data is written.
+func (w *EncryptedWriter) SetChunkSize(size int) {
+	w.chunkSize = size
+}
+
+// Write encrypts the specified data. Data is written to the underlying writer
+// in whole chunks.
+func (w *EncryptedWriter) Write(data []byte) (int, error) {
+	if w.closed {
+		return 0, errors.New("write to closed encrypted writer")
+	}
+	if err := w.start(); err != nil {
+		return 0, err
+	}
+	var written int
+	for len(data) > 0 {
+		if len(w.plain) == w.chunkSize {
+			if err := w.seal(false); err != nil {
+				return written, err
+			}
+		}
+		n := min(len(data), w.chunkSize-len(w.plain))
+		w.plain = append(w.plain, data[:n]...)
+		data = data[n:]
+		written += n
+	}
+	return written, nil
+}
+
+// Close encrypts the remaining data as the final chunk. It does not close the
+// underlying writer.
+func (w *EncryptedWriter) Close() error {
+	if w.closed {
+		return nil
+	}
+	if err := w.start(); err != nil {
+		return err
+	}
+	w.closed = true
+	return w.seal(true)
+}
+
+func (w *EncryptedWriter) start() error {
+	if w.aead != nil {
+		return nil
+	}
+	if w.chunkSize <= 0 || w.chunkSize > maxEncryptionChunkSize {
+		return fmt.Errorf("invalid chunk size: %d", w.chunkSize)
+	}
+	header := make([]byte, encryptionHeaderSize)
+	copy(header, encryptionMagic)
+	header[4] = uint8(w.algorithm)
+	BigEndianBlock(header).SetUint32(5, uint32(w.chunkSize))
+	if _, err := rand.Read(header[9:]); err != nil {
+		return err
+	}
+	aead, err := newEncryptionAEAD(w.algorithm, w.key, header)
+	if err != nil {
+		return err
+	}
+	if _, err := w.out.Write(header); err != nil {
+		return err
+	}
+	w.aead = aead
+	w.header = header
+	w.nonce = make([]byte, aead.NonceSize())
+	w.plain = make([]byte, 0, w.chunkSize)
+	return nil
+}
+
+func (w *EncryptedWriter) seal(final bool) error {
+	nonce := encryptionNonce(w.nonce, w.index, final)
+	w.sealed = append(w.sealed[:0], 0, 0, 0, 0)
+	w.sealed = w.aead.Seal(w.sealed, nonce, w.plain, w.header)
+	length := uint32(len(w.sealed) - 4)
+	if final {
+		length |= encryptionFinalFlag
+	}
+	BigEndianBlock(w.sealed).SetUint32(0, length)
+	if _, err := w.out.Write(w.sealed); err != nil {
+		return err
+	}
+	w.plain = w.plain[:0]
+	w.index++
+	return nil
+}
+
+// NewEncryptedReader returns a new EncryptedReader that decrypts data from
+// the specified in Reader using the specified key. The algorithm is read from
+// the header of the data.
+func NewEncryptedReader(in io.Reader, key []byte) *EncryptedReader {
+	return &EncryptedReader{
+		in:  in,
+		key: key,
+	}
+}
+
+// EncryptedReader is an io.Reader that reads data that was written through
+// an EncryptedWriter.
+//
+// Each chunk is authenticated before any of its data is returned. If
+// authentication fails, ErrAuthenticationFailed is returned instead. If the
+// input ends before the final chunk, io.ErrUnexpectedEOF is returned.
+type EncryptedReader struct {
+	in        io.Reader
+	key       []byte
+	aead      cipher.AEAD
+	header    []byte
+	chunkSize int
+	nonce     []byte
+	sealed    []byte
+	plain     bytes.Reader
+	index     uint64
+	final     bool
+	err       error
+}
+
+// Read reads authenticated data into the specified target.
+func (r *EncryptedReader) Read(target []byte) (int, error) {
+	for r.plain.Len() == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.final {
+			return 0, io.EOF
+		}
+		r.err = r.open()
+	}
+	return r.plain.Read(target)
+}
+
+func (r *EncryptedReader) open() error {
+	if r.aead == nil {
+		if err := r.start(); err != nil {
+			return err
+		}
+	}
+	var prefix [4]byte
+	if _, err := io.ReadFull(r.in, prefix[:]); err != nil {
+		return eofAsUnexpected(err)
+	}
+	length := BigEndianBlock(prefix[:]).Uint32(0)
+	final := length&encryptionFinalFlag != 0
+	length &^= encryptionFinalFlag
+	if int(length) > r.chunkSize+r.aead.Overhead() {
+		return ErrAuthenticationFailed
+	}
+	r.sealed = slices.Grow(r.sealed[:0], int(length))[:length]
+	if _, err := io.ReadFull(r.in, r.sealed); err != nil {
+		return eofAsUnexpected(err)
+	}
+	nonce := encryptionNonce(r.nonce, r.index, final)
+	plain, err := r.aead.Open(r.sealed[:0], nonce, r.sealed, r.header)
+	if err != nil {
+		return ErrAuthenticationFailed
+	}
+	r.plain.Reset(plain)
+	r.index++
+	r.final = final
+	return nil
+}
+
+func (r *EncryptedReader) start() error {
+	header := make([]byte, encryptionHeaderSize)
+	if _, err := io.ReadFull(r.in, header); err != nil {
+		return eofAsUnexpected(err)
+	}
+	if !bytes.Equal(header[:4], encryptionMagic) {
+		return ErrInvalidMagic
+	}
+	aead, err := newEncryptionAEAD(EncryptionAlgorithm(header[4]), r.key, header)
+	if err != nil {
+		return err
+	}
+	chunkSize := BigEndianBlock(header).Uint32(5)
+	if chunkSize == 0 || chunkSize > maxEncryptionChunkSize {
+		return fmt.Errorf("invalid chunk size: %d", chunkSize)
+	}
+	r.aead = aead
+	r.header = header
+	r.chunkSize = int(chunkSize)
+	r.nonce = make([]byte, aead.NonceSize())
+	return nil
+}
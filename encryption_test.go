@@ -0,0 +1,195 @@
+package gblob_test
+
+import (
+	"bytes"
+	"io"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+)
+
+var _ = Describe("Encryption", func() {
+	type save struct {
+		Player string
+		Score  uint64
+		Levels []uint32
+	}
+
+	var (
+		key    []byte
+		buffer *bytes.Buffer
+		source save
+	)
+
+	BeforeEach(func() {
+		key = bytes.Repeat([]byte{0x42}, 32)
+		buffer = new(bytes.Buffer)
+		source = save{
+			Player: "player",
+			Score:  1000,
+			Levels: make([]uint32, 1000),
+		}
+		for i := range source.Levels {
+			source.Levels[i] = uint32(i)
+		}
+	})
+
+	encrypt := func(algorithm gblob.EncryptionAlgorithm, chunkSize int) {
+		writer, err := gblob.NewEncryptedWriter(buffer, algorithm, key)
+		Expect(err).ToNot(HaveOccurred())
+		if chunkSize > 0 {
+			writer.SetChunkSize(chunkSize)
+		}
+		Expect(gblob.NewLittleEndianPackedEncoder(writer).Encode(source)).To(Succeed())
+		Expect(writer.Close()).To(Succeed())
+	}
+
+	decrypt := func() (save, error) {
+		var target save
+		reader := gblob.NewEncryptedReader(buffer, key)
+		err := gblob.NewLittleEndianPackedDecoder(reader).Decode(&target)
+		return target, err
+	}
+
+	DescribeTable("round trip",
+		func(algorithm gblob.EncryptionAlgorithm, chunkSize int) {
+			encrypt(algorithm, chunkSize)
+			Expect(buffer.Bytes()[:4]).To(Equal([]byte("GBAE")))
+			Expect(buffer.Bytes()).ToNot(ContainSubstring("player"))
+
+			target, err := decrypt()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(target).To(Equal(source))
+		},
+		Entry("AES-GCM", gblob.EncryptionAESGCM, 0),
+		Entry("AES-GCM with small chunks", gblob.EncryptionAESGCM, 100),
+		Entry("ChaCha20-Poly1305", gblob.EncryptionChaCha20Poly1305, 0),
+		Entry("ChaCha20-Poly1305 with small chunks", gblob.EncryptionChaCha20Poly1305, 100),
+	)
+
+	DescribeTable("data sizes",
+		func(size int) {
+			data := make([]byte, size)
+			for i := range data {
+				data[i] = byte(i)
+			}
+			writer, err := gblob.NewEncryptedWriter(buffer, gblob.EncryptionAESGCM, key)
+			Expect(err).ToNot(HaveOccurred())
+			writer.SetChunkSize(16)
+			_, err = writer.Write(data)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(writer.Close()).To(Succeed())
+
+			result, err := io.ReadAll(gblob.NewEncryptedReader(buffer, key))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(data))
+		},
+		Entry("empty", 0),
+		Entry("partial chunk", 10),
+		Entry("exact chunk", 16),
+		Entry("exact chunk multiple", 64),
+		Entry("uneven", 70),
+	)
+
+	It("detects modified data", func() {
+		encrypt(gblob.EncryptionAESGCM, 100)
+		buffer.Bytes()[buffer.Len()/2] ^= 0x01
+		_, err := decrypt()
+		Expect(err).To(MatchError(gblob.ErrAuthenticationFailed))
+	})
+
+	It("detects a modified header", func() {
+		encrypt(gblob.EncryptionChaCha20Poly1305, 0)
+		buffer.Bytes()[8] ^= 0x01
+		_, err := decrypt()
+		Expect(err).To(MatchError(gblob.ErrAuthenticationFailed))
+	})
+
+	It("detects truncated data", func() {
+		encrypt(gblob.EncryptionAESGCM, 100)
+		buffer.Truncate(buffer.Len() - 1)
+		_, err := decrypt()
+		Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+	})
+
+	It("detects missing chunks", func() {
+		writer, err := gblob.NewEncryptedWriter(buffer, gblob.EncryptionAESGCM, key)
+		Expect(err).ToNot(HaveOccurred())
+		writer.SetChunkSize(4)
+		_, err = writer.Write([]byte("abcdefgh"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(writer.Close()).To(Succeed())
+
+		By("dropping the final chunk")
+		data := buffer.Bytes()[:25+4+4+16]
+		_, err = io.ReadAll(gblob.NewEncryptedReader(bytes.NewReader(data), key))
+		Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+
+		By("dropping the first chunk")
+		data = append(bytes.Clone(buffer.Bytes()[:25]), buffer.Bytes()[25+4+4+16:]...)
+		_, err = io.ReadAll(gblob.NewEncryptedReader(bytes.NewReader(data), key))
+		Expect(err).To(MatchError(gblob.ErrAuthenticationFailed))
+	})
+
+	It("does not return data of a chunk that fails authentication", func() {
+		writer, err := gblob.NewEncryptedWriter(buffer, gblob.EncryptionAESGCM, key)
+		Expect(err).ToNot(HaveOccurred())
+		writer.SetChunkSize(4)
+		_, err = writer.Write([]byte("abcdefgh"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(writer.Close()).To(Succeed())
+		buffer.Bytes()[25+4+4+16+4] ^= 0x01
+
+		result, err := io.ReadAll(gblob.NewEncryptedReader(buffer, key))
+		Expect(err).To(MatchError(gblob.ErrAuthenticationFailed))
+		Expect(result).To(Equal([]byte("abcd")))
+	})
+
+	It("rejects excessive chunk sizes", func() {
+		writer, err := gblob.NewEncryptedWriter(buffer, gblob.EncryptionAESGCM, key)
+		Expect(err).ToNot(HaveOccurred())
+		writer.SetChunkSize(32 * 1024 * 1024)
+		_, err = writer.Write([]byte("abcd"))
+		Expect(err).To(MatchError(ContainSubstring("invalid chunk size")))
+
+		By("reading a header with a corrupted chunk size")
+		buffer.Reset()
+		encrypt(gblob.EncryptionAESGCM, 0)
+		gblob.BigEndianBlock(buffer.Bytes()).SetUint32(5, 0x7FFFFFF0)
+		gblob.BigEndianBlock(buffer.Bytes()).SetUint32(25, 0x7FFFFF00)
+		_, err = decrypt()
+		Expect(err).To(MatchError(ContainSubstring("invalid chunk size")))
+	})
+
+	It("rejects the wrong key", func() {
+		encrypt(gblob.EncryptionChaCha20Poly1305, 0)
+		key = bytes.Repeat([]byte{0x43}, 32)
+		_, err := decrypt()
+		Expect(err).To(MatchError(gblob.ErrAuthenticationFailed))
+	})
+
+	It("uses a different stream key each time", func() {
+		encrypt(gblob.EncryptionAESGCM, 0)
+		first := bytes.Clone(buffer.Bytes())
+		buffer.Reset()
+		encrypt(gblob.EncryptionAESGCM, 0)
+		Expect(buffer.Bytes()).ToNot(Equal(first))
+	})
+
+	It("rejects invalid keys and algorithms", func() {
+		_, err := gblob.NewEncryptedWriter(buffer, gblob.EncryptionAESGCM, make([]byte, 7))
+		Expect(err).To(HaveOccurred())
+		_, err = gblob.NewEncryptedWriter(buffer, gblob.EncryptionChaCha20Poly1305, make([]byte, 16))
+		Expect(err).To(HaveOccurred())
+		_, err = gblob.NewEncryptedWriter(buffer, gblob.EncryptionAlgorithm(200), key)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("rejects data without an encryption header", func() {
+		buffer.Write(make([]byte, 25))
+		_, err := decrypt()
+		Expect(err).To(MatchError(gblob.ErrInvalidMagic))
+	})
+})
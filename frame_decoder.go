@@ -0,0 +1,199 @@
+package gblob
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ErrTruncatedFrame indicates that the input ended in the middle of a frame.
+var ErrTruncatedFrame = errors.New("truncated frame")
+
+// NewLittleEndianFrameDecoder creates a new FrameDecoder that is configured
+// to read its input in Little Endian order.
+func NewLittleEndianFrameDecoder(in io.Reader) *FrameDecoder {
+	result := &FrameDecoder{
+		source: in,
+		in:     NewLittleEndianReader(in),
+	}
+	result.decoder = NewLittleEndianPackedDecoder(&result.payload)
+	result.sumOut = NewLittleEndianWriter(&result.sum)
+	return result
+}
+
+// NewBigEndianFrameDecoder creates a new FrameDecoder that is configured
+// to read its input in Big Endian order.
+func NewBigEndianFrameDecoder(in io.Reader) *FrameDecoder {
+	result := &FrameDecoder{
+		source: in,
+		in:     NewBigEndianReader(in),
+	}
+	result.decoder = NewBigEndianPackedDecoder(&result.payload)
+	result.sumOut = NewBigEndianWriter(&result.sum)
+	return result
+}
+
+// NewNativeEndianFrameDecoder creates a new FrameDecoder that is configured
+// to read its input in the byte order of the host.
+func NewNativeEndianFrameDecoder(in io.Reader) *FrameDecoder {
+	result := &FrameDecoder{
+		source: in,
+		in:     NewNativeEndianReader(in),
+	}
+	result.decoder = NewNativeEndianPackedDecoder(&result.payload)
+	result.sumOut = NewNativeEndianWriter(&result.sum)
+	return result
+}
+
+// FrameDecoder decodes Go objects from frames that were written by a
+// FrameEncoder.
+type FrameDecoder struct {
+	source  io.Reader
+	in      TypedReader
+	payload io.LimitedReader
+	decoder *PackedDecoder
+	tag     uint32
+	pending bool
+
+	checksum ChecksumAlgorithm
+	sum      hashSink
+	sumOut   TypedWriter
+	verified bool
+	data     bytes.Buffer
+	dataIn   bytes.Reader
+	digest   [8]byte
+	expected [8]byte
+}
+
+// SetChecksum configures the decoder to expect a checksum of the specified
+// algorithm at the end of each frame. Payloads are verified before they are
+// decoded and ErrChecksumMismatch is returned if verification fails.
+func (d *FrameDecoder) SetChecksum(algorithm ChecksumAlgorithm) error {
+	if err := algorithm.validate(); err != nil {
+		return err
+	}
+	d.checksum = algorithm
+	d.sum.hash = algorithm.newHash()
+	return nil
+}
+
+// Next reads the header of the next frame and returns its tag.
+//
+// If the input ends cleanly before a new frame, io.EOF is returned. If the
+// input ends in the middle of the header, ErrTruncatedFrame is returned.
+//
+// Any unread payload of the current frame is skipped.
+func (d *FrameDecoder) Next() (uint32, error) {
+	if d.pending {
+		if err := d.Skip(); err != nil {
+			return 0, err
+		}
+	}
+	tag, err := d.in.ReadUint32()
+	if err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return 0, ErrTruncatedFrame
+		}
+		return 0, err
+	}
+	length, err := d.in.ReadUint64()
+	if err != nil {
+		return 0, truncatedFrameError(err)
+	}
+	if length > math.MaxInt {
+		return 0, fmt.Errorf("frame length %d exceeds maximum: %w", length, ErrTruncatedFrame)
+	}
+	d.tag = tag
+	d.payload.R = d.source
+	d.payload.N = int64(length)
+	d.pending = true
+	d.verified = false
+	return tag, nil
+}
+
+// Decode decodes the payload of the current frame into the specified target.
+// If there is no current frame, Next is called first and the tag is
+// discarded. Any payload that remains after decoding is skipped.
+func (d *FrameDecoder) Decode(target any) error {
+	if !d.pending {
+		if _, err := d.Next(); err != nil {
+			return err
+		}
+	}
+	if d.checksum != ChecksumNone && !d.verified {
+		if err := d.verify(); err != nil {
+			d.pending = false
+			return err
+		}
+	}
+	if err := d.decoder.Decode(target); err != nil {
+		if d.payload.N == 0 && isEOF(err) {
+			d.pending = false
+			return fmt.Errorf("frame payload too short: %w", io.ErrUnexpectedEOF)
+		}
+		return truncatedFrameError(err)
+	}
+	return d.Skip()
+}
+
+// Skip skips the payload of the current frame without decoding it.
+func (d *FrameDecoder) Skip() error {
+	if !d.pending {
+		return nil
+	}
+	remaining := d.payload.N
+	if d.verified {
+		remaining = 0 // already consumed from the source
+	} else {
+		remaining += int64(d.checksum.Size())
+	}
+	d.payload.N = 0
+	d.pending = false
+	if remaining == 0 {
+		return nil
+	}
+	if err := d.in.SkipBytes(int(remaining)); err != nil {
+		return truncatedFrameError(err)
+	}
+	return nil
+}
+
+func (d *FrameDecoder) verify() error {
+	length := d.payload.N
+	d.sum.hash.Reset()
+	d.sumOut.WriteUint32(d.tag)
+	d.sumOut.WriteUint64(uint64(length))
+	d.data.Reset()
+	copied, err := io.Copy(io.MultiWriter(&d.sum, &d.data), &d.payload)
+	if err != nil {
+		return truncatedFrameError(err)
+	}
+	if copied != length {
+		return ErrTruncatedFrame
+	}
+	expected := d.expected[:d.checksum.Size()]
+	if err := d.in.ReadBytes(expected); err != nil {
+		return truncatedFrameError(err)
+	}
+	if !bytes.Equal(d.sum.hash.Sum(d.digest[:0]), expected) {
+		return ErrChecksumMismatch
+	}
+	d.dataIn.Reset(d.data.Bytes())
+	d.payload.R = &d.dataIn
+	d.payload.N = length
+	d.verified = true
+	return nil
+}
+
+func truncatedFrameError(err error) error {
+	if isEOF(err) {
+		return ErrTruncatedFrame
+	}
+	return err
+}
+
+func isEOF(err error) bool {
+	return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+}
@@ -0,0 +1,239 @@
+package gblob_test
+
+import (
+	"bytes"
+	"io"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+)
+
+var _ = Describe("FrameDecoder", func() {
+	var (
+		buffer  *bytes.Buffer
+		decoder *gblob.FrameDecoder
+	)
+
+	BeforeEach(func() {
+		buffer = new(bytes.Buffer)
+		decoder = gblob.NewLittleEndianFrameDecoder(buffer)
+	})
+
+	Specify("Decode", func() {
+		buffer.Write([]uint8{
+			0x00, 0x00, 0x00, 0x00, // tag
+			0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // length
+			0xCA, 0xF1, // payload
+		})
+
+		var target uint16
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(uint16(0xF1CA)))
+
+		Expect(decoder.Decode(&target)).To(MatchError(io.EOF))
+	})
+
+	Specify("Next and Skip", func() {
+		buffer.Write([]uint8{
+			0x13, 0x00, 0x00, 0x00, // tag
+			0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // length
+			0x01, 0x02, 0x03, // payload
+			0x14, 0x00, 0x00, 0x00, // tag
+			0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // length
+			0x37, // payload
+		})
+
+		tag, err := decoder.Next()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tag).To(Equal(uint32(0x13)))
+		Expect(decoder.Skip()).To(Succeed())
+
+		tag, err = decoder.Next()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tag).To(Equal(uint32(0x14)))
+
+		var target uint8
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(uint8(0x37)))
+
+		_, err = decoder.Next()
+		Expect(err).To(MatchError(io.EOF))
+	})
+
+	Specify("Next skips unread payload", func() {
+		buffer.Write([]uint8{
+			0x13, 0x00, 0x00, 0x00, // tag
+			0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // length
+			0x01, 0x02, 0x03, // payload
+			0x14, 0x00, 0x00, 0x00, // tag
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // length
+		})
+
+		_, err := decoder.Next()
+		Expect(err).ToNot(HaveOccurred())
+
+		tag, err := decoder.Next()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tag).To(Equal(uint32(0x14)))
+	})
+
+	Specify("Decode skips remaining payload", func() {
+		buffer.Write([]uint8{
+			0x00, 0x00, 0x00, 0x00, // tag
+			0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // length
+			0x01, 0x02, 0x03, // payload
+			0x00, 0x00, 0x00, 0x00, // tag
+			0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // length
+			0x37, // payload
+		})
+
+		var target uint8
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(uint8(0x01)))
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(uint8(0x37)))
+	})
+
+	Specify("truncated header", func() {
+		buffer.Write([]uint8{
+			0x00, 0x00, 0x00, 0x00, // tag
+			0x03, 0x00, 0x00, // partial length
+		})
+
+		_, err := decoder.Next()
+		Expect(err).To(MatchError(gblob.ErrTruncatedFrame))
+	})
+
+	Specify("truncated payload", func() {
+		buffer.Write([]uint8{
+			0x00, 0x00, 0x00, 0x00, // tag
+			0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // length
+			0x01, 0x02, // partial payload
+		})
+
+		var target uint32
+		Expect(decoder.Decode(&target)).To(MatchError(gblob.ErrTruncatedFrame))
+	})
+
+	Specify("payload too short", func() {
+		buffer.Write([]uint8{
+			0x00, 0x00, 0x00, 0x00, // tag
+			0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // length
+			0x01, 0x02, // payload
+			0x00, 0x00, 0x00, 0x00, // tag
+			0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // length
+			0x37, // payload
+		})
+
+		var target uint32
+		Expect(decoder.Decode(&target)).To(MatchError(io.ErrUnexpectedEOF))
+
+		var next uint8
+		Expect(decoder.Decode(&next)).To(Succeed())
+		Expect(next).To(Equal(uint8(0x37)))
+	})
+
+	Specify("round trip", func() {
+		encoder := gblob.NewBigEndianFrameEncoder(buffer)
+		decoder = gblob.NewBigEndianFrameDecoder(buffer)
+		Expect(encoder.EncodeTagged(1, []string{"a", "b"})).To(Succeed())
+		Expect(encoder.EncodeTagged(2, float64(3.5))).To(Succeed())
+
+		tag, err := decoder.Next()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tag).To(Equal(uint32(1)))
+		var first []string
+		Expect(decoder.Decode(&first)).To(Succeed())
+		Expect(first).To(Equal([]string{"a", "b"}))
+
+		tag, err = decoder.Next()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(tag).To(Equal(uint32(2)))
+		var second float64
+		Expect(decoder.Decode(&second)).To(Succeed())
+		Expect(second).To(Equal(3.5))
+	})
+
+	When("checksum", func() {
+		var encoder *gblob.FrameEncoder
+
+		BeforeEach(func() {
+			encoder = gblob.NewLittleEndianFrameEncoder(buffer)
+			Expect(encoder.SetChecksum(gblob.ChecksumCRC32C)).To(Succeed())
+			Expect(decoder.SetChecksum(gblob.ChecksumCRC32C)).To(Succeed())
+		})
+
+		Specify("round trip", func() {
+			Expect(encoder.EncodeTagged(1, "hello")).To(Succeed())
+			Expect(encoder.EncodeTagged(2, uint32(0xF1CA7632))).To(Succeed())
+			Expect(encoder.EncodeTagged(3, "world")).To(Succeed())
+
+			var first string
+			Expect(decoder.Decode(&first)).To(Succeed())
+			Expect(first).To(Equal("hello"))
+
+			tag, err := decoder.Next()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tag).To(Equal(uint32(2)))
+			Expect(decoder.Skip()).To(Succeed())
+
+			var third string
+			Expect(decoder.Decode(&third)).To(Succeed())
+			Expect(third).To(Equal("world"))
+
+			_, err = decoder.Next()
+			Expect(err).To(MatchError(io.EOF))
+		})
+
+		Specify("corrupted payload", func() {
+			Expect(encoder.EncodeTagged(1, "hello")).To(Succeed())
+			Expect(encoder.EncodeTagged(2, "world")).To(Succeed())
+			buffer.Bytes()[4+8+8] ^= 0x01
+
+			var target string
+			Expect(decoder.Decode(&target)).To(MatchError(gblob.ErrChecksumMismatch))
+			Expect(target).To(BeEmpty())
+
+			Expect(decoder.Decode(&target)).To(Succeed())
+			Expect(target).To(Equal("world"))
+		})
+
+		Specify("corrupted tag", func() {
+			Expect(encoder.EncodeTagged(1, "hello")).To(Succeed())
+			buffer.Bytes()[0] ^= 0x02
+
+			tag, err := decoder.Next()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(tag).To(Equal(uint32(3)))
+
+			var target string
+			Expect(decoder.Decode(&target)).To(MatchError(gblob.ErrChecksumMismatch))
+		})
+
+		Specify("truncated checksum", func() {
+			Expect(encoder.EncodeTagged(1, "hello")).To(Succeed())
+			buffer.Truncate(buffer.Len() - 2)
+
+			var target string
+			Expect(decoder.Decode(&target)).To(MatchError(gblob.ErrTruncatedFrame))
+		})
+
+		Specify("corrupted length", func() {
+			Expect(encoder.EncodeTagged(1, "hello")).To(Succeed())
+			buffer.Bytes()[4+7] ^= 0x80
+
+			var target string
+			Expect(decoder.Decode(&target)).To(MatchError(gblob.ErrTruncatedFrame))
+		})
+
+		Specify("excessive length", func() {
+			Expect(encoder.EncodeTagged(1, "hello")).To(Succeed())
+			buffer.Bytes()[4+6] = 0x7F
+
+			var target string
+			Expect(decoder.Decode(&target)).To(MatchError(gblob.ErrTruncatedFrame))
+		})
+	})
+})
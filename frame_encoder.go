@@ -0,0 +1,100 @@
+package gblob
+
+import (
+	"bytes"
+	"io"
+)
+
+// NewLittleEndianFrameEncoder creates a new FrameEncoder that is configured
+// to write its output in Little Endian order.
+func NewLittleEndianFrameEncoder(out io.Writer) *FrameEncoder {
+	result := &FrameEncoder{
+		out: NewLittleEndianWriter(out),
+	}
+	result.encoder = NewLittleEndianPackedEncoder(&result.buffer)
+	result.sumOut = NewLittleEndianWriter(&result.sum)
+	return result
+}
+
+// NewBigEndianFrameEncoder creates a new FrameEncoder that is configured
+// to write its output in Big Endian order.
+func NewBigEndianFrameEncoder(out io.Writer) *FrameEncoder {
+	result := &FrameEncoder{
+		out: NewBigEndianWriter(out),
+	}
+	result.encoder = NewBigEndianPackedEncoder(&result.buffer)
+	result.sumOut = NewBigEndianWriter(&result.sum)
+	return result
+}
+
+// NewNativeEndianFrameEncoder creates a new FrameEncoder that is configured
+// to write its output in the byte order of the host.
+func NewNativeEndianFrameEncoder(out io.Writer) *FrameEncoder {
+	result := &FrameEncoder{
+		out: NewNativeEndianWriter(out),
+	}
+	result.encoder = NewNativeEndianPackedEncoder(&result.buffer)
+	result.sumOut = NewNativeEndianWriter(&result.sum)
+	return result
+}
+
+// FrameEncoder encodes Go objects in packed form, wrapping each one in a
+// separate frame.
+//
+// Each frame starts with a uint32 tag, followed by a uint64 length, followed
+// by the packed payload. This allows a FrameDecoder to skip frames it does
+// not recognize. If a checksum is configured, it follows the payload and
+// covers the tag, the length and the payload.
+type FrameEncoder struct {
+	out      TypedWriter
+	buffer   bytes.Buffer
+	encoder  *PackedEncoder
+	checksum ChecksumAlgorithm
+	sum      hashSink
+	sumOut   TypedWriter
+	digest   [8]byte
+}
+
+// SetChecksum configures the encoder to append a checksum of the specified
+// algorithm to each frame. The FrameDecoder needs to be configured with the
+// same algorithm.
+func (e *FrameEncoder) SetChecksum(algorithm ChecksumAlgorithm) error {
+	if err := algorithm.validate(); err != nil {
+		return err
+	}
+	e.checksum = algorithm
+	e.sum.hash = algorithm.newHash()
+	return nil
+}
+
+// Encode encodes the specified source value as a frame with a zero tag.
+func (e *FrameEncoder) Encode(source any) error {
+	return e.EncodeTagged(0, source)
+}
+
+// EncodeTagged encodes the specified source value as a frame with the
+// specified tag. The tag can be used by the reading side to determine the
+// type of the payload.
+func (e *FrameEncoder) EncodeTagged(tag uint32, source any) error {
+	e.buffer.Reset()
+	if err := e.encoder.Encode(source); err != nil {
+		return err
+	}
+	if err := e.out.WriteUint32(tag); err != nil {
+		return err
+	}
+	if err := e.out.WriteUint64(uint64(e.buffer.Len())); err != nil {
+		return err
+	}
+	if err := e.out.WriteBytes(e.buffer.Bytes()); err != nil {
+		return err
+	}
+	if e.checksum == ChecksumNone {
+		return nil
+	}
+	e.sum.hash.Reset()
+	e.sumOut.WriteUint32(tag)
+	e.sumOut.WriteUint64(uint64(e.buffer.Len()))
+	e.sumOut.WriteBytes(e.buffer.Bytes())
+	return e.out.WriteBytes(e.sum.hash.Sum(e.digest[:0]))
+}
@@ -0,0 +1,65 @@
+package gblob_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+)
+
+var _ = Describe("FrameEncoder", func() {
+	var (
+		buffer  *bytes.Buffer
+		encoder *gblob.FrameEncoder
+	)
+
+	BeforeEach(func() {
+		buffer = new(bytes.Buffer)
+		encoder = gblob.NewLittleEndianFrameEncoder(buffer)
+	})
+
+	Specify("Encode", func() {
+		Expect(encoder.Encode(uint16(0xF1CA))).To(Succeed())
+		Expect(buffer.Bytes()).To(Equal([]uint8{
+			0x00, 0x00, 0x00, 0x00, // tag
+			0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // length
+			0xCA, 0xF1, // payload
+		}))
+	})
+
+	Specify("EncodeTagged", func() {
+		Expect(encoder.EncodeTagged(0x13, uint8(0x37))).To(Succeed())
+		Expect(encoder.EncodeTagged(0x14, "hi")).To(Succeed())
+		Expect(buffer.Bytes()).To(Equal([]uint8{
+			0x13, 0x00, 0x00, 0x00, // tag
+			0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // length
+			0x37,                   // payload
+			0x14, 0x00, 0x00, 0x00, // tag
+			0x0A, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // length
+			0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 'h', 'i', // payload
+		}))
+	})
+
+	Specify("BigEndian", func() {
+		encoder = gblob.NewBigEndianFrameEncoder(buffer)
+		Expect(encoder.EncodeTagged(0x13, uint16(0xF1CA))).To(Succeed())
+		Expect(buffer.Bytes()).To(Equal([]uint8{
+			0x00, 0x00, 0x00, 0x13, // tag
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, // length
+			0xF1, 0xCA, // payload
+		}))
+	})
+
+	Specify("SetChecksum", func() {
+		Expect(encoder.SetChecksum(gblob.ChecksumCRC32)).To(Succeed())
+		Expect(encoder.Encode(uint16(0xF1CA))).To(Succeed())
+		Expect(buffer.Bytes()).To(HaveLen(4 + 8 + 2 + 4))
+		Expect(buffer.Bytes()[:14]).To(Equal([]uint8{
+			0x00, 0x00, 0x00, 0x00, // tag
+			0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // length
+			0xCA, 0xF1, // payload
+		}))
+	})
+})
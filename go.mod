@@ -6,6 +6,7 @@ require (
 	github.com/mokiat/gog v0.17.0
 	github.com/onsi/ginkgo/v2 v2.23.4
 	github.com/onsi/gomega v1.37.0
+	golang.org/x/crypto v0.38.0
 )
 
 require (
@@ -0,0 +1,173 @@
+package gblob
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+)
+
+var (
+	// ErrInvalidMagic indicates that the input does not start with the
+	// expected magic sequence.
+	ErrInvalidMagic = errors.New("invalid magic")
+
+	// ErrInvalidByteOrder indicates that the byte order marker of a header
+	// is not recognized.
+	ErrInvalidByteOrder = errors.New("invalid byte order marker")
+)
+
+// byteOrderMarker is written in the byte order of the file, which allows
+// the reading side to detect the order.
+const byteOrderMarker = uint16(0xFEFF)
+
+// ByteOrder specifies the order in which the bytes of multi-byte values are
+// stored.
+type ByteOrder uint8
+
+const (
+	// LittleEndian stores the least significant byte first.
+	LittleEndian ByteOrder = iota
+
+	// BigEndian stores the most significant byte first.
+	BigEndian
+)
+
+// String returns a string representation of the byte order.
+func (o ByteOrder) String() string {
+	switch o {
+	case LittleEndian:
+		return "LittleEndian"
+	case BigEndian:
+		return "BigEndian"
+	default:
+		return fmt.Sprintf("ByteOrder(%d)", uint8(o))
+	}
+}
+
+// Header describes the start of a file that allows it to be identified and
+// decoded correctly.
+//
+// It is stored as the raw Magic bytes, followed by a uint16 byte order
+// marker and a uint32 Version, both in the byte order of the file.
+type Header struct {
+
+	// Magic is an application-specific sequence of bytes that identifies
+	// the file format.
+	Magic []byte
+
+	// Version is an application-specific version of the file format.
+	Version uint32
+
+	// ByteOrder is the byte order in which the file is stored.
+	ByteOrder ByteOrder
+}
+
+// WriteHeader writes the specified header to the out Writer.
+func WriteHeader(out io.Writer, header Header) error {
+	writer, err := newOrderedWriter(out, header.ByteOrder)
+	if err != nil {
+		return err
+	}
+	if err := writer.WriteBytes(header.Magic); err != nil {
+		return err
+	}
+	if err := writer.WriteUint16(byteOrderMarker); err != nil {
+		return err
+	}
+	return writer.WriteUint32(header.Version)
+}
+
+// ReadHeader reads a header from the in Reader. The header is expected to
+// start with the specified magic, otherwise ErrInvalidMagic is returned.
+func ReadHeader(in io.Reader, magic []byte) (Header, error) {
+	actualMagic := make([]byte, len(magic))
+	if _, err := io.ReadFull(in, actualMagic); err != nil {
+		return Header{}, err
+	}
+	if !bytes.Equal(actualMagic, magic) {
+		return Header{}, ErrInvalidMagic
+	}
+	var marker [2]byte
+	if _, err := io.ReadFull(in, marker[:]); err != nil {
+		return Header{}, err
+	}
+	var order ByteOrder
+	switch {
+	case LittleEndianBlock(marker[:]).Uint16(0) == byteOrderMarker:
+		order = LittleEndian
+	case BigEndianBlock(marker[:]).Uint16(0) == byteOrderMarker:
+		order = BigEndian
+	default:
+		return Header{}, ErrInvalidByteOrder
+	}
+	reader, err := newOrderedReader(in, order)
+	if err != nil {
+		return Header{}, err
+	}
+	version, err := reader.ReadUint32()
+	if err != nil {
+		return Header{}, err
+	}
+	return Header{
+		Magic:     actualMagic,
+		Version:   version,
+		ByteOrder: order,
+	}, nil
+}
+
+// NewHeaderPackedEncoder writes the specified header to the out Writer and
+// returns a PackedEncoder that uses the byte order of the header.
+func NewHeaderPackedEncoder(out io.Writer, header Header) (*PackedEncoder, error) {
+	if err := WriteHeader(out, header); err != nil {
+		return nil, err
+	}
+	writer, err := newOrderedWriter(out, header.ByteOrder)
+	if err != nil {
+		return nil, err
+	}
+	return &PackedEncoder{
+		out:   writer,
+		order: header.ByteOrder,
+	}, nil
+}
+
+// NewHeaderPackedDecoder reads a header from the in Reader and returns a
+// PackedDecoder that uses the byte order of the header. The header is
+// returned as well, so that the caller can inspect the version.
+func NewHeaderPackedDecoder(in io.Reader, magic []byte) (*PackedDecoder, Header, error) {
+	header, err := ReadHeader(in, magic)
+	if err != nil {
+		return nil, Header{}, err
+	}
+	reader, err := newOrderedReader(in, header.ByteOrder)
+	if err != nil {
+		return nil, Header{}, err
+	}
+	return &PackedDecoder{
+		in:    reader,
+		order: header.ByteOrder,
+	}, header, nil
+}
+
+func newOrderedWriter(out io.Writer, order ByteOrder) (TypedWriter, error) {
+	switch order {
+	case LittleEndian:
+		return NewLittleEndianWriter(out), nil
+	case BigEndian:
+		return NewBigEndianWriter(out), nil
+	default:
+		return nil, fmt.Errorf("unsupported byte order: %v", order)
+	}
+}
+
+func newOrderedReader(in io.Reader, order ByteOrder) (TypedReader, error) {
+	switch order {
+	case LittleEndian:
+		return NewLittleEndianReader(in), nil
+	case BigEndian:
+		return NewBigEndianReader(in), nil
+	default:
+		return nil, fmt.Errorf("unsupported byte order: %v", order)
+	}
+}
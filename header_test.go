@@ -0,0 +1,108 @@
+package gblob_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+)
+
+var _ = Describe("Header", func() {
+	var buffer *bytes.Buffer
+
+	magic := []byte("GBLB")
+
+	BeforeEach(func() {
+		buffer = new(bytes.Buffer)
+	})
+
+	Specify("WriteHeader LittleEndian", func() {
+		Expect(gblob.WriteHeader(buffer, gblob.Header{
+			Magic:     magic,
+			Version:   3,
+			ByteOrder: gblob.LittleEndian,
+		})).To(Succeed())
+		Expect(buffer.Bytes()).To(Equal([]uint8{
+			'G', 'B', 'L', 'B', // magic
+			0xFF, 0xFE, // byte order marker
+			0x03, 0x00, 0x00, 0x00, // version
+		}))
+	})
+
+	Specify("WriteHeader BigEndian", func() {
+		Expect(gblob.WriteHeader(buffer, gblob.Header{
+			Magic:     magic,
+			Version:   3,
+			ByteOrder: gblob.BigEndian,
+		})).To(Succeed())
+		Expect(buffer.Bytes()).To(Equal([]uint8{
+			'G', 'B', 'L', 'B', // magic
+			0xFE, 0xFF, // byte order marker
+			0x00, 0x00, 0x00, 0x03, // version
+		}))
+	})
+
+	Specify("ReadHeader", func() {
+		buffer.Write([]uint8{
+			'G', 'B', 'L', 'B', // magic
+			0xFE, 0xFF, // byte order marker
+			0x00, 0x00, 0x00, 0x07, // version
+		})
+
+		header, err := gblob.ReadHeader(buffer, magic)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(header).To(Equal(gblob.Header{
+			Magic:     magic,
+			Version:   7,
+			ByteOrder: gblob.BigEndian,
+		}))
+	})
+
+	Specify("ReadHeader invalid magic", func() {
+		buffer.Write([]uint8{
+			'G', 'O', 'B', 'B', // magic
+			0xFE, 0xFF, // byte order marker
+			0x00, 0x00, 0x00, 0x07, // version
+		})
+
+		_, err := gblob.ReadHeader(buffer, magic)
+		Expect(err).To(MatchError(gblob.ErrInvalidMagic))
+	})
+
+	Specify("ReadHeader invalid byte order", func() {
+		buffer.Write([]uint8{
+			'G', 'B', 'L', 'B', // magic
+			0xFE, 0xFE, // byte order marker
+			0x00, 0x00, 0x00, 0x07, // version
+		})
+
+		_, err := gblob.ReadHeader(buffer, magic)
+		Expect(err).To(MatchError(gblob.ErrInvalidByteOrder))
+	})
+
+	DescribeTable("round trip",
+		func(order gblob.ByteOrder) {
+			encoder, err := gblob.NewHeaderPackedEncoder(buffer, gblob.Header{
+				Magic:     magic,
+				Version:   2,
+				ByteOrder: order,
+			})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(encoder.Encode(uint32(0xF1CA7632))).To(Succeed())
+
+			decoder, header, err := gblob.NewHeaderPackedDecoder(buffer, magic)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(header.Version).To(Equal(uint32(2)))
+			Expect(header.ByteOrder).To(Equal(order))
+
+			var target uint32
+			Expect(decoder.Decode(&target)).To(Succeed())
+			Expect(target).To(Equal(uint32(0xF1CA7632)))
+		},
+		Entry("LittleEndian", gblob.LittleEndian),
+		Entry("BigEndian", gblob.BigEndian),
+		Entry("NativeEndian", gblob.NativeEndian),
+	)
+})
@@ -0,0 +1,225 @@
+package gblob
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+)
+
+// NewJSONDecoder creates a new JSONDecoder that reads from the specified in
+// Reader.
+func NewJSONDecoder(in io.Reader) *JSONDecoder {
+	decoder := json.NewDecoder(in)
+	decoder.UseNumber()
+	return &JSONDecoder{
+		in: decoder,
+	}
+}
+
+// JSONDecoder parses JSON, as written by a JSONEncoder, back into packed
+// data. This allows one to keep human-editable sources next to binary
+// outputs.
+//
+// Struct fields that are missing from the JSON are set to zero, whereas
+// unknown fields result in an error. Byte data can also be specified as an
+// array of numbers.
+type JSONDecoder struct {
+	in       *json.Decoder
+	hexBytes bool
+}
+
+// SetHexBytes configures whether byte data is expected as hex strings
+// instead of base64 strings.
+func (d *JSONDecoder) SetHexBytes(hexBytes bool) {
+	d.hexBytes = hexBytes
+}
+
+// Decode parses JSON into the specified target, which needs to be a non-nil
+// pointer. The value is converted through its self-describing packed form,
+// so PackedDecodable values are decoded from their Little Endian packed bytes.
+func (d *JSONDecoder) Decode(target any) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return fmt.Errorf("target needs to be a non-nil pointer")
+	}
+	schema, err := SchemaOf(value.Type())
+	if err != nil {
+		return err
+	}
+	tree, err := d.DecodeSchema(schema)
+	if err != nil {
+		return err
+	}
+	var buffer bytes.Buffer
+	encoder := NewLittleEndianPackedEncoder(&buffer)
+	encoder.SetSelfDescribing(true)
+	if err := encoder.EncodeSchema(schema, tree); err != nil {
+		return err
+	}
+	decoder := NewLittleEndianPackedDecoder(&buffer)
+	decoder.SetSelfDescribing(true)
+	return decoder.Decode(target)
+}
+
+// DecodeSchema parses JSON into a generic tree that matches the specified
+// schema and that can be passed to PackedEncoder.EncodeSchema.
+func (d *JSONDecoder) DecodeSchema(schema *Schema) (any, error) {
+	var document any
+	if err := d.in.Decode(&document); err != nil {
+		return nil, err
+	}
+	return d.parseValue(schema, document)
+}
+
+func (d *JSONDecoder) parseValue(schema *Schema, document any) (any, error) {
+	if document == nil {
+		return nil, nil
+	}
+	switch schema.Kind {
+	case KindArray, KindSlice:
+		if schema.Kind == KindSlice && schema.Elem.Kind == KindUint8 {
+			if _, ok := document.(string); ok {
+				return d.parseBytes(schema, document)
+			}
+		}
+		elements, ok := document.([]any)
+		if !ok {
+			return nil, fmt.Errorf("cannot parse %T as %v", document, schema.Kind)
+		}
+		result := make([]any, len(elements))
+		for i, element := range elements {
+			value, err := d.parseValue(schema.Elem, element)
+			if err != nil {
+				return nil, fmt.Errorf("element %d: %w", i, err)
+			}
+			result[i] = value
+		}
+		return result, nil
+	case KindMap:
+		entries, ok := document.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("cannot parse %T as %v", document, schema.Kind)
+		}
+		result := make(map[any]any, len(entries))
+		for name, entry := range entries {
+			key, err := d.parseKey(schema.Key, name)
+			if err != nil {
+				return nil, err
+			}
+			value, err := d.parseValue(schema.Elem, entry)
+			if err != nil {
+				return nil, fmt.Errorf("entry %s: %w", name, err)
+			}
+			result[key] = value
+		}
+		return result, nil
+	case KindStruct:
+		fields, ok := document.(map[string]any)
+		if !ok {
+			return nil, fmt.Errorf("cannot parse %T as %v", document, schema.Kind)
+		}
+		result := make(map[string]any, len(fields))
+		for name := range fields {
+			if !hasSchemaField(schema, name) {
+				return nil, fmt.Errorf("unknown field: %s", name)
+			}
+		}
+		for _, field := range schema.Fields {
+			value, err := d.parseValue(field.Schema, fields[field.Name])
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", field.Name, err)
+			}
+			if value != nil {
+				result[field.Name] = value
+			}
+		}
+		return result, nil
+	case KindCustom:
+		return d.parseBytes(schema, document)
+	default:
+		return parseJSONScalar(schema.Kind, document)
+	}
+}
+
+func (d *JSONDecoder) parseKey(schema *Schema, name string) (any, error) {
+	switch {
+	case schema.Kind == KindString:
+		return name, nil
+	case schema.Kind == KindBool:
+		return strconv.ParseBool(name)
+	case schema.Kind.Size() > 0:
+		return parseJSONScalar(schema.Kind, json.Number(name))
+	default:
+		return nil, fmt.Errorf("unsupported map key kind: %v", schema.Kind)
+	}
+}
+
+func (d *JSONDecoder) parseBytes(schema *Schema, document any) ([]byte, error) {
+	if elements, ok := document.([]any); ok {
+		result := make([]byte, len(elements))
+		for i, element := range elements {
+			value, err := parseJSONScalar(KindUint8, element)
+			if err != nil {
+				return nil, err
+			}
+			result[i] = value.(uint8)
+		}
+		return result, nil
+	}
+	text, ok := document.(string)
+	if !ok {
+		return nil, fmt.Errorf("cannot parse %T as %v", document, schema.Kind)
+	}
+	if d.hexBytes {
+		return hex.DecodeString(text)
+	}
+	return base64.StdEncoding.DecodeString(text)
+}
+
+func parseJSONScalar(kind Kind, document any) (any, error) {
+	var value any
+	switch document := document.(type) {
+	case json.Number:
+		var err error
+		switch kindTypes[kind].Kind() {
+		case reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			value, err = strconv.ParseInt(string(document), 10, 64)
+		case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			value, err = strconv.ParseUint(string(document), 10, 64)
+		default:
+			value, err = strconv.ParseFloat(string(document), 64)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("cannot parse %s as %v: %w", document, kind, err)
+		}
+	case string:
+		switch {
+		case kind == KindFloat32 || kind == KindFloat64:
+			switch document {
+			case "NaN":
+				value = math.NaN()
+			case "+Inf":
+				value = math.Inf(1)
+			case "-Inf":
+				value = math.Inf(-1)
+			default:
+				return nil, fmt.Errorf("cannot parse %q as %v", document, kind)
+			}
+		default:
+			value = document
+		}
+	default:
+		value = document
+	}
+	result, err := treeScalar(kind, value)
+	if err != nil {
+		return nil, err
+	}
+	return result.Interface(), nil
+}
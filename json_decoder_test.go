@@ -0,0 +1,90 @@
+package gblob_test
+
+import (
+	"bytes"
+	"math"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+)
+
+var _ = Describe("JSONDecoder", func() {
+	Specify("Decode", func() {
+		decoder := gblob.NewJSONDecoder(strings.NewReader(`{
+			"Name": "mesh",
+			"Data": [1, 2],
+			"Points": [{"X": 1.5, "Y": "-Inf"}],
+			"Weights": {"10": -1},
+			"Custom": "NBI="
+		}`))
+		var target jsonAsset
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(jsonAsset{
+			Name:    "mesh",
+			Data:    []byte{0x01, 0x02},
+			Points:  []jsonPoint{{X: 1.5, Y: math.Inf(-1)}},
+			Weights: map[uint16]int8{10: -1},
+			Custom:  msgpackCustom{Value: 0x1234},
+			Empty:   []string{},
+		}))
+	})
+
+	Specify("round trip", func() {
+		source := jsonAsset{
+			Name:    "mesh",
+			Visible: true,
+			Data:    []byte{0xCA, 0xFE},
+			Points:  []jsonPoint{{X: 1.5, Y: 0.1}},
+			Weights: map[uint16]int8{10: -1, 9: 2},
+			Custom:  msgpackCustom{Value: 0x1234},
+			Empty:   []string{},
+		}
+		var buffer bytes.Buffer
+		encoder := gblob.NewJSONEncoder(&buffer)
+		encoder.SetHexBytes(true)
+		Expect(encoder.Encode(source)).To(Succeed())
+
+		decoder := gblob.NewJSONDecoder(&buffer)
+		decoder.SetHexBytes(true)
+		var target jsonAsset
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(source))
+	})
+
+	Specify("packed to JSON and back", func() {
+		schema, err := gblob.SchemaFor[jsonPoint]()
+		Expect(err).ToNot(HaveOccurred())
+
+		var packed bytes.Buffer
+		Expect(gblob.NewLittleEndianPackedEncoder(&packed).Encode(jsonPoint{X: 2, Y: 3})).To(Succeed())
+		original := bytes.Clone(packed.Bytes())
+
+		tree, err := gblob.NewLittleEndianPackedDecoder(&packed).DecodeSchema(schema)
+		Expect(err).ToNot(HaveOccurred())
+		var text bytes.Buffer
+		Expect(gblob.NewJSONEncoder(&text).EncodeSchema(schema, tree)).To(Succeed())
+
+		tree, err = gblob.NewJSONDecoder(&text).DecodeSchema(schema)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(gblob.NewLittleEndianPackedEncoder(&packed).EncodeSchema(schema, tree)).To(Succeed())
+		Expect(packed.Bytes()).To(Equal(original))
+	})
+
+	DescribeTable("errors",
+		func(document string) {
+			decoder := gblob.NewJSONDecoder(strings.NewReader(document))
+			var target jsonAsset
+			Expect(decoder.Decode(&target)).ToNot(Succeed())
+		},
+		Entry("unknown field", `{"Unknown": 1}`),
+		Entry("fraction", `{"Weights": {"1": 1.5}}`),
+		Entry("overflow", `{"Weights": {"1": 200}}`),
+		Entry("invalid key", `{"Weights": {"a": 1}}`),
+		Entry("invalid bytes", `{"Data": "!"}`),
+		Entry("kind mismatch", `{"Name": 1}`),
+		Entry("syntax", `{`),
+	)
+})
@@ -0,0 +1,337 @@
+package gblob
+
+import (
+	"bytes"
+	"cmp"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// NewJSONEncoder creates a new JSONEncoder that writes to the specified out
+// Writer.
+func NewJSONEncoder(out io.Writer) *JSONEncoder {
+	return &JSONEncoder{
+		out:            out,
+		indent:         "  ",
+		sortKeys:       true,
+		floatFormat:    'g',
+		floatPrecision: -1,
+	}
+}
+
+// JSONEncoder renders packed data as JSON, which is useful for reviewing
+// and diffing binary assets.
+//
+// The output is driven by a Schema. Structs are rendered as objects with
+// their fields in schema order and maps as objects with their keys rendered
+// as strings. Slices of KindUint8 and values of KindCustom are rendered as
+// base64 strings, or as hex strings if configured through SetHexBytes.
+// Floating-point values that are not finite are rendered as the strings
+// "NaN", "+Inf" and "-Inf".
+type JSONEncoder struct {
+	out            io.Writer
+	indent         string
+	hexBytes       bool
+	sortKeys       bool
+	floatFormat    byte
+	floatPrecision int
+}
+
+// SetIndent configures the string that is used for each level of
+// indentation. An empty string results in compact output. The default is two
+// spaces.
+func (e *JSONEncoder) SetIndent(indent string) {
+	e.indent = indent
+}
+
+// SetHexBytes configures whether byte data is rendered as hex strings
+// instead of base64 strings.
+func (e *JSONEncoder) SetHexBytes(hexBytes bool) {
+	e.hexBytes = hexBytes
+}
+
+// SetSortKeys configures whether map keys are sorted, which is the default.
+// Struct fields always retain their schema order.
+func (e *JSONEncoder) SetSortKeys(sortKeys bool) {
+	e.sortKeys = sortKeys
+}
+
+// SetFloatFormat configures how floating-point values are rendered. The
+// format and precision have the same meaning as in strconv.FormatFloat. The
+// default is 'g' with a precision of -1, which is the shortest representation
+// that preserves the value.
+func (e *JSONEncoder) SetFloatFormat(format byte, precision int) {
+	e.floatFormat = format
+	e.floatPrecision = precision
+}
+
+// Encode renders the specified Go value as JSON. The value is first packed in
+// self-describing mode, so PackedEncodable values are rendered as their Little
+// Endian packed bytes.
+func (e *JSONEncoder) Encode(source any) error {
+	schema, err := SchemaOf(reflect.TypeOf(source))
+	if err != nil {
+		return err
+	}
+	var buffer bytes.Buffer
+	encoder := NewLittleEndianPackedEncoder(&buffer)
+	encoder.SetSelfDescribing(true)
+	if err := encoder.Encode(source); err != nil {
+		return err
+	}
+	decoder := NewLittleEndianPackedDecoder(&buffer)
+	decoder.SetSelfDescribing(true)
+	tree, err := decoder.DecodeSchema(nil)
+	if err != nil {
+		return err
+	}
+	return e.EncodeSchema(schema, tree)
+}
+
+// EncodeSchema renders the specified generic tree, as returned by
+// PackedDecoder.DecodeSchema, as JSON.
+func (e *JSONEncoder) EncodeSchema(schema *Schema, tree any) error {
+	var buffer bytes.Buffer
+	if err := e.renderValue(&buffer, schema, tree, 0); err != nil {
+		return err
+	}
+	buffer.WriteByte('\n')
+	_, err := e.out.Write(buffer.Bytes())
+	return err
+}
+
+func (e *JSONEncoder) renderValue(out *bytes.Buffer, schema *Schema, tree any, depth int) error {
+	switch schema.Kind {
+	case KindArray, KindSlice:
+		if schema.Kind == KindSlice && schema.Elem.Kind == KindUint8 {
+			return e.renderBytes(out, schema, tree)
+		}
+		var elements []any
+		if tree != nil {
+			value := reflect.ValueOf(tree)
+			if kind := value.Kind(); kind != reflect.Slice && kind != reflect.Array {
+				return fmt.Errorf("cannot render %T as %v", tree, schema.Kind)
+			}
+			elements = make([]any, value.Len())
+			for i := range elements {
+				elements[i] = value.Index(i).Interface()
+			}
+		}
+		out.WriteByte('[')
+		for i, element := range elements {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			e.renderNewline(out, depth+1)
+			if err := e.renderValue(out, schema.Elem, element, depth+1); err != nil {
+				return err
+			}
+		}
+		if len(elements) > 0 {
+			e.renderNewline(out, depth)
+		}
+		out.WriteByte(']')
+		return nil
+	case KindMap:
+		type entry struct {
+			key   any
+			name  string
+			value any
+		}
+		var entries []entry
+		if tree != nil {
+			value := reflect.ValueOf(tree)
+			if value.Kind() != reflect.Map {
+				return fmt.Errorf("cannot render %T as %v", tree, schema.Kind)
+			}
+			iterator := value.MapRange()
+			for iterator.Next() {
+				key := iterator.Key().Interface()
+				name, err := e.renderKey(schema.Key, key)
+				if err != nil {
+					return err
+				}
+				entries = append(entries, entry{
+					key:   key,
+					name:  name,
+					value: iterator.Value().Interface(),
+				})
+			}
+		}
+		if e.sortKeys {
+			slices.SortFunc(entries, func(a, b entry) int {
+				return compareTreeKeys(a.key, b.key)
+			})
+		}
+		out.WriteByte('{')
+		for i, entry := range entries {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			e.renderNewline(out, depth+1)
+			e.renderString(out, entry.name)
+			e.renderColon(out)
+			if err := e.renderValue(out, schema.Elem, entry.value, depth+1); err != nil {
+				return err
+			}
+		}
+		if len(entries) > 0 {
+			e.renderNewline(out, depth)
+		}
+		out.WriteByte('}')
+		return nil
+	case KindStruct:
+		fields, ok := tree.(map[string]any)
+		if !ok && tree != nil {
+			return fmt.Errorf("cannot render %T as %v", tree, schema.Kind)
+		}
+		out.WriteByte('{')
+		for i, field := range schema.Fields {
+			if i > 0 {
+				out.WriteByte(',')
+			}
+			e.renderNewline(out, depth+1)
+			e.renderString(out, field.Name)
+			e.renderColon(out)
+			if err := e.renderValue(out, field.Schema, fields[field.Name], depth+1); err != nil {
+				return fmt.Errorf("field %s: %w", field.Name, err)
+			}
+		}
+		if len(schema.Fields) > 0 {
+			e.renderNewline(out, depth)
+		}
+		out.WriteByte('}')
+		return nil
+	case KindCustom:
+		return e.renderBytes(out, schema, tree)
+	default:
+		value, err := treeScalar(schema.Kind, tree)
+		if err != nil {
+			return err
+		}
+		switch {
+		case value.Kind() == reflect.String:
+			e.renderString(out, value.String())
+		case value.CanFloat():
+			out.WriteString(e.formatFloat(value.Float(), value.Type().Bits()))
+		default:
+			out.WriteString(formatTreeScalar(value))
+		}
+		return nil
+	}
+}
+
+func (e *JSONEncoder) renderKey(schema *Schema, key any) (string, error) {
+	if schema.Kind.Size() == 0 && schema.Kind != KindString {
+		return "", fmt.Errorf("unsupported map key kind: %v", schema.Kind)
+	}
+	value, err := treeScalar(schema.Kind, key)
+	if err != nil {
+		return "", err
+	}
+	if value.CanFloat() {
+		return e.formatFloat(value.Float(), value.Type().Bits()), nil
+	}
+	return formatTreeScalar(value), nil
+}
+
+func (e *JSONEncoder) renderBytes(out *bytes.Buffer, schema *Schema, tree any) error {
+	data, ok := tree.([]byte)
+	if !ok && tree != nil {
+		return fmt.Errorf("cannot render %T as %v", tree, schema.Kind)
+	}
+	if e.hexBytes {
+		e.renderString(out, hex.EncodeToString(data))
+	} else {
+		e.renderString(out, base64.StdEncoding.EncodeToString(data))
+	}
+	return nil
+}
+
+func (e *JSONEncoder) renderString(out *bytes.Buffer, value string) {
+	encoder := json.NewEncoder(out)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(value)       // writing to a bytes.Buffer cannot fail
+	out.Truncate(out.Len() - 1) // remove the trailing newline
+}
+
+func (e *JSONEncoder) renderColon(out *bytes.Buffer) {
+	if e.indent == "" {
+		out.WriteByte(':')
+	} else {
+		out.WriteString(": ")
+	}
+}
+
+func (e *JSONEncoder) renderNewline(out *bytes.Buffer, depth int) {
+	if e.indent == "" {
+		return
+	}
+	out.WriteByte('\n')
+	out.WriteString(strings.Repeat(e.indent, depth))
+}
+
+func (e *JSONEncoder) formatFloat(value float64, bits int) string {
+	switch {
+	case math.IsNaN(value):
+		return `"NaN"`
+	case math.IsInf(value, 1):
+		return `"+Inf"`
+	case math.IsInf(value, -1):
+		return `"-Inf"`
+	default:
+		return strconv.FormatFloat(value, e.floatFormat, e.floatPrecision, bits)
+	}
+}
+
+func formatTreeScalar(value reflect.Value) string {
+	switch {
+	case value.Kind() == reflect.Bool:
+		return strconv.FormatBool(value.Bool())
+	case value.Kind() == reflect.String:
+		return value.String()
+	case value.CanInt():
+		return strconv.FormatInt(value.Int(), 10)
+	case value.CanUint():
+		return strconv.FormatUint(value.Uint(), 10)
+	default:
+		return strconv.FormatFloat(value.Float(), 'g', -1, value.Type().Bits())
+	}
+}
+
+// compareTreeKeys orders scalar tree values of the same kind by their
+// natural order.
+func compareTreeKeys(a, b any) int {
+	valueA, valueB := reflect.ValueOf(a), reflect.ValueOf(b)
+	switch {
+	case valueA.Kind() != valueB.Kind():
+		return cmp.Compare(valueA.Kind(), valueB.Kind())
+	case valueA.Kind() == reflect.Bool:
+		return cmp.Compare(boolOrder(valueA.Bool()), boolOrder(valueB.Bool()))
+	case valueA.Kind() == reflect.String:
+		return strings.Compare(valueA.String(), valueB.String())
+	case valueA.CanInt():
+		return cmp.Compare(valueA.Int(), valueB.Int())
+	case valueA.CanUint():
+		return cmp.Compare(valueA.Uint(), valueB.Uint())
+	case valueA.CanFloat():
+		return cmp.Compare(valueA.Float(), valueB.Float())
+	default:
+		return 0
+	}
+}
+
+func boolOrder(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,108 @@
+package gblob_test
+
+import (
+	"bytes"
+	"math"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+)
+
+type jsonPoint struct {
+	X float32
+	Y float64
+}
+
+type jsonAsset struct {
+	Name    string
+	Visible bool
+	Data    []byte
+	Points  []jsonPoint
+	Weights map[uint16]int8
+	Custom  msgpackCustom
+	Empty   []string
+}
+
+var _ = Describe("JSONEncoder", func() {
+	var (
+		buffer  *bytes.Buffer
+		encoder *gblob.JSONEncoder
+		asset   jsonAsset
+	)
+
+	BeforeEach(func() {
+		buffer = new(bytes.Buffer)
+		encoder = gblob.NewJSONEncoder(buffer)
+		asset = jsonAsset{
+			Name:    "<mesh>",
+			Visible: true,
+			Data:    []byte{0xCA, 0xFE},
+			Points: []jsonPoint{
+				{X: 1.5, Y: 0.1},
+				{X: float32(math.Inf(1)), Y: math.NaN()},
+			},
+			Weights: map[uint16]int8{10: -1, 9: 2, 100: 3},
+			Custom:  msgpackCustom{Value: 0x1234},
+		}
+	})
+
+	Specify("Encode", func() {
+		Expect(encoder.Encode(asset)).To(Succeed())
+		Expect(buffer.String()).To(Equal(`{
+  "Name": "<mesh>",
+  "Visible": true,
+  "Data": "yv4=",
+  "Points": [
+    {
+      "X": 1.5,
+      "Y": 0.1
+    },
+    {
+      "X": "+Inf",
+      "Y": "NaN"
+    }
+  ],
+  "Weights": {
+    "9": 2,
+    "10": -1,
+    "100": 3
+  },
+  "Custom": "NBI=",
+  "Empty": []
+}
+`))
+	})
+
+	Specify("compact output with options", func() {
+		encoder.SetIndent("")
+		encoder.SetHexBytes(true)
+		encoder.SetFloatFormat('f', 2)
+		Expect(encoder.Encode(asset)).To(Succeed())
+		Expect(buffer.String()).To(Equal(
+			`{"Name":"<mesh>","Visible":true,"Data":"cafe","Points":[{"X":1.50,"Y":0.10},{"X":"+Inf","Y":"NaN"}],` +
+				`"Weights":{"9":2,"10":-1,"100":3},"Custom":"3412","Empty":[]}` + "\n",
+		))
+	})
+
+	Specify("EncodeSchema", func() {
+		schema := &gblob.Schema{
+			Kind: gblob.KindMap,
+			Key:  &gblob.Schema{Kind: gblob.KindString},
+			Elem: &gblob.Schema{Kind: gblob.KindUint8},
+		}
+		encoder.SetIndent("\t")
+		Expect(encoder.EncodeSchema(schema, map[any]any{"b": uint8(2), "a": uint8(1)})).To(Succeed())
+		Expect(buffer.String()).To(Equal("{\n\t\"a\": 1,\n\t\"b\": 2\n}\n"))
+	})
+
+	Specify("unsupported map keys", func() {
+		schema := &gblob.Schema{
+			Kind: gblob.KindMap,
+			Key:  &gblob.Schema{Kind: gblob.KindSlice, Elem: &gblob.Schema{Kind: gblob.KindUint16}},
+			Elem: &gblob.Schema{Kind: gblob.KindUint8},
+		}
+		Expect(encoder.EncodeSchema(schema, map[any]any{"a": uint8(1)})).ToNot(Succeed())
+	})
+})
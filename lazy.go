@@ -0,0 +1,312 @@
+package gblob
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+)
+
+var (
+	lazyEncodableType = reflect.TypeFor[lazyEncodable]()
+	lazyDecodableType = reflect.TypeFor[lazyDecodable]()
+)
+
+// lazyEncodable is implemented by types that write their own length-prefixed
+// packed form, such as Lazy.
+type lazyEncodable interface {
+	encodeLazy(e *PackedEncoder) error
+
+	// loadedValue returns the value, for encoders that store it inline.
+	loadedValue() (any, error)
+}
+
+// lazyDecodable is implemented by types that can defer the decoding of their
+// length-prefixed packed form, such as Lazy.
+type lazyDecodable interface {
+	decodeLazy(d *PackedDecoder) error
+
+	// decodeEager decodes the value through the specified decode function,
+	// for decoders that store it inline.
+	decodeEager(decode func(target any) error) error
+}
+
+// NewLazy returns a Lazy that holds the specified value.
+func NewLazy[T any](value T) Lazy[T] {
+	return Lazy[T]{
+		value: value,
+	}
+}
+
+// Lazy holds a value that is encoded with its uint64 byte length upfront.
+// When decoding, a PackedDecoder only records the position of the value and
+// skips it, so that it can be decoded later on demand through Load or
+// LoadFrom.
+//
+// In self-describing mode, a Lazy is described as KindCustom.
+type Lazy[T any] struct {
+	value   T
+	pending bool
+	order   ByteOrder
+	data    []byte
+	offset  int64
+	size    int64
+}
+
+var _ lazyEncodable = Lazy[int]{}
+var _ lazyDecodable = (*Lazy[int])(nil)
+
+// Set replaces the value that is held.
+func (l *Lazy[T]) Set(value T) {
+	*l = NewLazy(value)
+}
+
+// Loaded returns whether the value is available without decoding.
+func (l Lazy[T]) Loaded() bool {
+	return !l.pending
+}
+
+// Offset returns the position of the packed value, relative to the start of
+// the input of the PackedDecoder that decoded the Lazy.
+func (l Lazy[T]) Offset() int64 {
+	return l.offset
+}
+
+// Size returns the number of bytes that the packed value occupies.
+func (l Lazy[T]) Size() int64 {
+	return l.size
+}
+
+// Load returns the value, decoding it if it has not been loaded yet.
+//
+// Decoding is only possible if the Lazy was decoded by a PackedDecoder that
+// reads from a data buffer, in which case the Lazy references that buffer
+// until it is loaded. Otherwise, LoadFrom needs to be used.
+func (l *Lazy[T]) Load() (T, error) {
+	if !l.pending {
+		return l.value, nil
+	}
+	if l.data == nil {
+		var zero T
+		return zero, fmt.Errorf("lazy value at offset %d needs to be loaded from its source", l.offset)
+	}
+	return l.load(l.data)
+}
+
+// LoadFrom returns the value, decoding it from the specified in ReaderAt if
+// it has not been loaded yet. Position zero of the ReaderAt needs to match
+// the start of the input of the PackedDecoder that decoded the Lazy.
+func (l *Lazy[T]) LoadFrom(in io.ReaderAt) (T, error) {
+	if !l.pending {
+		return l.value, nil
+	}
+	// The size comes from the input, so the data is read in chunks, in case
+	// the source is shorter than the size claims.
+	section := io.NewSectionReader(in, l.offset, l.size)
+	data, err := readChunked(NewLittleEndianReader(section), int(l.size))
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return l.load(data)
+}
+
+func (l *Lazy[T]) load(data []byte) (T, error) {
+	var value T
+	reader, err := newOrderedBufferReader(data, l.order)
+	if err != nil {
+		return value, err
+	}
+	decoder := &PackedDecoder{
+		in:    reader,
+		order: l.order,
+	}
+	if err := decoder.Decode(&value); err != nil {
+		return value, err
+	}
+	l.Set(value)
+	return value, nil
+}
+
+func (l Lazy[T]) loadedValue() (any, error) {
+	return l.Load()
+}
+
+func (l *Lazy[T]) decodeEager(decode func(target any) error) error {
+	var value T
+	if err := decode(&value); err != nil {
+		return err
+	}
+	l.Set(value)
+	return nil
+}
+
+func (l Lazy[T]) encodeLazy(e *PackedEncoder) error {
+	if l.pending {
+		if l.data == nil {
+			return fmt.Errorf("cannot encode lazy value at offset %d that has not been loaded", l.offset)
+		}
+		if l.order == e.order {
+			if err := e.out.WriteUint64(uint64(len(l.data))); err != nil {
+				return err
+			}
+			return e.out.WriteBytes(l.data)
+		}
+		if _, err := l.Load(); err != nil {
+			return err
+		}
+	}
+	var buffer bytes.Buffer
+	writer, err := newOrderedWriter(&buffer, e.order)
+	if err != nil {
+		return err
+	}
+	encoder := &PackedEncoder{
+		out:   writer,
+		order: e.order,
+		ctx:   e.ctx,
+	}
+	if err := encoder.Encode(l.value); err != nil {
+		return err
+	}
+	if err := e.out.WriteUint64(uint64(buffer.Len())); err != nil {
+		return err
+	}
+	return e.out.WriteBytes(buffer.Bytes())
+}
+
+func (l *Lazy[T]) decodeLazy(d *PackedDecoder) error {
+	size, err := d.in.ReadUint64()
+	if err != nil {
+		return err
+	}
+	if size > math.MaxInt {
+		return fmt.Errorf("lazy value size %d exceeds maximum: %w", size, io.ErrUnexpectedEOF)
+	}
+	offset, err := d.position()
+	if err != nil {
+		return fmt.Errorf("cannot decode lazy value: %w", err)
+	}
+	*l = Lazy[T]{
+		pending: true,
+		order:   d.order,
+		offset:  offset,
+		size:    int64(size),
+	}
+	if reader, ok := d.in.(sliceReader); ok {
+		l.data, err = reader.readSlice(int(size))
+		return eofAsUnexpected(err)
+	}
+	return eofAsUnexpected(d.in.SkipBytes(int(size)))
+}
+
+// NewLazySlice returns a LazySlice that holds the specified elements.
+func NewLazySlice[T any](values []T) LazySlice[T] {
+	return LazySlice[T]{
+		Lazy:  NewLazy(values),
+		count: len(values),
+	}
+}
+
+// LazySlice is a Lazy that holds a slice, whose length is available without
+// decoding the elements.
+type LazySlice[T any] struct {
+	Lazy[[]T]
+	count int
+}
+
+var _ lazyEncodable = LazySlice[int]{}
+var _ lazyDecodable = (*LazySlice[int])(nil)
+
+// Set replaces the elements that are held.
+func (l *LazySlice[T]) Set(values []T) {
+	*l = NewLazySlice(values)
+}
+
+// Len returns the number of elements.
+func (l LazySlice[T]) Len() int {
+	if !l.pending {
+		return len(l.value)
+	}
+	return l.count
+}
+
+func (l *LazySlice[T]) decodeEager(decode func(target any) error) error {
+	var values []T
+	if err := decode(&values); err != nil {
+		return err
+	}
+	l.Set(values)
+	return nil
+}
+
+func (l *LazySlice[T]) decodeLazy(d *PackedDecoder) error {
+	if _, ok := d.in.(sliceReader); ok {
+		if err := l.Lazy.decodeLazy(d); err != nil {
+			return err
+		}
+		reader, err := newOrderedBufferReader(l.data, l.order)
+		if err != nil {
+			return err
+		}
+		count, err := reader.ReadUint64()
+		if err != nil {
+			return fmt.Errorf("lazy slice at offset %d: %w", l.offset, err)
+		}
+		if count > math.MaxInt {
+			return fmt.Errorf("lazy slice at offset %d has invalid length %d", l.offset, count)
+		}
+		l.count = int(count)
+		return nil
+	}
+	// The data is not retained, so the length is read before the elements
+	// are skipped.
+	size, err := d.in.ReadUint64()
+	if err != nil {
+		return err
+	}
+	if size > math.MaxInt {
+		return fmt.Errorf("lazy slice size %d exceeds maximum: %w", size, io.ErrUnexpectedEOF)
+	}
+	offset, err := d.position()
+	if err != nil {
+		return fmt.Errorf("cannot decode lazy slice: %w", err)
+	}
+	if size < 8 {
+		return fmt.Errorf("lazy slice at offset %d is too small", offset)
+	}
+	count, err := d.in.ReadUint64()
+	if err != nil {
+		return eofAsUnexpected(err)
+	}
+	if count > math.MaxInt {
+		return fmt.Errorf("lazy slice at offset %d has invalid length %d", offset, count)
+	}
+	*l = LazySlice[T]{
+		Lazy: Lazy[[]T]{
+			pending: true,
+			order:   d.order,
+			offset:  offset,
+			size:    int64(size),
+		},
+		count: int(count),
+	}
+	return eofAsUnexpected(d.in.SkipBytes(int(size) - 8))
+}
+
+// asLazyDecodable returns the lazyDecodable implementation of the specified
+// value, allocating it if it is a nil pointer.
+func asLazyDecodable(value reflect.Value) (lazyDecodable, bool) {
+	switch {
+	case value.Kind() == reflect.Struct && value.CanAddr() && value.Addr().Type().Implements(lazyDecodableType):
+		return value.Addr().Interface().(lazyDecodable), true
+	case value.Kind() == reflect.Pointer && value.Type().Implements(lazyDecodableType):
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return value.Interface().(lazyDecodable), true
+	default:
+		return nil, false
+	}
+}
@@ -0,0 +1,198 @@
+package gblob_test
+
+import (
+	"bytes"
+	"io"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+)
+
+var _ = Describe("Lazy", func() {
+	type vertex struct {
+		X, Y, Z float32
+	}
+
+	type scene struct {
+		Name     string
+		Vertices gblob.LazySlice[vertex]
+		Meta     gblob.Lazy[map[string]string]
+		Version  uint8
+	}
+
+	var (
+		buffer *bytes.Buffer
+		source scene
+	)
+
+	BeforeEach(func() {
+		buffer = new(bytes.Buffer)
+		source = scene{
+			Name: "level",
+			Vertices: gblob.NewLazySlice([]vertex{
+				{X: 1, Y: 2, Z: 3},
+				{X: 4, Y: 5, Z: 6},
+			}),
+			Meta: gblob.NewLazy(map[string]string{
+				"author": "someone",
+			}),
+			Version: 7,
+		}
+	})
+
+	It("is encoded with its byte length upfront", func() {
+		Expect(gblob.NewLittleEndianPackedEncoder(buffer).Encode(struct {
+			Value gblob.Lazy[uint16]
+		}{
+			Value: gblob.NewLazy(uint16(0x1234)),
+		})).To(Succeed())
+		Expect(buffer.Bytes()).To(Equal([]byte{
+			0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // length
+			0x34, 0x12, // value
+		}))
+	})
+
+	When("decoded from a buffer", func() {
+		var target scene
+
+		BeforeEach(func() {
+			Expect(gblob.NewLittleEndianPackedEncoder(buffer).Encode(source)).To(Succeed())
+			decoder := gblob.NewLittleEndianBufferPackedDecoder(buffer.Bytes())
+			Expect(decoder.Decode(&target)).To(Succeed())
+		})
+
+		It("decodes the surrounding fields", func() {
+			Expect(target.Name).To(Equal("level"))
+			Expect(target.Version).To(Equal(uint8(7)))
+			Expect(target.Vertices.Loaded()).To(BeFalse())
+			Expect(target.Vertices.Len()).To(Equal(2))
+			Expect(target.Vertices.Offset()).To(Equal(int64(8 + 5 + 8)))
+			Expect(target.Vertices.Size()).To(Equal(int64(8 + 2*12)))
+		})
+
+		It("decodes the values on demand", func() {
+			vertices, err := target.Vertices.Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(vertices).To(Equal([]vertex{
+				{X: 1, Y: 2, Z: 3},
+				{X: 4, Y: 5, Z: 6},
+			}))
+			Expect(target.Vertices.Loaded()).To(BeTrue())
+
+			meta, err := target.Meta.Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(meta).To(Equal(map[string]string{
+				"author": "someone",
+			}))
+		})
+
+		It("can be encoded again without loading", func() {
+			var output bytes.Buffer
+			Expect(gblob.NewLittleEndianPackedEncoder(&output).Encode(target)).To(Succeed())
+			Expect(output.Bytes()).To(Equal(buffer.Bytes()))
+		})
+	})
+
+	When("decoded from a stream", func() {
+		var target scene
+
+		BeforeEach(func() {
+			Expect(gblob.NewBigEndianPackedEncoder(buffer).Encode(source)).To(Succeed())
+			decoder := gblob.NewBigEndianPackedDecoder(bytes.NewBuffer(buffer.Bytes()))
+			Expect(decoder.Decode(&target)).To(Succeed())
+		})
+
+		It("skips the values", func() {
+			Expect(target.Name).To(Equal("level"))
+			Expect(target.Version).To(Equal(uint8(7)))
+			Expect(target.Vertices.Len()).To(Equal(2))
+
+			_, err := target.Vertices.Load()
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("decodes the values from a ReaderAt", func() {
+			vertices, err := target.Vertices.LoadFrom(bytes.NewReader(buffer.Bytes()))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(vertices).To(HaveLen(2))
+			Expect(vertices[1]).To(Equal(vertex{X: 4, Y: 5, Z: 6}))
+
+			meta, err := target.Meta.LoadFrom(bytes.NewReader(buffer.Bytes()))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(meta).To(HaveKeyWithValue("author", "someone"))
+		})
+
+		It("fails to decode the values from a shorter ReaderAt", func() {
+			data := buffer.Bytes()[:target.Meta.Offset()+1]
+			_, err := target.Meta.LoadFrom(bytes.NewReader(data))
+			Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+		})
+
+		It("fails to decode a value with a corrupted size", func() {
+			type record struct {
+				Data gblob.Lazy[string]
+			}
+			var output bytes.Buffer
+			Expect(gblob.NewLittleEndianPackedEncoder(&output).Encode(record{
+				Data: gblob.NewLazy("payload"),
+			})).To(Succeed())
+			data := output.Bytes()
+			data[6] = 0xFF // size of roughly 2^56 bytes
+
+			var corrupted record
+			Expect(gblob.NewLittleEndianPackedDecoder(bytes.NewReader(data)).Decode(&corrupted)).To(Succeed())
+			_, err := corrupted.Data.LoadFrom(bytes.NewReader(data))
+			Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+		})
+	})
+
+	It("is supported in self-describing mode", func() {
+		encoder := gblob.NewLittleEndianPackedEncoder(buffer)
+		encoder.SetSelfDescribing(true)
+		Expect(encoder.Encode(source)).To(Succeed())
+
+		decoder := gblob.NewLittleEndianBufferPackedDecoder(buffer.Bytes())
+		decoder.SetSelfDescribing(true)
+		var target struct {
+			Version  uint8
+			Vertices *gblob.LazySlice[vertex]
+		}
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target.Version).To(Equal(uint8(7)))
+		Expect(target.Vertices.Len()).To(Equal(2))
+
+		vertices, err := target.Vertices.Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(vertices[0]).To(Equal(vertex{X: 1, Y: 2, Z: 3}))
+	})
+
+	DescribeTable("corrupted size",
+		func(data []byte) {
+			var value struct {
+				Value gblob.Lazy[uint16]
+			}
+			Expect(gblob.NewLittleEndianPackedDecoder(bytes.NewBuffer(data)).Decode(&value)).To(MatchError(io.ErrUnexpectedEOF))
+			Expect(gblob.NewLittleEndianBufferPackedDecoder(data).Decode(&value)).To(MatchError(io.ErrUnexpectedEOF))
+
+			var slice struct {
+				Values gblob.LazySlice[uint16]
+			}
+			Expect(gblob.NewLittleEndianPackedDecoder(bytes.NewBuffer(data)).Decode(&slice)).To(MatchError(io.ErrUnexpectedEOF))
+			Expect(gblob.NewLittleEndianBufferPackedDecoder(data).Decode(&slice)).To(MatchError(io.ErrUnexpectedEOF))
+		},
+		Entry("beyond maximum", []byte{
+			0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, // length
+			0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // count
+		}),
+		Entry("beyond input", []byte{
+			0x0C, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // length
+			0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // count
+			0x01, 0x00, // partial value
+		}),
+		Entry("without value", []byte{
+			0x0C, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // length
+		}),
+	)
+})
@@ -0,0 +1,132 @@
+package gblob
+
+import (
+	"fmt"
+	"reflect"
+)
+
+var (
+	versionedType = reflect.TypeFor[PackedVersioned]()
+)
+
+// PackedVersioned is an interface that can be implemented by types that
+// want to be stored with a version tag.
+//
+// The PackedEncoder writes the version as a uint32 before the value. When
+// the PackedDecoder reads an older version, it uses the Migrations that it
+// is configured with to decode the value into an older Go type and upgrade
+// it to the current one. The MsgpackEncoder and MsgpackDecoder handle such
+// types in the same way, storing the version alongside the value.
+//
+// Version tags are not written in self-describing mode.
+type PackedVersioned interface {
+
+	// PackedVersion returns the current version of the type. It is called
+	// on the zero value of the type.
+	PackedVersion() uint32
+}
+
+// NewMigrations creates a new empty set of migrations.
+func NewMigrations() *Migrations {
+	return &Migrations{
+		byVersion: make(map[uint32][]*migration),
+		byFrom:    make(map[reflect.Type]*migration),
+	}
+}
+
+// Migrations holds upgrade functions that convert values of old Go types
+// to newer ones.
+type Migrations struct {
+	byVersion map[uint32][]*migration
+	byFrom    map[reflect.Type]*migration
+}
+
+type migration struct {
+	from    reflect.Type
+	to      reflect.Type
+	upgrade func(any) (any, error)
+}
+
+// RegisterMigration registers an upgrade function that converts a value of
+// type From, which represents the specified stored version, to a value of
+// type To. The type To can either be the current type or an older type for
+// which another migration is registered, forming a chain.
+//
+// The type From should not implement PackedVersioned.
+func RegisterMigration[From, To any](migrations *Migrations, version uint32, upgrade func(From) (To, error)) {
+	entry := &migration{
+		from: reflect.TypeFor[From](),
+		to:   reflect.TypeFor[To](),
+		upgrade: func(value any) (any, error) {
+			return upgrade(value.(From))
+		},
+	}
+	migrations.byVersion[version] = append(migrations.byVersion[version], entry)
+	migrations.byFrom[entry.from] = entry
+}
+
+// chain returns the sequence of migrations that convert a value stored with
+// the specified version to the specified target type.
+func (m *Migrations) chain(version uint32, target reflect.Type) []*migration {
+	if m == nil {
+		return nil
+	}
+	for _, first := range m.byVersion[version] {
+		result := []*migration{first}
+		for current := first; current.to != target; {
+			next, ok := m.byFrom[current.to]
+			if !ok || len(result) > len(m.byFrom) {
+				result = nil
+				break
+			}
+			result = append(result, next)
+			current = next
+		}
+		if result != nil {
+			return result
+		}
+	}
+	return nil
+}
+
+func (d *PackedDecoder) decodeVersionedValue(value reflect.Value) error {
+	version, err := d.in.ReadUint32()
+	if err != nil {
+		return err
+	}
+	if version == versionOf(value.Type()) {
+		return d.decodeKindValue(value)
+	}
+	return d.migrations.decodeMigrated(version, value, d.decodeKindValue)
+}
+
+// decodeMigrated decodes a value that was stored with the specified older
+// version through the specified decode function and upgrades it to the type
+// of the specified value.
+func (m *Migrations) decodeMigrated(version uint32, value reflect.Value, decode func(reflect.Value) error) error {
+	chain := m.chain(version, value.Type())
+	if chain == nil {
+		return fmt.Errorf("no migration from version %d to %v", version, value.Type())
+	}
+	old := reflect.New(chain[0].from).Elem()
+	if err := decode(old); err != nil {
+		return err
+	}
+	result := old.Interface()
+	for _, entry := range chain {
+		var err error
+		if result, err = entry.upgrade(result); err != nil {
+			return err
+		}
+	}
+	value.Set(reflect.ValueOf(result))
+	return nil
+}
+
+func isVersioned(t reflect.Type) bool {
+	return t.Implements(versionedType) || reflect.PointerTo(t).Implements(versionedType)
+}
+
+func versionOf(t reflect.Type) uint32 {
+	return reflect.New(t).Interface().(PackedVersioned).PackedVersion()
+}
@@ -0,0 +1,149 @@
+package gblob_test
+
+import (
+	"bytes"
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+)
+
+type saveGameV1 struct {
+	Name  string
+	Score uint16
+}
+
+type saveGameV2 struct {
+	Name  string
+	Score uint32
+}
+
+type saveGame struct {
+	Name   string
+	Score  uint32
+	Levels []uint8
+}
+
+func (saveGame) PackedVersion() uint32 {
+	return 3
+}
+
+var _ = Describe("Migrations", func() {
+	var (
+		buffer     *bytes.Buffer
+		decoder    *gblob.PackedDecoder
+		migrations *gblob.Migrations
+	)
+
+	BeforeEach(func() {
+		buffer = new(bytes.Buffer)
+		decoder = gblob.NewLittleEndianPackedDecoder(buffer)
+		migrations = gblob.NewMigrations()
+		gblob.RegisterMigration(migrations, 1, func(old saveGameV1) (saveGameV2, error) {
+			return saveGameV2{
+				Name:  old.Name,
+				Score: uint32(old.Score) * 10,
+			}, nil
+		})
+		gblob.RegisterMigration(migrations, 2, func(old saveGameV2) (saveGame, error) {
+			return saveGame{
+				Name:   old.Name,
+				Score:  old.Score,
+				Levels: []uint8{1},
+			}, nil
+		})
+		decoder.SetMigrations(migrations)
+	})
+
+	Specify("encoding writes version", func() {
+		encoder := gblob.NewLittleEndianPackedEncoder(buffer)
+		Expect(encoder.Encode(&saveGame{
+			Name:  "a",
+			Score: 0x13,
+		})).To(Succeed())
+		Expect(buffer.Bytes()).To(Equal([]uint8{
+			0x03, 0x00, 0x00, 0x00, // version
+			0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 'a', // name
+			0x13, 0x00, 0x00, 0x00, // score
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // levels
+		}))
+	})
+
+	Specify("current version", func() {
+		source := saveGame{
+			Name:   "hero",
+			Score:  1200,
+			Levels: []uint8{1, 2, 3},
+		}
+		Expect(gblob.NewLittleEndianPackedEncoder(buffer).Encode(source)).To(Succeed())
+
+		var target saveGame
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(source))
+	})
+
+	Specify("chained migration", func() {
+		writer := gblob.NewLittleEndianWriter(buffer)
+		Expect(writer.WriteUint32(1)).To(Succeed())
+		Expect(gblob.NewLittleEndianPackedEncoder(buffer).Encode(saveGameV1{
+			Name:  "hero",
+			Score: 12,
+		})).To(Succeed())
+
+		var target *saveGame
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(&saveGame{
+			Name:   "hero",
+			Score:  120,
+			Levels: []uint8{1},
+		}))
+	})
+
+	Specify("nested versioned value", func() {
+		writer := gblob.NewLittleEndianWriter(buffer)
+		Expect(writer.WriteUint16(0x1234)).To(Succeed())
+		Expect(writer.WriteUint32(2)).To(Succeed())
+		Expect(gblob.NewLittleEndianPackedEncoder(buffer).Encode(saveGameV2{
+			Name:  "hero",
+			Score: 5,
+		})).To(Succeed())
+
+		var target struct {
+			Slot uint16
+			Game saveGame
+		}
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target.Slot).To(Equal(uint16(0x1234)))
+		Expect(target.Game).To(Equal(saveGame{
+			Name:   "hero",
+			Score:  5,
+			Levels: []uint8{1},
+		}))
+	})
+
+	Specify("missing migration", func() {
+		writer := gblob.NewLittleEndianWriter(buffer)
+		Expect(writer.WriteUint32(7)).To(Succeed())
+
+		var target saveGame
+		Expect(decoder.Decode(&target)).To(MatchError(ContainSubstring("no migration from version 7")))
+	})
+
+	Specify("failed migration", func() {
+		errMigration := errors.New("migration failed")
+		migrations = gblob.NewMigrations()
+		gblob.RegisterMigration(migrations, 2, func(old saveGameV2) (saveGame, error) {
+			return saveGame{}, errMigration
+		})
+		decoder.SetMigrations(migrations)
+
+		writer := gblob.NewLittleEndianWriter(buffer)
+		Expect(writer.WriteUint32(2)).To(Succeed())
+		Expect(gblob.NewLittleEndianPackedEncoder(buffer).Encode(saveGameV2{})).To(Succeed())
+
+		var target saveGame
+		Expect(decoder.Decode(&target)).To(MatchError(errMigration))
+	})
+})
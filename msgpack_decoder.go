@@ -0,0 +1,564 @@
+package gblob
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+)
+
+// NewMsgpackDecoder creates a new MsgpackDecoder that reads from the specified
+// in Reader.
+func NewMsgpackDecoder(in io.Reader) *MsgpackDecoder {
+	return &MsgpackDecoder{
+		in: NewBigEndianReader(in),
+	}
+}
+
+// MsgpackDecoder decodes arbitrary Go objects from MessagePack form, as
+// written by a MsgpackEncoder.
+//
+// Numbers are accepted in any MessagePack format, as long as the value fits
+// into the target. Arrays that are longer than the target array or struct
+// have their extra elements skipped and shorter ones leave the remaining
+// elements at zero. Map entries of PackedTagged structs with unknown field
+// numbers are skipped.
+//
+// Values of PackedVersioned types that were stored with an older version are
+// upgraded through the configured Migrations.
+//
+// Values can be decoded into an empty interface, in which case they are
+// represented by nil, bool, int64, uint64, float64, string, []byte, []any and
+// map[any]any values.
+type MsgpackDecoder struct {
+	in         TypedReader
+	migrations *Migrations
+}
+
+// SetMigrations configures the migrations that are used to upgrade values of
+// PackedVersioned types that were stored with an older version.
+func (d *MsgpackDecoder) SetMigrations(migrations *Migrations) {
+	d.migrations = migrations
+}
+
+// Decode decodes the specified target value from the Reader. The target
+// needs to be a non-nil pointer.
+func (d *MsgpackDecoder) Decode(target any) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return fmt.Errorf("target needs to be a non-nil pointer")
+	}
+	format, err := d.in.ReadUint8()
+	if err != nil {
+		return err
+	}
+	if format != msgpackNil && value.Type().Implements(decodableType) {
+		return d.decodeDecodable(format, value.Interface().(PackedDecodable))
+	}
+	return d.decodeValue(format, value.Elem())
+}
+
+// decodeNext decodes a nested value, which is expected to be present.
+func (d *MsgpackDecoder) decodeNext(value reflect.Value) error {
+	format, err := d.in.ReadUint8()
+	if err != nil {
+		return eofAsUnexpected(err)
+	}
+	return d.decodeValue(format, value)
+}
+
+func (d *MsgpackDecoder) decodeValue(format uint8, value reflect.Value) error {
+	if format == msgpackNil {
+		value.SetZero()
+		return nil
+	}
+	if value.Kind() == reflect.Pointer && value.IsNil() {
+		value.Set(reflect.New(value.Type().Elem()))
+	}
+	switch decodeHookOf(value) {
+	case hookLazy:
+		lazy, _ := asLazyDecodable(value)
+		return lazy.decodeEager(func(target any) error {
+			return d.decodeValue(format, reflect.ValueOf(target).Elem())
+		})
+	case hookPacked:
+		return d.decodeDecodable(format, value.Interface().(PackedDecodable))
+	case hookVersioned:
+		return d.decodeVersionedValue(format, value)
+	}
+	return d.decodeKindValue(format, value)
+}
+
+func (d *MsgpackDecoder) decodeKindValue(format uint8, value reflect.Value) error {
+	switch kind := value.Kind(); kind {
+	case reflect.Pointer:
+		return d.decodeValue(format, value.Elem())
+	case reflect.Interface:
+		if value.NumMethod() > 0 {
+			return fmt.Errorf("unsupported interface type: %v", value.Type())
+		}
+		v, err := d.decodeAny(format)
+		if err != nil {
+			return err
+		}
+		value.Set(reflect.ValueOf(v))
+		return nil
+	case reflect.Bool:
+		switch format {
+		case msgpackTrue:
+			value.SetBool(true)
+		case msgpackFalse:
+			value.SetBool(false)
+		default:
+			return unexpectedMsgpackFormat(format, value.Type())
+		}
+		return nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		number, err := d.readNumber(format, value.Type())
+		if err != nil {
+			return err
+		}
+		var v int64
+		switch number := number.(type) {
+		case int64:
+			v = number
+		case uint64:
+			if number > math.MaxInt64 {
+				return fmt.Errorf("value %d overflows %v", number, value.Type())
+			}
+			v = int64(number)
+		default:
+			return unexpectedMsgpackFormat(format, value.Type())
+		}
+		if value.OverflowInt(v) {
+			return fmt.Errorf("value %d overflows %v", v, value.Type())
+		}
+		value.SetInt(v)
+		return nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		number, err := d.readNumber(format, value.Type())
+		if err != nil {
+			return err
+		}
+		var v uint64
+		switch number := number.(type) {
+		case int64:
+			if number < 0 {
+				return fmt.Errorf("value %d overflows %v", number, value.Type())
+			}
+			v = uint64(number)
+		case uint64:
+			v = number
+		default:
+			return unexpectedMsgpackFormat(format, value.Type())
+		}
+		if value.OverflowUint(v) {
+			return fmt.Errorf("value %d overflows %v", v, value.Type())
+		}
+		value.SetUint(v)
+		return nil
+	case reflect.Float32, reflect.Float64:
+		number, err := d.readNumber(format, value.Type())
+		if err != nil {
+			return err
+		}
+		switch number := number.(type) {
+		case int64:
+			value.SetFloat(float64(number))
+		case uint64:
+			value.SetFloat(float64(number))
+		case float64:
+			value.SetFloat(number)
+		}
+		return nil
+	case reflect.String:
+		data, err := d.readData(format, value.Type())
+		if err != nil {
+			return err
+		}
+		value.SetString(string(data))
+		return nil
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Uint8 { // fast track
+			data, err := d.readData(format, value.Type())
+			if err != nil {
+				return err
+			}
+			value.Set(reflect.ValueOf(data).Convert(value.Type()))
+			return nil
+		}
+		count, err := d.readLength(msgpackArray, format, value.Type())
+		if err != nil {
+			return err
+		}
+		value.Set(reflect.MakeSlice(value.Type(), 0, min(count, preallocLimit)))
+		for i := 0; i < count; i++ {
+			value.Grow(1)
+			value.SetLen(i + 1)
+			if err := d.decodeNext(value.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Array:
+		count, err := d.readLength(msgpackArray, format, value.Type())
+		if err != nil {
+			return err
+		}
+		return d.decodeElements(count, value.Len(), value.Index)
+	case reflect.Map:
+		count, err := d.readLength(msgpackMap, format, value.Type())
+		if err != nil {
+			return err
+		}
+		value.Set(reflect.MakeMapWithSize(value.Type(), min(count, preallocLimit)))
+		for i := 0; i < count; i++ {
+			entryKey := reflect.New(value.Type().Key()).Elem()
+			if err := d.decodeNext(entryKey); err != nil {
+				return err
+			}
+			entryValue := reflect.New(value.Type().Elem()).Elem()
+			if err := d.decodeNext(entryValue); err != nil {
+				return err
+			}
+			value.SetMapIndex(entryKey, entryValue)
+		}
+		return nil
+	case reflect.Struct:
+		if isTagged(value.Type()) {
+			return d.decodeTaggedStruct(format, value)
+		}
+		count, err := d.readLength(msgpackArray, format, value.Type())
+		if err != nil {
+			return err
+		}
+		return d.decodeElements(count, value.NumField(), value.Field)
+	default:
+		return fmt.Errorf("unsupported type: %v", kind)
+	}
+}
+
+func (d *MsgpackDecoder) decodeElements(count, length int, element func(int) reflect.Value) error {
+	for i := 0; i < count; i++ {
+		if i >= length {
+			if err := d.skipNext(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := d.decodeNext(element(i)); err != nil {
+			return err
+		}
+	}
+	for i := count; i < length; i++ {
+		element(i).SetZero()
+	}
+	return nil
+}
+
+func (d *MsgpackDecoder) decodeTaggedStruct(format uint8, value reflect.Value) error {
+	fields, err := taggedFieldsOf(value.Type())
+	if err != nil {
+		return err
+	}
+	count, err := d.readLength(msgpackMap, format, value.Type())
+	if err != nil {
+		return err
+	}
+	decoded := make([]bool, len(fields))
+	for i := 0; i < count; i++ {
+		var number uint32
+		if err := d.decodeNext(reflect.ValueOf(&number).Elem()); err != nil {
+			return err
+		}
+		position := taggedFieldPosition(fields, number)
+		if position < 0 {
+			if err := d.skipNext(); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := d.decodeNext(value.Field(fields[position].index)); err != nil {
+			return err
+		}
+		decoded[position] = true
+	}
+	for i, field := range fields {
+		if !decoded[i] {
+			value.Field(field.index).SetZero()
+		}
+	}
+	return nil
+}
+
+func (d *MsgpackDecoder) decodeVersionedValue(format uint8, value reflect.Value) error {
+	count, err := d.readLength(msgpackArray, format, value.Type())
+	if err != nil {
+		return err
+	}
+	if count != 2 {
+		return fmt.Errorf("expected version and value for %v, got %d elements", value.Type(), count)
+	}
+	var version uint32
+	if err := d.decodeNext(reflect.ValueOf(&version).Elem()); err != nil {
+		return err
+	}
+	decode := func(value reflect.Value) error {
+		format, err := d.in.ReadUint8()
+		if err != nil {
+			return err
+		}
+		return d.decodeKindValue(format, value)
+	}
+	if version == versionOf(value.Type()) {
+		return decode(value)
+	}
+	return d.migrations.decodeMigrated(version, value, decode)
+}
+
+func (d *MsgpackDecoder) decodeDecodable(format uint8, decodable PackedDecodable) error {
+	count, err := d.readLength(msgpackBin, format, reflect.TypeOf(decodable))
+	if err != nil {
+		return err
+	}
+	data, err := d.readBytes(count)
+	if err != nil {
+		return err
+	}
+	return decodable.DecodePacked(NewBigEndianReader(bytes.NewReader(data)))
+}
+
+func (d *MsgpackDecoder) decodeAny(format uint8) (any, error) {
+	switch format {
+	case msgpackNil:
+		return nil, nil
+	case msgpackTrue:
+		return true, nil
+	case msgpackFalse:
+		return false, nil
+	}
+	if isMsgpackNumber(format) {
+		return d.readNumber(format, nil)
+	}
+	family, ok := msgpackFamilyOf(format)
+	if !ok {
+		return nil, unexpectedMsgpackFormat(format, nil)
+	}
+	count, err := d.readLength(family, format, nil)
+	if err != nil {
+		return nil, err
+	}
+	switch family {
+	case msgpackStr:
+		data, err := d.readBytes(count)
+		return string(data), err
+	case msgpackBin:
+		return d.readBytes(count)
+	case msgpackArray:
+		result := make([]any, 0, min(count, preallocLimit))
+		for range count {
+			var element any
+			if err := d.decodeNext(reflect.ValueOf(&element).Elem()); err != nil {
+				return nil, err
+			}
+			result = append(result, element)
+		}
+		return result, nil
+	default:
+		result := make(map[any]any, min(count, preallocLimit))
+		for i := 0; i < count; i++ {
+			var key, value any
+			if err := d.decodeNext(reflect.ValueOf(&key).Elem()); err != nil {
+				return nil, err
+			}
+			if data, ok := key.([]byte); ok {
+				key = string(data)
+			}
+			if key == nil || !reflect.TypeOf(key).Comparable() {
+				return nil, fmt.Errorf("unsupported map key type: %T", key)
+			}
+			if err := d.decodeNext(reflect.ValueOf(&value).Elem()); err != nil {
+				return nil, err
+			}
+			result[key] = value
+		}
+		return result, nil
+	}
+}
+
+func (d *MsgpackDecoder) readNumber(format uint8, t reflect.Type) (any, error) {
+	switch {
+	case format <= 0x7F:
+		return int64(format), nil
+	case format >= 0xE0:
+		return int64(int8(format)), nil
+	}
+	switch format {
+	case msgpackUint8:
+		v, err := d.in.ReadUint8()
+		return uint64(v), err
+	case msgpackUint16:
+		v, err := d.in.ReadUint16()
+		return uint64(v), err
+	case msgpackUint32:
+		v, err := d.in.ReadUint32()
+		return uint64(v), err
+	case msgpackUint64:
+		v, err := d.in.ReadUint64()
+		return v, err
+	case msgpackInt8:
+		v, err := d.in.ReadInt8()
+		return int64(v), err
+	case msgpackInt16:
+		v, err := d.in.ReadInt16()
+		return int64(v), err
+	case msgpackInt32:
+		v, err := d.in.ReadInt32()
+		return int64(v), err
+	case msgpackInt64:
+		v, err := d.in.ReadInt64()
+		return v, err
+	case msgpackFloat32:
+		v, err := d.in.ReadFloat32()
+		return float64(v), err
+	case msgpackFloat64:
+		v, err := d.in.ReadFloat64()
+		return v, err
+	default:
+		return nil, unexpectedMsgpackFormat(format, t)
+	}
+}
+
+func (d *MsgpackDecoder) readData(format uint8, t reflect.Type) ([]byte, error) {
+	family, ok := msgpackFamilyOf(format)
+	if !ok || (family != msgpackStr && family != msgpackBin) {
+		return nil, unexpectedMsgpackFormat(format, t)
+	}
+	count, err := d.readLength(family, format, t)
+	if err != nil {
+		return nil, err
+	}
+	return d.readBytes(count)
+}
+
+func (d *MsgpackDecoder) readLength(family msgpackFamily, format uint8, t reflect.Type) (int, error) {
+	spec := msgpackFormats[family]
+	switch {
+	case spec.fix != 0 && format&^uint8(spec.fixMax) == spec.fix:
+		return int(format & uint8(spec.fixMax)), nil
+	case spec.len8 != 0 && format == spec.len8:
+		v, err := d.in.ReadUint8()
+		return int(v), err
+	case format == spec.len16:
+		v, err := d.in.ReadUint16()
+		return int(v), err
+	case format == spec.len32:
+		v, err := d.in.ReadUint32()
+		return int(v), err
+	default:
+		return 0, unexpectedMsgpackFormat(format, t)
+	}
+}
+
+func (d *MsgpackDecoder) readBytes(count int) ([]byte, error) {
+	return readChunked(d.in, count)
+}
+
+func (d *MsgpackDecoder) skipNext() error {
+	format, err := d.in.ReadUint8()
+	if err != nil {
+		return eofAsUnexpected(err)
+	}
+	switch format {
+	case msgpackNil, msgpackTrue, msgpackFalse:
+		return nil
+	case msgpackUint8, msgpackInt8:
+		return d.in.SkipBytes(1)
+	case msgpackUint16, msgpackInt16:
+		return d.in.SkipBytes(2)
+	case msgpackUint32, msgpackInt32, msgpackFloat32:
+		return d.in.SkipBytes(4)
+	case msgpackUint64, msgpackInt64, msgpackFloat64:
+		return d.in.SkipBytes(8)
+	case msgpackExt8:
+		count, err := d.in.ReadUint8()
+		if err != nil {
+			return err
+		}
+		return d.in.SkipBytes(1 + int(count))
+	case msgpackExt16:
+		count, err := d.in.ReadUint16()
+		if err != nil {
+			return err
+		}
+		return d.in.SkipBytes(1 + int(count))
+	case msgpackExt32:
+		count, err := d.in.ReadUint32()
+		if err != nil {
+			return err
+		}
+		return d.in.SkipBytes(1 + int(count))
+	}
+	switch {
+	case format <= 0x7F || format >= 0xE0:
+		return nil
+	case format >= msgpackFixExt1 && format <= msgpackFixExt1+4:
+		return d.in.SkipBytes(1 + 1<<(format-msgpackFixExt1))
+	}
+	family, ok := msgpackFamilyOf(format)
+	if !ok {
+		return unexpectedMsgpackFormat(format, nil)
+	}
+	count, err := d.readLength(family, format, nil)
+	if err != nil {
+		return err
+	}
+	switch family {
+	case msgpackArray:
+		for i := 0; i < count; i++ {
+			if err := d.skipNext(); err != nil {
+				return err
+			}
+		}
+		return nil
+	case msgpackMap:
+		for i := 0; i < 2*count; i++ {
+			if err := d.skipNext(); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return d.in.SkipBytes(count)
+	}
+}
+
+func isMsgpackNumber(format uint8) bool {
+	switch {
+	case format <= 0x7F || format >= 0xE0:
+		return true
+	case format == msgpackFloat32 || format == msgpackFloat64:
+		return true
+	default:
+		return format >= msgpackUint8 && format <= msgpackInt64
+	}
+}
+
+func msgpackFamilyOf(format uint8) (msgpackFamily, bool) {
+	for family, spec := range msgpackFormats {
+		if (spec.fix != 0 && format&^uint8(spec.fixMax) == spec.fix) ||
+			(spec.len8 != 0 && format == spec.len8) ||
+			format == spec.len16 || format == spec.len32 {
+			return msgpackFamily(family), true
+		}
+	}
+	return 0, false
+}
+
+func unexpectedMsgpackFormat(format uint8, t reflect.Type) error {
+	if t == nil {
+		return fmt.Errorf("unexpected MessagePack format 0x%02X", format)
+	}
+	return fmt.Errorf("unexpected MessagePack format 0x%02X for %v", format, t)
+}
@@ -0,0 +1,155 @@
+package gblob_test
+
+import (
+	"bytes"
+	"io"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+	"github.com/mokiat/gog"
+)
+
+var _ = Describe("MsgpackDecoder", func() {
+	var (
+		buffer  *bytes.Buffer
+		decoder *gblob.MsgpackDecoder
+	)
+
+	seq := func(values ...uint8) []uint8 {
+		return values
+	}
+
+	BeforeEach(func() {
+		buffer = new(bytes.Buffer)
+		decoder = gblob.NewMsgpackDecoder(buffer)
+	})
+
+	DescribeTable("types",
+		func(data []byte, target any, expected any) {
+			buffer.Write(data)
+			Expect(decoder.Decode(target)).To(Succeed())
+			Expect(target).To(Equal(expected))
+		},
+		Entry("bool", seq(0xC3), gog.PtrOf(false), gog.PtrOf(true)),
+		Entry("nil pointer", seq(0xC0), gog.PtrOf(gog.PtrOf(uint8(1))), gog.PtrOf((*uint8)(nil))),
+		Entry("pointer", seq(0x05), gog.PtrOf((*uint8)(nil)), gog.PtrOf(gog.PtrOf(uint8(5)))),
+		Entry("fixint to int64", seq(0x7F), gog.PtrOf(int64(0)), gog.PtrOf(int64(0x7F))),
+		Entry("negative fixint", seq(0xE0), gog.PtrOf(int8(0)), gog.PtrOf(int8(-32))),
+		Entry("uint16 to uint64", seq(0xCD, 0x12, 0x34), gog.PtrOf(uint64(0)), gog.PtrOf(uint64(0x1234))),
+		Entry("uint8 to int16", seq(0xCC, 0xFF), gog.PtrOf(int16(0)), gog.PtrOf(int16(0xFF))),
+		Entry("int32", seq(0xD2, 0xFF, 0xFE, 0x79, 0x60), gog.PtrOf(int32(0)), gog.PtrOf(int32(-100000))),
+		Entry("float32", seq(0xCA, 0x3F, 0xC0, 0x00, 0x00), gog.PtrOf(float32(0)), gog.PtrOf(float32(1.5))),
+		Entry("int to float64", seq(0x02), gog.PtrOf(float64(0)), gog.PtrOf(float64(2.0))),
+		Entry("fixstr", seq(0xA3, 'a', 'b', 'c'), gog.PtrOf(""), gog.PtrOf("abc")),
+		Entry("str8", seq(0xD9, 0x01, 'a'), gog.PtrOf(""), gog.PtrOf("a")),
+		Entry("bin to string", seq(0xC4, 0x01, 'a'), gog.PtrOf(""), gog.PtrOf("a")),
+		Entry("bin8", seq(0xC4, 0x02, 0x01, 0x02), gog.PtrOf([]byte(nil)), gog.PtrOf([]byte{0x01, 0x02})),
+		Entry("array16", seq(0xDC, 0x00, 0x02, 0x01, 0x02), gog.PtrOf([]uint16(nil)), gog.PtrOf([]uint16{1, 2})),
+		Entry("longer array", seq(0x93, 0x01, 0x02, 0x03), gog.PtrOf([2]uint8{}), gog.PtrOf([2]uint8{1, 2})),
+		Entry("shorter array", seq(0x91, 0x01), gog.PtrOf([2]uint8{5, 5}), gog.PtrOf([2]uint8{1, 0})),
+		Entry("fixmap", seq(0x81, 0xA1, 'a', 0x01), gog.PtrOf(map[string]uint8(nil)), gog.PtrOf(map[string]uint8{"a": 1})),
+		Entry("struct",
+			seq(0x92, 0xFF, 0xCA, 0x3F, 0x00, 0x00, 0x00),
+			&msgpackPoint{},
+			&msgpackPoint{X: -1, Y: 0.5},
+		),
+		Entry("struct with extra fields",
+			seq(0x93, 0x01, 0x02, 0x92, 0x01, 0x02),
+			&msgpackPoint{},
+			&msgpackPoint{X: 1, Y: 2},
+		),
+		Entry("tagged struct",
+			seq(0x83, 0x01, 0x07, 0x09, 0xA1, 'x', 0x03, 0xA1, 'n'),
+			&msgpackTagged{Point: &msgpackPoint{}},
+			&msgpackTagged{ID: 7, Name: "n"},
+		),
+		Entry("custom",
+			seq(0xC4, 0x02, 0x12, 0x34),
+			&msgpackCustom{},
+			&msgpackCustom{Value: 0x1234},
+		),
+		Entry("versioned",
+			seq(0x92, 0x03, 0x93, 0xA1, 'a', 0x01, 0xC4, 0x01, 0x02),
+			&saveGame{},
+			&saveGame{Name: "a", Score: 1, Levels: []uint8{2}},
+		),
+		Entry("lazy slice",
+			seq(0x92, 0x01, 0x02),
+			&gblob.LazySlice[uint16]{},
+			gog.PtrOf(gblob.NewLazySlice([]uint16{1, 2})),
+		),
+		Entry("any",
+			seq(0x83,
+				0xA1, 'a', 0x92, 0xFF, 0xCF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+				0xC4, 0x01, 'b', 0xCB, 0x3F, 0xF8, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+				0x01, 0xC0,
+			),
+			gog.PtrOf(any(nil)),
+			gog.PtrOf(any(map[any]any{
+				"a":      []any{int64(-1), uint64(0xFFFFFFFFFFFFFFFF)},
+				"b":      float64(1.5),
+				int64(1): nil,
+			})),
+		),
+	)
+
+	DescribeTable("errors",
+		func(data []byte, target any) {
+			buffer.Write(data)
+			Expect(decoder.Decode(target)).ToNot(Succeed())
+		},
+		Entry("overflow", seq(0xCD, 0x01, 0x00), gog.PtrOf(uint8(0))),
+		Entry("negative to unsigned", seq(0xFF), gog.PtrOf(uint32(0))),
+		Entry("float to int", seq(0xCA, 0x3F, 0xC0, 0x00, 0x00), gog.PtrOf(int32(0))),
+		Entry("string to bool", seq(0xA1, 'a'), gog.PtrOf(false)),
+		Entry("reserved", seq(0xC1), gog.PtrOf(any(nil))),
+		Entry("truncated", seq(0xCD, 0x01), gog.PtrOf(uint16(0))),
+		Entry("nil map key", seq(0x81, 0xC0, 0x01), gog.PtrOf(any(nil))),
+		Entry("versioned without version", seq(0x93, 0xA1, 'a', 0x01, 0x90), &saveGame{}),
+		Entry("versioned without migration", seq(0x92, 0x01, 0x92, 0xA1, 'a', 0x01), &saveGame{}),
+	)
+
+	DescribeTable("corrupted length",
+		func(data []byte, target any) {
+			buffer.Write(data)
+			Expect(decoder.Decode(target)).To(MatchError(io.ErrUnexpectedEOF))
+		},
+		Entry("bin", seq(0xC6, 0xFF, 0xFF, 0xFF, 0xFF, 0x01), new([]byte)),
+		Entry("str", seq(0xDB, 0xFF, 0xFF, 0xFF, 0xFF, 'a'), new(string)),
+		Entry("array", seq(0xDD, 0xFF, 0xFF, 0xFF, 0xFF, 0x01), new([]uint16)),
+		Entry("map", seq(0xDF, 0xFF, 0xFF, 0xFF, 0xFF, 0xA1, 'a', 0x01), new(map[string]int)),
+		Entry("array as any", seq(0xDD, 0xFF, 0xFF, 0xFF, 0xFF, 0x01), gog.PtrOf(any(nil))),
+		Entry("map as any", seq(0xDF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01, 0x02), gog.PtrOf(any(nil))),
+	)
+
+	Specify("migrations", func() {
+		migrations := gblob.NewMigrations()
+		gblob.RegisterMigration(migrations, 1, func(old saveGameV1) (saveGame, error) {
+			return saveGame{
+				Name:  old.Name,
+				Score: uint32(old.Score) * 10,
+			}, nil
+		})
+		decoder.SetMigrations(migrations)
+		buffer.Write(seq(0x92, 0x01, 0x92, 0xA1, 'a', 0x05))
+
+		var target saveGame
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(saveGame{Name: "a", Score: 50}))
+	})
+
+	Specify("round trip", func() {
+		source := msgpackTagged{
+			ID:    0xABCD,
+			Point: &msgpackPoint{X: -300, Y: 2.25},
+			Name:  "round trip",
+		}
+		Expect(gblob.NewMsgpackEncoder(buffer).Encode(source)).To(Succeed())
+
+		var target msgpackTagged
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(source))
+	})
+})
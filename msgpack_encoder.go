@@ -0,0 +1,327 @@
+package gblob
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+)
+
+const (
+	msgpackNil     = 0xC0
+	msgpackFalse   = 0xC2
+	msgpackTrue    = 0xC3
+	msgpackFloat32 = 0xCA
+	msgpackFloat64 = 0xCB
+	msgpackUint8   = 0xCC
+	msgpackUint16  = 0xCD
+	msgpackUint32  = 0xCE
+	msgpackUint64  = 0xCF
+	msgpackInt8    = 0xD0
+	msgpackInt16   = 0xD1
+	msgpackInt32   = 0xD2
+	msgpackInt64   = 0xD3
+	msgpackFixExt1 = 0xD4
+	msgpackExt8    = 0xC7
+	msgpackExt16   = 0xC8
+	msgpackExt32   = 0xC9
+)
+
+type msgpackFamily uint8
+
+const (
+	msgpackStr msgpackFamily = iota
+	msgpackBin
+	msgpackArray
+	msgpackMap
+)
+
+type msgpackFormat struct {
+	fix    uint8
+	fixMax int
+	len8   uint8
+	len16  uint8
+	len32  uint8
+}
+
+var msgpackFormats = [...]msgpackFormat{
+	msgpackStr:   {fix: 0xA0, fixMax: 0x1F, len8: 0xD9, len16: 0xDA, len32: 0xDB},
+	msgpackBin:   {len8: 0xC4, len16: 0xC5, len32: 0xC6},
+	msgpackArray: {fix: 0x90, fixMax: 0x0F, len16: 0xDC, len32: 0xDD},
+	msgpackMap:   {fix: 0x80, fixMax: 0x0F, len16: 0xDE, len32: 0xDF},
+}
+
+// NewMsgpackEncoder creates a new MsgpackEncoder that writes to the specified
+// out Writer.
+func NewMsgpackEncoder(out io.Writer) *MsgpackEncoder {
+	return &MsgpackEncoder{
+		out: NewBigEndianWriter(out),
+	}
+}
+
+// MsgpackEncoder encodes arbitrary Go objects in MessagePack form.
+//
+// Values are traversed in the same way as by the PackedEncoder. Structs are
+// encoded as arrays of their fields, in order, unless they implement
+// PackedTagged, in which case they are encoded as maps from field number to
+// value. Nil pointers are encoded as nil. Types that implement
+// PackedEncodable are encoded as bin values that hold their Big Endian packed
+// form. Types that implement PackedVersioned are encoded as two-element
+// arrays of their version and value. Lazy values are encoded inline and need
+// to be loaded.
+//
+// Integers and strings use the smallest MessagePack format that can hold
+// them.
+type MsgpackEncoder struct {
+	out TypedWriter
+}
+
+// Encode encodes the specified source value into the Writer.
+func (e *MsgpackEncoder) Encode(source any) error {
+	if source == nil {
+		return e.out.WriteUint8(msgpackNil)
+	}
+	return e.encodeValue(reflect.ValueOf(source))
+}
+
+func (e *MsgpackEncoder) encodeValue(value reflect.Value) error {
+	if value.Kind() == reflect.Pointer && value.IsNil() {
+		return e.out.WriteUint8(msgpackNil)
+	}
+	switch encodeHookOf(value.Type()) {
+	case hookLazy:
+		loaded, err := value.Interface().(lazyEncodable).loadedValue()
+		if err != nil {
+			return err
+		}
+		return e.Encode(loaded)
+	case hookPacked:
+		return e.encodeEncodable(value.Interface().(PackedEncodable))
+	case hookVersioned:
+		return e.encodeVersionedValue(value)
+	}
+	return e.encodeKindValue(value)
+}
+
+func (e *MsgpackEncoder) encodeKindValue(value reflect.Value) error {
+	switch kind := value.Kind(); kind {
+	case reflect.Pointer, reflect.Interface:
+		if value.IsNil() {
+			return e.out.WriteUint8(msgpackNil)
+		}
+		return e.encodeValue(value.Elem())
+	case reflect.Bool:
+		if value.Bool() {
+			return e.out.WriteUint8(msgpackTrue)
+		}
+		return e.out.WriteUint8(msgpackFalse)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return e.encodeUint(value.Uint())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return e.encodeInt(value.Int())
+	case reflect.Float32:
+		if err := e.out.WriteUint8(msgpackFloat32); err != nil {
+			return err
+		}
+		return e.out.WriteFloat32(float32(value.Float()))
+	case reflect.Float64:
+		if err := e.out.WriteUint8(msgpackFloat64); err != nil {
+			return err
+		}
+		return e.out.WriteFloat64(value.Float())
+	case reflect.String:
+		if err := e.encodeLength(msgpackStr, value.Len()); err != nil {
+			return err
+		}
+		return e.out.WriteBytes([]byte(value.String()))
+	case reflect.Array:
+		return e.encodeElements(value)
+	case reflect.Slice:
+		if value.Type().Elem().Kind() == reflect.Uint8 { // fast track
+			if err := e.encodeLength(msgpackBin, value.Len()); err != nil {
+				return err
+			}
+			return e.out.WriteBytes(value.Bytes())
+		}
+		return e.encodeElements(value)
+	case reflect.Map:
+		if err := e.encodeLength(msgpackMap, value.Len()); err != nil {
+			return err
+		}
+		entries := value.MapRange()
+		for entries.Next() {
+			if err := e.encodeValue(entries.Key()); err != nil {
+				return err
+			}
+			if err := e.encodeValue(entries.Value()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case reflect.Struct:
+		if isTagged(value.Type()) {
+			return e.encodeTaggedStruct(value)
+		}
+		return e.encodeElements(value)
+	default:
+		return fmt.Errorf("unsupported type: %v", kind)
+	}
+}
+
+func (e *MsgpackEncoder) encodeUint(value uint64) error {
+	switch {
+	case value <= 0x7F:
+		return e.out.WriteUint8(uint8(value))
+	case value <= math.MaxUint8:
+		if err := e.out.WriteUint8(msgpackUint8); err != nil {
+			return err
+		}
+		return e.out.WriteUint8(uint8(value))
+	case value <= math.MaxUint16:
+		if err := e.out.WriteUint8(msgpackUint16); err != nil {
+			return err
+		}
+		return e.out.WriteUint16(uint16(value))
+	case value <= math.MaxUint32:
+		if err := e.out.WriteUint8(msgpackUint32); err != nil {
+			return err
+		}
+		return e.out.WriteUint32(uint32(value))
+	default:
+		if err := e.out.WriteUint8(msgpackUint64); err != nil {
+			return err
+		}
+		return e.out.WriteUint64(value)
+	}
+}
+
+func (e *MsgpackEncoder) encodeInt(value int64) error {
+	switch {
+	case value >= 0:
+		return e.encodeUint(uint64(value))
+	case value >= -32:
+		return e.out.WriteInt8(int8(value))
+	case value >= math.MinInt8:
+		if err := e.out.WriteUint8(msgpackInt8); err != nil {
+			return err
+		}
+		return e.out.WriteInt8(int8(value))
+	case value >= math.MinInt16:
+		if err := e.out.WriteUint8(msgpackInt16); err != nil {
+			return err
+		}
+		return e.out.WriteInt16(int16(value))
+	case value >= math.MinInt32:
+		if err := e.out.WriteUint8(msgpackInt32); err != nil {
+			return err
+		}
+		return e.out.WriteInt32(int32(value))
+	default:
+		if err := e.out.WriteUint8(msgpackInt64); err != nil {
+			return err
+		}
+		return e.out.WriteInt64(value)
+	}
+}
+
+func (e *MsgpackEncoder) encodeLength(family msgpackFamily, length int) error {
+	format := msgpackFormats[family]
+	switch {
+	case format.fix != 0 && length <= format.fixMax:
+		return e.out.WriteUint8(format.fix | uint8(length))
+	case format.len8 != 0 && length <= math.MaxUint8:
+		if err := e.out.WriteUint8(format.len8); err != nil {
+			return err
+		}
+		return e.out.WriteUint8(uint8(length))
+	case length <= math.MaxUint16:
+		if err := e.out.WriteUint8(format.len16); err != nil {
+			return err
+		}
+		return e.out.WriteUint16(uint16(length))
+	case uint64(length) <= math.MaxUint32:
+		if err := e.out.WriteUint8(format.len32); err != nil {
+			return err
+		}
+		return e.out.WriteUint32(uint32(length))
+	default:
+		return fmt.Errorf("length %d exceeds MessagePack limit", length)
+	}
+}
+
+func (e *MsgpackEncoder) encodeElements(value reflect.Value) error {
+	isStruct := value.Kind() == reflect.Struct
+	var count int
+	if isStruct {
+		count = value.NumField()
+	} else {
+		count = value.Len()
+	}
+	if err := e.encodeLength(msgpackArray, count); err != nil {
+		return err
+	}
+	for i := 0; i < count; i++ {
+		var element reflect.Value
+		if isStruct {
+			element = value.Field(i)
+		} else {
+			element = value.Index(i)
+		}
+		if err := e.encodeValue(element); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *MsgpackEncoder) encodeTaggedStruct(value reflect.Value) error {
+	fields, err := taggedFieldsOf(value.Type())
+	if err != nil {
+		return err
+	}
+	count := 0
+	for _, field := range fields {
+		fieldValue := value.Field(field.index)
+		if fieldValue.Kind() != reflect.Pointer || !fieldValue.IsNil() {
+			count++
+		}
+	}
+	if err := e.encodeLength(msgpackMap, count); err != nil {
+		return err
+	}
+	for _, field := range fields {
+		fieldValue := value.Field(field.index)
+		if fieldValue.Kind() == reflect.Pointer && fieldValue.IsNil() {
+			continue
+		}
+		if err := e.encodeUint(uint64(field.number)); err != nil {
+			return err
+		}
+		if err := e.encodeValue(fieldValue); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *MsgpackEncoder) encodeVersionedValue(value reflect.Value) error {
+	if err := e.encodeLength(msgpackArray, 2); err != nil {
+		return err
+	}
+	if err := e.encodeUint(uint64(versionOf(value.Type()))); err != nil {
+		return err
+	}
+	return e.encodeKindValue(value)
+}
+
+func (e *MsgpackEncoder) encodeEncodable(encodable PackedEncodable) error {
+	var buffer bytes.Buffer
+	if err := encodable.EncodePacked(NewBigEndianWriter(&buffer)); err != nil {
+		return err
+	}
+	if err := e.encodeLength(msgpackBin, buffer.Len()); err != nil {
+		return err
+	}
+	return e.out.WriteBytes(buffer.Bytes())
+}
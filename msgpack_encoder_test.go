@@ -0,0 +1,112 @@
+package gblob_test
+
+import (
+	"bytes"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+	"github.com/mokiat/gog"
+)
+
+type msgpackPoint struct {
+	X int16
+	Y float32
+}
+
+type msgpackTagged struct {
+	ID    uint16        `gblob:"1"`
+	Point *msgpackPoint `gblob:"2"`
+	Name  string        `gblob:"3"`
+}
+
+func (msgpackTagged) PackedTagged() {}
+
+type msgpackCustom struct {
+	Value uint16
+}
+
+func (c msgpackCustom) EncodePacked(writer gblob.TypedWriter) error {
+	return writer.WriteUint16(c.Value)
+}
+
+func (c *msgpackCustom) DecodePacked(reader gblob.TypedReader) error {
+	var err error
+	c.Value, err = reader.ReadUint16()
+	return err
+}
+
+var _ = Describe("MsgpackEncoder", func() {
+	var (
+		buffer  *bytes.Buffer
+		encoder *gblob.MsgpackEncoder
+	)
+
+	seq := func(values ...uint8) []uint8 {
+		return values
+	}
+
+	BeforeEach(func() {
+		buffer = new(bytes.Buffer)
+		encoder = gblob.NewMsgpackEncoder(buffer)
+	})
+
+	DescribeTable("types",
+		func(source any, expected []byte) {
+			Expect(encoder.Encode(source)).To(Succeed())
+			Expect(buffer.Bytes()).To(Equal(expected))
+		},
+		Entry("nil", nil, seq(0xC0)),
+		Entry("nil pointer", (*uint8)(nil), seq(0xC0)),
+		Entry("true", true, seq(0xC3)),
+		Entry("false", false, seq(0xC2)),
+		Entry("positive fixint", uint64(0x7F), seq(0x7F)),
+		Entry("negative fixint", int8(-32), seq(0xE0)),
+		Entry("uint8", uint16(0xFF), seq(0xCC, 0xFF)),
+		Entry("uint16", uint32(0x1234), seq(0xCD, 0x12, 0x34)),
+		Entry("uint32", uint64(0x12345678), seq(0xCE, 0x12, 0x34, 0x56, 0x78)),
+		Entry("uint64", uint64(0x0102030405060708), seq(0xCF, 0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08)),
+		Entry("positive int", int64(200), seq(0xCC, 0xC8)),
+		Entry("int8", int32(-33), seq(0xD0, 0xDF)),
+		Entry("int16", int32(-1000), seq(0xD1, 0xFC, 0x18)),
+		Entry("int32", int64(-100000), seq(0xD2, 0xFF, 0xFE, 0x79, 0x60)),
+		Entry("int64", int64(-5000000000), seq(0xD3, 0xFF, 0xFF, 0xFF, 0xFE, 0xD5, 0xFA, 0x0E, 0x00)),
+		Entry("float32", float32(1.5), seq(0xCA, 0x3F, 0xC0, 0x00, 0x00)),
+		Entry("float64", float64(-2.0), seq(0xCB, 0xC0, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00)),
+		Entry("fixstr", "abc", seq(0xA3, 'a', 'b', 'c')),
+		Entry("str8", strings.Repeat("x", 32), append(seq(0xD9, 0x20), strings.Repeat("x", 32)...)),
+		Entry("bin8", []byte{0x01, 0x02}, seq(0xC4, 0x02, 0x01, 0x02)),
+		Entry("fixarray", []uint16{1, 2}, seq(0x92, 0x01, 0x02)),
+		Entry("array", [2]bool{true, false}, seq(0x92, 0xC3, 0xC2)),
+		Entry("fixmap", map[string]uint8{"a": 1}, seq(0x81, 0xA1, 'a', 0x01)),
+		Entry("pointer", gog.PtrOf(uint8(5)), seq(0x05)),
+		Entry("struct",
+			msgpackPoint{X: -1, Y: 0.5},
+			seq(0x92, 0xFF, 0xCA, 0x3F, 0x00, 0x00, 0x00),
+		),
+		Entry("tagged struct",
+			msgpackTagged{ID: 7, Name: "n"},
+			seq(0x82, 0x01, 0x07, 0x03, 0xA1, 'n'),
+		),
+		Entry("custom",
+			msgpackCustom{Value: 0x1234},
+			seq(0xC4, 0x02, 0x12, 0x34),
+		),
+		Entry("versioned",
+			saveGame{Name: "a", Score: 1},
+			seq(0x92, 0x03, 0x93, 0xA1, 'a', 0x01, 0xC4, 0x00),
+		),
+		Entry("lazy",
+			gblob.NewLazy(uint16(0x1234)),
+			seq(0xCD, 0x12, 0x34),
+		),
+	)
+
+	Specify("array16", func() {
+		Expect(encoder.Encode(make([]bool, 16))).To(Succeed())
+		Expect(buffer.Bytes()[:3]).To(Equal(seq(0xDC, 0x00, 0x10)))
+		Expect(buffer.Len()).To(Equal(3 + 16))
+	})
+})
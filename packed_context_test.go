@@ -0,0 +1,146 @@
+package gblob_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+)
+
+var _ = Describe("Context", func() {
+	type scene struct {
+		Name    string
+		Weights []float32
+		Lookup  map[uint32]uint32
+	}
+
+	var (
+		ctx    context.Context
+		cancel context.CancelFunc
+		source scene
+	)
+
+	BeforeEach(func() {
+		ctx, cancel = context.WithCancel(context.Background())
+		source = scene{
+			Name:    "scene",
+			Weights: make([]float32, 10000),
+			Lookup:  make(map[uint32]uint32),
+		}
+		for i := range 3000 {
+			source.Lookup[uint32(i)] = uint32(i * 2)
+		}
+	})
+
+	AfterEach(func() {
+		cancel()
+	})
+
+	Specify("EncodeContext completes when not canceled", func() {
+		var buffer bytes.Buffer
+		encoder := gblob.NewLittleEndianPackedEncoder(&buffer)
+		Expect(encoder.EncodeContext(ctx, source)).To(Succeed())
+
+		var target scene
+		decoder := gblob.NewLittleEndianPackedDecoder(&buffer)
+		Expect(decoder.DecodeContext(ctx, &target)).To(Succeed())
+		Expect(target).To(Equal(source))
+	})
+
+	Specify("EncodeContext returns immediately when already canceled", func() {
+		cancel()
+
+		var buffer bytes.Buffer
+		encoder := gblob.NewLittleEndianPackedEncoder(&buffer)
+		Expect(encoder.EncodeContext(ctx, source)).To(MatchError(context.Canceled))
+		Expect(buffer.Len()).To(BeZero())
+	})
+
+	Specify("EncodeContext stops when canceled", func() {
+		out := &cancelingWriter{
+			limit:  1000,
+			cancel: cancel,
+		}
+		encoder := gblob.NewLittleEndianPackedEncoder(out)
+		err := encoder.EncodeContext(ctx, source)
+		Expect(err).To(MatchError(context.Canceled))
+		Expect(err).To(MatchError(ContainSubstring("encoding stopped at element 1023 (offset 4113)")))
+		Expect(out.written).To(BeNumerically("<", 40000))
+
+		By("not affecting subsequent calls to Encode")
+		Expect(encoder.Encode(source)).To(Succeed())
+	})
+
+	Specify("DecodeContext stops when canceled", func() {
+		var buffer bytes.Buffer
+		Expect(gblob.NewLittleEndianPackedEncoder(&buffer).Encode(source)).To(Succeed())
+		in := &cancelingReader{
+			in:     &buffer,
+			limit:  1000,
+			cancel: cancel,
+		}
+
+		var target scene
+		decoder := gblob.NewLittleEndianPackedDecoder(in)
+		err := decoder.DecodeContext(ctx, &target)
+		Expect(err).To(MatchError(context.Canceled))
+		Expect(err).To(MatchError(ContainSubstring("decoding stopped at element 1023 (offset 4113)")))
+	})
+
+	Specify("DecodeContext stops when canceled in self-describing mode", func() {
+		var buffer bytes.Buffer
+		encoder := gblob.NewLittleEndianPackedEncoder(&buffer)
+		encoder.SetSelfDescribing(true)
+		Expect(encoder.Encode(source)).To(Succeed())
+		in := &cancelingReader{
+			in:     &buffer,
+			limit:  40100,
+			cancel: cancel,
+		}
+
+		var target scene
+		decoder := gblob.NewLittleEndianPackedDecoder(in)
+		decoder.SetSelfDescribing(true)
+		err := decoder.DecodeContext(ctx, &target)
+		Expect(err).To(MatchError(context.Canceled))
+		Expect(err).To(MatchError(ContainSubstring("decoding stopped at element")))
+	})
+})
+
+// cancelingWriter cancels a context once the specified number of bytes has
+// been written.
+type cancelingWriter struct {
+	limit   int
+	written int
+	cancel  context.CancelFunc
+}
+
+func (w *cancelingWriter) Write(data []byte) (int, error) {
+	w.written += len(data)
+	if w.written >= w.limit {
+		w.cancel()
+	}
+	return len(data), nil
+}
+
+// cancelingReader cancels a context once the specified number of bytes has
+// been read.
+type cancelingReader struct {
+	in     io.Reader
+	limit  int
+	read   int
+	cancel context.CancelFunc
+}
+
+func (r *cancelingReader) Read(data []byte) (int, error) {
+	n, err := r.in.Read(data)
+	r.read += n
+	if r.read >= r.limit {
+		r.cancel()
+	}
+	return n, err
+}
@@ -1,9 +1,15 @@
 package gblob
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
 	"io"
+	"math"
 	"reflect"
+	"slices"
+	"unsafe"
 )
 
 var (
@@ -22,7 +28,8 @@ type PackedDecodable interface {
 // to read its input in Little Endian order.
 func NewLittleEndianPackedDecoder(in io.Reader) *PackedDecoder {
 	return &PackedDecoder{
-		in: NewLittleEndianReader(in),
+		in:    NewLittleEndianReader(in),
+		order: LittleEndian,
 	}
 }
 
@@ -30,30 +37,184 @@ func NewLittleEndianPackedDecoder(in io.Reader) *PackedDecoder {
 // to read its input in Big Endian order.
 func NewBigEndianPackedDecoder(in io.Reader) *PackedDecoder {
 	return &PackedDecoder{
-		in: NewBigEndianReader(in),
+		in:    NewBigEndianReader(in),
+		order: BigEndian,
+	}
+}
+
+// NewNativeEndianPackedDecoder creates a new PackedDecoder that is configured
+// to read its input in the byte order of the host.
+func NewNativeEndianPackedDecoder(in io.Reader) *PackedDecoder {
+	return &PackedDecoder{
+		in:    NewNativeEndianReader(in),
+		order: NativeEndian,
+	}
+}
+
+// NewLittleEndianBufferPackedDecoder creates a new PackedDecoder that is
+// configured to read the specified data buffer in Little Endian order.
+func NewLittleEndianBufferPackedDecoder(data []byte) *PackedDecoder {
+	return &PackedDecoder{
+		in:    NewLittleEndianBufferReader(data),
+		order: LittleEndian,
+	}
+}
+
+// NewBigEndianBufferPackedDecoder creates a new PackedDecoder that is
+// configured to read the specified data buffer in Big Endian order.
+func NewBigEndianBufferPackedDecoder(data []byte) *PackedDecoder {
+	return &PackedDecoder{
+		in:    NewBigEndianBufferReader(data),
+		order: BigEndian,
+	}
+}
+
+// NewNativeEndianBufferPackedDecoder creates a new PackedDecoder that is
+// configured to read the specified data buffer in the byte order of the host.
+func NewNativeEndianBufferPackedDecoder(data []byte) *PackedDecoder {
+	return &PackedDecoder{
+		in:    NewNativeEndianBufferReader(data),
+		order: NativeEndian,
 	}
 }
 
 // PackedDecoder decodes arbitrary Go objects from binary form by going through
 // each field in sequence and deserializing it without any padding.
 type PackedDecoder struct {
-	in TypedReader
+	in             TypedReader
+	order          ByteOrder
+	selfDescribing bool
+	checksum       ChecksumAlgorithm
+	zeroCopy       bool
+	reuse          bool
+	scratch        []byte
+	migrations     *Migrations
+	ctx            context.Context
+	steps          int
+}
+
+// SetSelfDescribing configures whether each value is expected to be preceded
+// by a Schema, as written by a PackedEncoder that is configured in the same
+// way.
+//
+// In this mode, stored struct fields are matched to the fields of the target
+// by name. Stored fields that are missing from the target are skipped and
+// target fields that are missing from the stored data are set to zero.
+func (d *PackedDecoder) SetSelfDescribing(selfDescribing bool) {
+	d.selfDescribing = selfDescribing
+}
+
+// SetZeroCopy configures whether decoded strings and byte slices reference
+// the source buffer instead of being copied, which avoids most allocations.
+// It only has an effect on decoders that were created from a data buffer.
+//
+// In this mode, the buffer must outlive the decoded values and must not be
+// modified afterwards, since strings are expected to be immutable. Decoded
+// byte slices have their capacity limited, so appending to them does not
+// affect the buffer.
+func (d *PackedDecoder) SetZeroCopy(zeroCopy bool) {
+	d.zeroCopy = zeroCopy
+}
+
+// SetReuse configures whether decoding reuses the memory that is already
+// held by the target. Slices with sufficient capacity keep their backing
+// array, maps are cleared and refilled and strings that have not changed keep
+// their memory. Non-nil pointers are always reused.
+//
+// This allows the same target to be decoded repeatedly with close to no
+// allocations, but means that the previous contents of the target are
+// overwritten in place and should not be retained elsewhere.
+func (d *PackedDecoder) SetReuse(reuse bool) {
+	d.reuse = reuse
+}
+
+// SetMigrations configures the migrations that are used to upgrade values of
+// PackedVersioned types that were stored with an older version.
+func (d *PackedDecoder) SetMigrations(migrations *Migrations) {
+	d.migrations = migrations
+}
+
+// SetChecksum configures the decoder to expect each value to be protected by
+// a checksum of the specified algorithm, as written by a PackedEncoder that is
+// configured in the same way. Values are verified before they are decoded and
+// ErrChecksumMismatch is returned if verification fails.
+func (d *PackedDecoder) SetChecksum(algorithm ChecksumAlgorithm) error {
+	if err := algorithm.validate(); err != nil {
+		return err
+	}
+	d.checksum = algorithm
+	return nil
 }
 
 // Decode decodes the specified target value from the Reader.
 func (d *PackedDecoder) Decode(target any) error {
+	if d.checksum != ChecksumNone {
+		return d.decodeChecksummed(target)
+	}
 	value := reflect.ValueOf(target)
+	if d.selfDescribing {
+		var schema Schema
+		if err := schema.DecodePacked(d.in); err != nil {
+			return err
+		}
+		return d.decodeSchemaValue(&schema, value)
+	}
 	return d.decodeValue(value)
 }
 
+// DecodeContext decodes the specified target value from the Reader, like
+// Decode, but stops with the error of the specified context once it is
+// canceled. The context is checked periodically while the elements of arrays,
+// slices and maps are decoded.
+func (d *PackedDecoder) DecodeContext(ctx context.Context, target any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	d.ctx, d.steps = ctx, 0
+	defer func() {
+		d.ctx = nil
+	}()
+	return d.Decode(target)
+}
+
+// checkContext returns the error of the context of DecodeContext, if it has
+// been canceled, wrapped with the position at which decoding stopped.
+func (d *PackedDecoder) checkContext(index int) error {
+	if d.ctx == nil {
+		return nil
+	}
+	if d.steps++; d.steps%contextCheckInterval != 0 {
+		return nil
+	}
+	if err := d.ctx.Err(); err != nil {
+		if offset, posErr := d.position(); posErr == nil {
+			return fmt.Errorf("decoding stopped at element %d (offset %d): %w", index, offset, err)
+		}
+		return fmt.Errorf("decoding stopped at element %d: %w", index, err)
+	}
+	return nil
+}
+
 func (d *PackedDecoder) decodeValue(value reflect.Value) error {
-	if value.Type().Implements(decodableType) {
+	switch decodeHookOf(value) {
+	case hookLazy:
+		lazy, _ := asLazyDecodable(value)
+		return lazy.decodeLazy(d)
+	case hookPacked:
 		if value.Kind() == reflect.Pointer && value.IsNil() {
 			value.Set(reflect.New(value.Type().Elem()))
 		}
 		decodable := value.Interface().(PackedDecodable)
 		return decodable.DecodePacked(d.in)
+	case hookVersioned:
+		if !d.selfDescribing {
+			return d.decodeVersionedValue(value)
+		}
 	}
+	return d.decodeKindValue(value)
+}
+
+func (d *PackedDecoder) decodeKindValue(value reflect.Value) error {
 	switch kind := value.Kind(); kind {
 	case reflect.Pointer:
 		if value.IsNil() {
@@ -140,12 +301,18 @@ func (d *PackedDecoder) decodeValue(value reflect.Value) error {
 	case reflect.Array:
 		count := value.Len()
 		for i := 0; i < count; i++ {
+			if err := d.checkContext(i); err != nil {
+				return err
+			}
 			if err := d.decodeValue(value.Index(i)); err != nil {
 				return err
 			}
 		}
 		return nil
 	case reflect.Struct:
+		if !d.selfDescribing && isTagged(value.Type()) {
+			return d.decodeTaggedStruct(value)
+		}
 		fieldCount := value.NumField()
 		for i := 0; i < fieldCount; i++ {
 			field := value.Field(i)
@@ -155,22 +322,20 @@ func (d *PackedDecoder) decodeValue(value reflect.Value) error {
 		}
 		return nil
 	case reflect.Slice:
-		count, err := d.in.ReadUint64()
+		count, err := d.readSliceCount()
 		if err != nil {
 			return err
 		}
 		if value.Type().Elem().Kind() == reflect.Uint8 { // fast track
-			data := make([]uint8, count)
-			if err := d.in.ReadBytes(data); err != nil {
+			return d.decodeBytes(value, count)
+		}
+		d.prepareSlice(value, count)
+		for i := 0; i < int(count); i++ {
+			if err := d.checkContext(i); err != nil {
 				return err
 			}
-			value.Set(reflect.ValueOf(data).Convert(value.Type()))
-		} else {
-			value.Set(reflect.MakeSlice(value.Type(), int(count), int(count)))
-			for i := 0; i < int(count); i++ {
-				if err := d.decodeValue(value.Index(i)); err != nil {
-					return err
-				}
+			if err := d.decodeValue(value.Index(i)); err != nil {
+				return err
 			}
 		}
 		return nil
@@ -179,13 +344,18 @@ func (d *PackedDecoder) decodeValue(value reflect.Value) error {
 		if err != nil {
 			return err
 		}
-		value.Set(reflect.MakeMapWithSize(value.Type(), int(count)))
+		d.prepareMap(value, count)
+		entryKey := reflect.New(value.Type().Key())
+		entryValue := reflect.New(value.Type().Elem())
 		for i := 0; i < int(count); i++ {
-			entryKey := reflect.New(value.Type().Key())
+			if err := d.checkContext(i); err != nil {
+				return err
+			}
+			entryKey.Elem().SetZero()
 			if err := d.decodeValue(entryKey); err != nil {
 				return err
 			}
-			entryValue := reflect.New(value.Type().Elem())
+			entryValue.Elem().SetZero()
 			if err := d.decodeValue(entryValue); err != nil {
 				return err
 			}
@@ -193,17 +363,110 @@ func (d *PackedDecoder) decodeValue(value reflect.Value) error {
 		}
 		return nil
 	case reflect.String:
-		count, err := d.in.ReadUint64()
+		v, err := d.readString(value.String())
 		if err != nil {
 			return err
 		}
-		data := make([]byte, count)
-		if err := d.in.ReadBytes(data); err != nil {
-			return err
-		}
-		value.SetString(string(data))
+		value.SetString(v)
 		return nil
 	default:
 		return fmt.Errorf("unsupported type: %v", kind)
 	}
 }
+
+// position returns the number of bytes consumed from the input so far. It
+// fails if the input does not keep track of its position, which is the case
+// for custom TypedReader implementations.
+func (d *PackedDecoder) position() (int64, error) {
+	if reader, ok := d.in.(positionReader); ok {
+		if offset, ok := reader.position(); ok {
+			return offset, nil
+		}
+	}
+	return 0, errors.New("position of the input is not known")
+}
+
+// readData reads the specified number of bytes, referencing the source
+// buffer when in zero-copy mode.
+func (d *PackedDecoder) readData(count uint64) ([]byte, error) {
+	if count > math.MaxInt {
+		return nil, io.ErrUnexpectedEOF
+	}
+	if reader, ok := d.in.(sliceReader); ok {
+		// Reading from the buffer first ensures that a corrupted count
+		// cannot cause a large allocation.
+		data, err := reader.readSlice(int(count))
+		if err != nil || d.zeroCopy {
+			return data, err
+		}
+		return bytes.Clone(data), nil
+	}
+	data := make([]byte, count)
+	if err := d.in.ReadBytes(data); err != nil {
+		return nil, err
+	}
+	return data, nil
+}
+
+// readString reads a length-prefixed string, referencing the source buffer
+// when in zero-copy mode. When configured to reuse memory, the current string
+// is returned if it matches the stored one.
+func (d *PackedDecoder) readString(current string) (string, error) {
+	count, err := d.in.ReadUint64()
+	if err != nil {
+		return "", err
+	}
+	if d.reuse && !d.zeroCopy && count == uint64(len(current)) {
+		d.scratch = slices.Grow(d.scratch[:0], len(current))[:len(current)]
+		if err := d.in.ReadBytes(d.scratch); err != nil {
+			return "", err
+		}
+		if string(d.scratch) == current {
+			return current, nil
+		}
+		return string(d.scratch), nil
+	}
+	data, err := d.readData(count)
+	if err != nil {
+		return "", err
+	}
+	if d.zeroCopy {
+		return unsafe.String(unsafe.SliceData(data), len(data)), nil
+	}
+	return string(data), nil
+}
+
+// decodeBytes decodes the specified number of bytes into the specified byte
+// slice value.
+func (d *PackedDecoder) decodeBytes(value reflect.Value, count uint64) error {
+	if d.reuse && !d.zeroCopy && !value.IsNil() && count <= uint64(value.Cap()) {
+		value.SetLen(int(count))
+		return d.in.ReadBytes(value.Bytes())
+	}
+	data, err := d.readData(count)
+	if err != nil {
+		return err
+	}
+	value.Set(reflect.ValueOf(data).Convert(value.Type()))
+	return nil
+}
+
+// prepareSlice sets the specified slice value to the specified length,
+// keeping its backing array if configured to reuse memory.
+func (d *PackedDecoder) prepareSlice(value reflect.Value, count uint64) {
+	if d.reuse && !value.IsNil() && count <= uint64(value.Cap()) {
+		value.SetLen(int(count))
+		return
+	}
+	value.Set(reflect.MakeSlice(value.Type(), int(count), int(count)))
+}
+
+// prepareMap sets the specified map value to an empty map, clearing the
+// existing one if configured to reuse memory.
+func (d *PackedDecoder) prepareMap(value reflect.Value, count uint64) {
+	if d.reuse && !value.IsNil() {
+		value.Clear()
+		return
+	}
+	value.Set(reflect.MakeMapWithSize(value.Type(), int(count)))
+}
@@ -2,6 +2,9 @@ package gblob_test
 
 import (
 	"bytes"
+	"io"
+	"reflect"
+	"unsafe"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -310,6 +313,162 @@ var _ = Describe("PackedDecoder", func() {
 			}),
 		),
 	)
+
+	Describe("zero copy", func() {
+		type asset struct {
+			Name string
+			Data []byte
+		}
+
+		var data []byte
+
+		BeforeEach(func() {
+			data = seq(
+				0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // length
+				0x68, 0x69, // items
+				0x03, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // length
+				0x01, 0x02, 0x03, // items
+			)
+		})
+
+		It("references the source buffer", func() {
+			decoder := gblob.NewLittleEndianBufferPackedDecoder(data)
+			decoder.SetZeroCopy(true)
+
+			var target asset
+			Expect(decoder.Decode(&target)).To(Succeed())
+			Expect(target).To(Equal(asset{
+				Name: "hi",
+				Data: []byte{0x01, 0x02, 0x03},
+			}))
+
+			data[9] = 0x6F
+			data[18] = 0xFF
+			Expect(target.Name).To(Equal("ho"))
+			Expect(target.Data).To(Equal([]byte{0xFF, 0x02, 0x03}))
+			Expect(cap(target.Data)).To(Equal(3))
+		})
+
+		It("copies when not enabled", func() {
+			decoder := gblob.NewLittleEndianBufferPackedDecoder(data)
+
+			var target asset
+			Expect(decoder.Decode(&target)).To(Succeed())
+
+			data[9] = 0x6F
+			data[18] = 0xFF
+			Expect(target).To(Equal(asset{
+				Name: "hi",
+				Data: []byte{0x01, 0x02, 0x03},
+			}))
+		})
+
+		It("references the source buffer in self-describing mode", func() {
+			var buffer bytes.Buffer
+			encoder := gblob.NewLittleEndianPackedEncoder(&buffer)
+			encoder.SetSelfDescribing(true)
+			Expect(encoder.Encode(asset{Name: "hi", Data: []byte{0x01}})).To(Succeed())
+			data := buffer.Bytes()
+
+			decoder := gblob.NewLittleEndianBufferPackedDecoder(data)
+			decoder.SetSelfDescribing(true)
+			decoder.SetZeroCopy(true)
+
+			var target asset
+			Expect(decoder.Decode(&target)).To(Succeed())
+			data[len(data)-1] = 0xFF
+			Expect(target.Data).To(Equal([]byte{0xFF}))
+		})
+
+		It("reports truncated data", func() {
+			decoder := gblob.NewLittleEndianBufferPackedDecoder(data[:12])
+			decoder.SetZeroCopy(true)
+
+			var target asset
+			Expect(decoder.Decode(&target)).To(MatchError(io.ErrUnexpectedEOF))
+		})
+	})
+
+	Describe("reuse", func() {
+		type frame struct {
+			Name    string
+			Data    []byte
+			Items   []uint16
+			Lookup  map[uint8]uint8
+			Details *CustomStruct
+		}
+
+		var (
+			source frame
+			target frame
+		)
+
+		BeforeEach(func() {
+			decoder.SetReuse(true)
+			source = frame{
+				Name:   "frame",
+				Data:   []byte{0x01, 0x02},
+				Items:  []uint16{0x0102, 0x0304},
+				Lookup: map[uint8]uint8{0x01: 0x02},
+				Details: &CustomStruct{
+					A: 0x0506,
+					B: gog.PtrOf(uint8(0x08)),
+					C: 0x07,
+				},
+			}
+			target = frame{
+				Name:    "frame",
+				Data:    make([]byte, 5, 8),
+				Items:   make([]uint16, 1, 4),
+				Lookup:  map[uint8]uint8{0x03: 0x04},
+				Details: &CustomStruct{},
+			}
+		})
+
+		It("keeps the existing memory of the target", func() {
+			name := target.Name
+			data := target.Data
+			items := target.Items
+			lookup := target.Lookup
+			details := target.Details
+
+			Expect(gblob.NewLittleEndianPackedEncoder(buffer).Encode(source)).To(Succeed())
+			Expect(decoder.Decode(&target)).To(Succeed())
+			Expect(target).To(Equal(source))
+
+			Expect(unsafe.StringData(target.Name)).To(BeIdenticalTo(unsafe.StringData(name)))
+			Expect(&target.Data[0]).To(BeIdenticalTo(&data[0]))
+			Expect(&target.Items[0]).To(BeIdenticalTo(&items[0]))
+			Expect(target.Lookup).To(HaveLen(1))
+			Expect(reflect.ValueOf(target.Lookup).Pointer()).To(Equal(reflect.ValueOf(lookup).Pointer()))
+			Expect(target.Details).To(BeIdenticalTo(details))
+		})
+
+		It("allocates when capacity is insufficient", func() {
+			source.Items = []uint16{1, 2, 3, 4, 5}
+			source.Name = "other"
+			items := target.Items
+
+			Expect(gblob.NewLittleEndianPackedEncoder(buffer).Encode(source)).To(Succeed())
+			Expect(decoder.Decode(&target)).To(Succeed())
+			Expect(target).To(Equal(source))
+			Expect(&target.Items[0]).ToNot(BeIdenticalTo(&items[0]))
+		})
+
+		It("reuses memory in self-describing mode", func() {
+			data := target.Data
+			lookup := target.Lookup
+
+			encoder := gblob.NewLittleEndianPackedEncoder(buffer)
+			encoder.SetSelfDescribing(true)
+			Expect(encoder.Encode(source)).To(Succeed())
+			decoder.SetSelfDescribing(true)
+			Expect(decoder.Decode(&target)).To(Succeed())
+			Expect(target).To(Equal(source))
+			Expect(&target.Data[0]).To(BeIdenticalTo(&data[0]))
+			Expect(reflect.ValueOf(target.Lookup).Pointer()).To(Equal(reflect.ValueOf(lookup).Pointer()))
+		})
+	})
 })
 
 type testDecodable struct {
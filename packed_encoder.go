@@ -1,6 +1,8 @@
 package gblob
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"reflect"
@@ -10,6 +12,10 @@ var (
 	encodableType = reflect.TypeFor[PackedEncodable]()
 )
 
+// contextCheckInterval is the number of collection elements after which the
+// context of EncodeContext and DecodeContext is checked for cancellation.
+const contextCheckInterval = 1024
+
 // PackedEncodable is an interface that can be implemented by types that want to
 // provide a custom packed encoding.
 type PackedEncodable interface {
@@ -22,7 +28,8 @@ type PackedEncodable interface {
 // to write its output in Little Endian order.
 func NewLittleEndianPackedEncoder(out io.Writer) *PackedEncoder {
 	return &PackedEncoder{
-		out: NewLittleEndianWriter(out),
+		out:   NewLittleEndianWriter(out),
+		order: LittleEndian,
 	}
 }
 
@@ -30,26 +37,161 @@ func NewLittleEndianPackedEncoder(out io.Writer) *PackedEncoder {
 // to write its output in Big Endian order.
 func NewBigEndianPackedEncoder(out io.Writer) *PackedEncoder {
 	return &PackedEncoder{
-		out: NewBigEndianWriter(out),
+		out:   NewBigEndianWriter(out),
+		order: BigEndian,
+	}
+}
+
+// NewNativeEndianPackedEncoder creates a new PackedEncoder that is configured
+// to write its output in the byte order of the host.
+func NewNativeEndianPackedEncoder(out io.Writer) *PackedEncoder {
+	return &PackedEncoder{
+		out:   NewNativeEndianWriter(out),
+		order: NativeEndian,
 	}
 }
 
 // PackedEncoder encodes arbitrary Go objects in binary form by going through
 // each field in sequence and serializing it without any padding.
 type PackedEncoder struct {
-	out TypedWriter
+	out            TypedWriter
+	order          ByteOrder
+	selfDescribing bool
+	parallelism    int
+	checksum       ChecksumAlgorithm
+	ctx            context.Context
+	steps          int
+}
+
+// SetSelfDescribing configures whether each encoded value is preceded by
+// a Schema of its type. This allows a PackedDecoder that is configured in the
+// same way to decode the value into a Go type whose fields have changed.
+//
+// In this mode, the output of PackedEncodable types is preceded by its
+// uint64 byte length, so that it can be skipped.
+func (e *PackedEncoder) SetSelfDescribing(selfDescribing bool) {
+	e.selfDescribing = selfDescribing
+}
+
+// SetParallelism configures the number of goroutines that are used to encode
+// the elements of large slices. Values below two, which is the default, result
+// in sequential encoding.
+//
+// Slices are split into chunks that are encoded into separate buffers and
+// then written in order, so the output is the same as with sequential
+// encoding. PackedEncodable implementations need to be safe for concurrent
+// use in this mode.
+func (e *PackedEncoder) SetParallelism(parallelism int) {
+	e.parallelism = parallelism
+}
+
+// SetChecksum configures the encoder to protect each encoded value with a
+// checksum of the specified algorithm. The value is preceded by its uint64
+// byte length and followed by the checksum, so that a PackedDecoder that is
+// configured with the same algorithm can verify it before decoding.
+func (e *PackedEncoder) SetChecksum(algorithm ChecksumAlgorithm) error {
+	if err := algorithm.validate(); err != nil {
+		return err
+	}
+	e.checksum = algorithm
+	return nil
 }
 
 // Encode encodes the specified source value into the Writer.
 func (e *PackedEncoder) Encode(source any) error {
-	return e.encodeValue(reflect.ValueOf(source))
+	if e.checksum != ChecksumNone {
+		return e.encodeChecksummed(source)
+	}
+	value := reflect.ValueOf(source)
+	if e.selfDescribing {
+		schema, err := SchemaOf(value.Type())
+		if err != nil {
+			return err
+		}
+		if err := schema.EncodePacked(e.out); err != nil {
+			return err
+		}
+	}
+	return e.encodeValue(value)
+}
+
+// EncodeContext encodes the specified source value into the Writer, like
+// Encode, but stops with the error of the specified context once it is
+// canceled. The context is checked periodically while the elements of arrays,
+// slices and maps are encoded.
+func (e *PackedEncoder) EncodeContext(ctx context.Context, source any) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	e.ctx, e.steps = ctx, 0
+	defer func() {
+		e.ctx = nil
+	}()
+	return e.Encode(source)
+}
+
+// checkContext returns the error of the context of EncodeContext, if it has
+// been canceled, wrapped with the position at which encoding stopped.
+func (e *PackedEncoder) checkContext(index int) error {
+	if e.ctx == nil {
+		return nil
+	}
+	if e.steps++; e.steps%contextCheckInterval != 0 {
+		return nil
+	}
+	if err := e.ctx.Err(); err != nil {
+		var offset int64
+		if writer, ok := e.out.(positionWriter); ok {
+			offset = writer.position()
+		}
+		return &encodeStoppedError{
+			index:  index,
+			offset: offset,
+			err:    err,
+		}
+	}
+	return nil
+}
+
+// encodeStoppedError is returned by checkContext. Its offset can be adjusted
+// when the encoder writes to a buffer that is later placed into the output,
+// as is the case with parallel encoding.
+type encodeStoppedError struct {
+	index  int
+	offset int64
+	err    error
+}
+
+func (e *encodeStoppedError) Error() string {
+	return fmt.Sprintf("encoding stopped at element %d (offset %d): %v", e.index, e.offset, e.err)
+}
+
+func (e *encodeStoppedError) Unwrap() error {
+	return e.err
 }
 
 func (e *PackedEncoder) encodeValue(value reflect.Value) error {
-	if value.Type().Implements(encodableType) {
+	switch encodeHookOf(value.Type()) {
+	case hookLazy:
+		return value.Interface().(lazyEncodable).encodeLazy(e)
+	case hookPacked:
 		encodable := value.Interface().(PackedEncodable)
+		if e.selfDescribing {
+			return e.encodeSizedValue(encodable)
+		}
 		return encodable.EncodePacked(e.out)
+	case hookVersioned:
+		if e.selfDescribing {
+			break
+		}
+		if err := e.out.WriteUint32(versionOf(value.Type())); err != nil {
+			return err
+		}
 	}
+	return e.encodeKindValue(value)
+}
+
+func (e *PackedEncoder) encodeKindValue(value reflect.Value) error {
 	switch kind := value.Kind(); kind {
 	case reflect.Pointer:
 		return e.encodeValue(value.Elem())
@@ -82,6 +224,9 @@ func (e *PackedEncoder) encodeValue(value reflect.Value) error {
 	case reflect.Array:
 		count := value.Len()
 		for i := 0; i < count; i++ {
+			if err := e.checkContext(i); err != nil {
+				return err
+			}
 			if err := e.encodeValue(value.Index(i)); err != nil {
 				return err
 			}
@@ -95,8 +240,13 @@ func (e *PackedEncoder) encodeValue(value reflect.Value) error {
 		if value.Type().Elem().Kind() == reflect.Uint8 { // fast track
 			data := value.Interface().([]uint8)
 			return e.out.WriteBytes(data)
+		} else if e.parallelism > 1 && count > parallelChunkSize {
+			return e.encodeParallelElements(value)
 		} else {
 			for i := 0; i < count; i++ {
+				if err := e.checkContext(i); err != nil {
+					return err
+				}
 				if err := e.encodeValue(value.Index(i)); err != nil {
 					return err
 				}
@@ -109,7 +259,10 @@ func (e *PackedEncoder) encodeValue(value reflect.Value) error {
 			return err
 		}
 		entries := value.MapRange()
-		for entries.Next() {
+		for i := 0; entries.Next(); i++ {
+			if err := e.checkContext(i); err != nil {
+				return err
+			}
 			entryKey := entries.Key()
 			if err := e.encodeValue(entryKey); err != nil {
 				return err
@@ -132,6 +285,9 @@ func (e *PackedEncoder) encodeValue(value reflect.Value) error {
 		}
 		return nil
 	case reflect.Struct:
+		if !e.selfDescribing && isTagged(value.Type()) {
+			return e.encodeTaggedStruct(value)
+		}
 		fieldCount := value.NumField()
 		for i := 0; i < fieldCount; i++ {
 			field := value.Field(i)
@@ -144,3 +300,18 @@ func (e *PackedEncoder) encodeValue(value reflect.Value) error {
 		return fmt.Errorf("unsupported type: %v", kind)
 	}
 }
+
+func (e *PackedEncoder) encodeSizedValue(encodable PackedEncodable) error {
+	var buffer bytes.Buffer
+	writer, err := newOrderedWriter(&buffer, e.order)
+	if err != nil {
+		return err
+	}
+	if err := encodable.EncodePacked(writer); err != nil {
+		return err
+	}
+	if err := e.out.WriteUint64(uint64(buffer.Len())); err != nil {
+		return err
+	}
+	return e.out.WriteBytes(buffer.Bytes())
+}
@@ -284,6 +284,28 @@ var _ = Describe("PackedEncoder", func() {
 	)
 })
 
+var _ = Describe("NativeEndianPackedEncoder", func() {
+	Specify("round trip", func() {
+		type Item struct {
+			A uint32
+			B []float64
+			C string
+		}
+		source := Item{
+			A: 0xF1CA7632,
+			B: []float64{1.5, -3.25},
+			C: "hello",
+		}
+
+		var buffer bytes.Buffer
+		Expect(gblob.NewNativeEndianPackedEncoder(&buffer).Encode(source)).To(Succeed())
+
+		var target Item
+		Expect(gblob.NewNativeEndianPackedDecoder(&buffer).Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(source))
+	})
+})
+
 type testEncodable struct{}
 
 var _ gblob.PackedEncodable = testEncodable{}
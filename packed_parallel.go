@@ -0,0 +1,87 @@
+package gblob
+
+import (
+	"bytes"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// parallelChunkSize is the number of slice elements that are encoded
+// together by a single goroutine when parallel encoding is configured.
+const parallelChunkSize = 1024
+
+// encodeParallelElements encodes the elements of the specified slice value in
+// chunks, using up to the configured number of goroutines, and writes the
+// resulting chunks in order.
+func (e *PackedEncoder) encodeParallelElements(value reflect.Value) error {
+	count := value.Len()
+	chunkCount := (count + parallelChunkSize - 1) / parallelChunkSize
+	buffers := make([]bytes.Buffer, chunkCount)
+	errs := make([]error, chunkCount)
+
+	var (
+		group  sync.WaitGroup
+		next   atomic.Int64
+		failed atomic.Bool
+	)
+	for range min(e.parallelism, chunkCount) {
+		group.Add(1)
+		go func() {
+			defer group.Done()
+			for !failed.Load() {
+				chunk := int(next.Add(1) - 1)
+				if chunk >= chunkCount {
+					return
+				}
+				if errs[chunk] = e.encodeChunk(&buffers[chunk], value, chunk); errs[chunk] != nil {
+					failed.Store(true)
+				}
+			}
+		}()
+	}
+	group.Wait()
+
+	for i := range buffers {
+		if errs[i] != nil {
+			// All preceding chunks have been written, so the output is
+			// positioned where the failed chunk would have started.
+			var stopped *encodeStoppedError
+			if errors.As(errs[i], &stopped) {
+				if writer, ok := e.out.(positionWriter); ok {
+					stopped.offset += writer.position()
+				}
+			}
+			return errs[i]
+		}
+		if err := e.out.WriteBytes(buffers[i].Bytes()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *PackedEncoder) encodeChunk(buffer *bytes.Buffer, value reflect.Value, chunk int) error {
+	writer, err := newOrderedWriter(buffer, e.order)
+	if err != nil {
+		return err
+	}
+	encoder := &PackedEncoder{
+		out:            writer,
+		order:          e.order,
+		selfDescribing: e.selfDescribing,
+		ctx:            e.ctx,
+	}
+	start := chunk * parallelChunkSize
+	end := min(start+parallelChunkSize, value.Len())
+	for i := start; i < end; i++ {
+		if err := encoder.checkContext(i); err != nil {
+			return err
+		}
+		if err := encoder.encodeValue(value.Index(i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
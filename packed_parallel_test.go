@@ -0,0 +1,113 @@
+package gblob_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+)
+
+var _ = Describe("Parallel PackedEncoder", func() {
+	type vertex struct {
+		Position [3]float32
+		Name     string
+		Weights  []uint8
+		Custom   msgpackCustom
+	}
+
+	type mesh struct {
+		Name     string
+		Vertices []vertex
+		Indices  []uint32
+	}
+
+	var source mesh
+
+	BeforeEach(func() {
+		source = mesh{
+			Name:     "mesh",
+			Vertices: make([]vertex, 5000),
+			Indices:  make([]uint32, 3000),
+		}
+		for i := range source.Vertices {
+			source.Vertices[i] = vertex{
+				Position: [3]float32{float32(i), float32(i) * 2, float32(i) * 3},
+				Name:     fmt.Sprintf("vertex-%d", i),
+				Weights:  make([]uint8, i%7),
+				Custom:   msgpackCustom{Value: uint16(i)},
+			}
+		}
+		for i := range source.Indices {
+			source.Indices[i] = uint32(i * 3)
+		}
+	})
+
+	DescribeTable("output matches sequential encoding",
+		func(parallelism int, selfDescribing bool) {
+			var expected bytes.Buffer
+			sequential := gblob.NewBigEndianPackedEncoder(&expected)
+			sequential.SetSelfDescribing(selfDescribing)
+			Expect(sequential.Encode(source)).To(Succeed())
+
+			var actual bytes.Buffer
+			parallel := gblob.NewBigEndianPackedEncoder(&actual)
+			parallel.SetSelfDescribing(selfDescribing)
+			parallel.SetParallelism(parallelism)
+			Expect(parallel.Encode(source)).To(Succeed())
+			Expect(actual.Bytes()).To(Equal(expected.Bytes()))
+
+			var target mesh
+			decoder := gblob.NewBigEndianPackedDecoder(&actual)
+			decoder.SetSelfDescribing(selfDescribing)
+			Expect(decoder.Decode(&target)).To(Succeed())
+			Expect(target).To(Equal(source))
+		},
+		Entry("two workers", 2, false),
+		Entry("many workers", 16, false),
+		Entry("self-describing", 4, true),
+	)
+
+	It("returns errors of elements", func() {
+		var buffer bytes.Buffer
+		encoder := gblob.NewLittleEndianPackedEncoder(&buffer)
+		encoder.SetParallelism(4)
+		Expect(encoder.Encode(make([]any, 3000))).To(MatchError(ContainSubstring("unsupported type")))
+	})
+
+	It("reports the output offset when canceled", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		// The first chunk cancels after its only context check, while the
+		// second chunk waits for that, so that the second chunk stops.
+		elements := make([]cancelingElement, 3000)
+		elements[1023].cancel = cancel
+		elements[1024].wait = ctx
+
+		var buffer bytes.Buffer
+		encoder := gblob.NewLittleEndianPackedEncoder(&buffer)
+		encoder.SetParallelism(2)
+		err := encoder.EncodeContext(ctx, elements)
+		Expect(err).To(MatchError(context.Canceled))
+		Expect(err).To(MatchError(ContainSubstring("encoding stopped at element 2047 (offset %d)", 8+2047*4)))
+	})
+})
+
+type cancelingElement struct {
+	cancel context.CancelFunc
+	wait   context.Context
+}
+
+func (e cancelingElement) EncodePacked(writer gblob.TypedWriter) error {
+	if e.wait != nil {
+		<-e.wait.Done()
+	}
+	if e.cancel != nil {
+		e.cancel()
+	}
+	return writer.WriteUint32(0)
+}
@@ -0,0 +1,228 @@
+package gblob
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+)
+
+func (d *PackedDecoder) decodeSchemaValue(schema *Schema, value reflect.Value) error {
+	if value.Kind() == reflect.Interface && value.NumMethod() == 0 {
+		tree, err := d.decodeTree(schema)
+		if err != nil {
+			return err
+		}
+		value.Set(reflect.ValueOf(tree))
+		return nil
+	}
+	if schema.Kind == KindCustom {
+		if lazy, ok := asLazyDecodable(value); ok {
+			return lazy.decodeLazy(d)
+		}
+		return d.decodeSizedValue(schema, value)
+	}
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return d.decodeSchemaValue(schema, value.Elem())
+	}
+	if kind := schemaKindOf(value.Kind()); kind != schema.Kind {
+		return fmt.Errorf("cannot decode %v into %v", schema.Kind, value.Type())
+	}
+	switch schema.Kind {
+	case KindArray:
+		count := value.Len()
+		for i := range schema.Length {
+			if err := d.checkContext(i); err != nil {
+				return err
+			}
+			if i >= count {
+				if err := d.skipSchemaValue(schema.Elem); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := d.decodeSchemaValue(schema.Elem, value.Index(i)); err != nil {
+				return err
+			}
+		}
+		for i := schema.Length; i < count; i++ {
+			value.Index(i).SetZero()
+		}
+		return nil
+	case KindSlice:
+		count, err := d.readSliceCount()
+		if err != nil {
+			return err
+		}
+		if schema.Elem.Kind == KindUint8 && value.Type().Elem().Kind() == reflect.Uint8 { // fast track
+			return d.decodeBytes(value, count)
+		}
+		d.prepareSlice(value, count)
+		for i := range int(count) {
+			if err := d.checkContext(i); err != nil {
+				return err
+			}
+			if err := d.decodeSchemaValue(schema.Elem, value.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case KindMap:
+		count, err := d.in.ReadUint64()
+		if err != nil {
+			return err
+		}
+		d.prepareMap(value, count)
+		entryKey := reflect.New(value.Type().Key())
+		entryValue := reflect.New(value.Type().Elem())
+		for i := range int(count) {
+			if err := d.checkContext(i); err != nil {
+				return err
+			}
+			entryKey.Elem().SetZero()
+			if err := d.decodeSchemaValue(schema.Key, entryKey); err != nil {
+				return err
+			}
+			entryValue.Elem().SetZero()
+			if err := d.decodeSchemaValue(schema.Elem, entryValue); err != nil {
+				return err
+			}
+			value.SetMapIndex(entryKey.Elem(), entryValue.Elem())
+		}
+		return nil
+	case KindStruct:
+		valueType := value.Type()
+		decoded := make([]bool, value.NumField())
+		for _, field := range schema.Fields {
+			structField, ok := valueType.FieldByName(field.Name)
+			if !ok || len(structField.Index) != 1 {
+				if err := d.skipSchemaValue(field.Schema); err != nil {
+					return err
+				}
+				continue
+			}
+			index := structField.Index[0]
+			if err := d.decodeSchemaValue(field.Schema, value.Field(index)); err != nil {
+				return fmt.Errorf("field %s: %w", field.Name, err)
+			}
+			decoded[index] = true
+		}
+		for i, ok := range decoded {
+			if !ok && value.Field(i).CanSet() {
+				value.Field(i).SetZero()
+			}
+		}
+		return nil
+	default:
+		return d.decodeValue(value)
+	}
+}
+
+func (d *PackedDecoder) decodeSizedValue(schema *Schema, value reflect.Value) error {
+	decodable, ok := asDecodable(value)
+	if !ok {
+		return fmt.Errorf("cannot decode %v (%s) into %v", schema.Kind, schema.Name, value.Type())
+	}
+	count, err := d.in.ReadUint64()
+	if err != nil {
+		return err
+	}
+	data, err := d.readData(count)
+	if err != nil {
+		return err
+	}
+	reader, err := newOrderedReader(bytes.NewReader(data), d.order)
+	if err != nil {
+		return err
+	}
+	return decodable.DecodePacked(reader)
+}
+
+func (d *PackedDecoder) skipSchemaValue(schema *Schema) error {
+	if size, ok := schema.FixedSize(); ok {
+		return d.in.SkipBytes(size)
+	}
+	switch schema.Kind {
+	case KindString, KindCustom:
+		count, err := d.in.ReadUint64()
+		if err != nil {
+			return err
+		}
+		if count > math.MaxInt {
+			return fmt.Errorf("value length %d exceeds maximum: %w", count, io.ErrUnexpectedEOF)
+		}
+		return d.in.SkipBytes(int(count))
+	case KindArray:
+		for range schema.Length {
+			if err := d.skipSchemaValue(schema.Elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	case KindSlice:
+		count, err := d.readSliceCount()
+		if err != nil {
+			return err
+		}
+		if size, ok := schema.Elem.FixedSize(); ok {
+			if size > 0 && count > uint64(math.MaxInt/size) {
+				return fmt.Errorf("slice length %d exceeds maximum: %w", count, io.ErrUnexpectedEOF)
+			}
+			return d.in.SkipBytes(int(count) * size)
+		}
+		for range int(count) {
+			if err := d.skipSchemaValue(schema.Elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	case KindMap:
+		count, err := d.in.ReadUint64()
+		if err != nil {
+			return err
+		}
+		for range int(count) {
+			if err := d.skipSchemaValue(schema.Key); err != nil {
+				return err
+			}
+			if err := d.skipSchemaValue(schema.Elem); err != nil {
+				return err
+			}
+		}
+		return nil
+	case KindStruct:
+		for _, field := range schema.Fields {
+			if err := d.skipSchemaValue(field.Schema); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported schema kind: %v", schema.Kind)
+	}
+}
+
+// asDecodable returns the PackedDecodable implementation of the specified
+// value, allocating it if it is a nil pointer.
+func asDecodable(value reflect.Value) (PackedDecodable, bool) {
+	if value.Type().Implements(decodableType) {
+		if value.Kind() == reflect.Pointer && value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return value.Interface().(PackedDecodable), true
+	}
+	if value.CanAddr() && reflect.PointerTo(value.Type()).Implements(decodableType) {
+		return value.Addr().Interface().(PackedDecodable), true
+	}
+	if value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		return asDecodable(value.Elem())
+	}
+	return nil, false
+}
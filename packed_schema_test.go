@@ -0,0 +1,128 @@
+package gblob_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+)
+
+var _ = Describe("Self-describing PackedEncoder / PackedDecoder", func() {
+	var (
+		buffer  *bytes.Buffer
+		encoder *gblob.PackedEncoder
+		decoder *gblob.PackedDecoder
+	)
+
+	BeforeEach(func() {
+		buffer = new(bytes.Buffer)
+		encoder = gblob.NewLittleEndianPackedEncoder(buffer)
+		encoder.SetSelfDescribing(true)
+		decoder = gblob.NewLittleEndianPackedDecoder(buffer)
+		decoder.SetSelfDescribing(true)
+	})
+
+	Specify("schema precedes value", func() {
+		Expect(encoder.Encode(uint16(0xF1CA))).To(Succeed())
+		Expect(buffer.Bytes()).To(Equal([]uint8{
+			uint8(gblob.KindUint16), // schema
+			0xCA, 0xF1,              // value
+		}))
+	})
+
+	Specify("same type", func() {
+		type Item struct {
+			A uint32
+			B []string
+			C map[uint8]float64
+			D *[2]int16
+		}
+		source := Item{
+			A: 0xF1CA7632,
+			B: []string{"hello", "world"},
+			C: map[uint8]float64{1: 1.5, 2: -2.5},
+			D: &[2]int16{-1, 1},
+		}
+		Expect(encoder.Encode(source)).To(Succeed())
+
+		var target Item
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(source))
+	})
+
+	Specify("changed fields", func() {
+		type Nested struct {
+			X float32
+			Y float32
+		}
+		type OldItem struct {
+			Name    string
+			Removed []Nested
+			Count   uint16
+			Tags    map[string]string
+			Blob    []byte
+			Nested  Nested
+		}
+		type NewItem struct {
+			Added  uint64
+			Blob   []byte
+			Count  uint16
+			Name   string
+			Nested struct {
+				Y float32
+				Z float32
+			}
+		}
+		Expect(encoder.Encode(OldItem{
+			Name:    "item",
+			Removed: []Nested{{X: 1, Y: 2}, {X: 3, Y: 4}},
+			Count:   7,
+			Tags:    map[string]string{"a": "b"},
+			Blob:    []byte{0x01, 0x02},
+			Nested:  Nested{X: 5, Y: 6},
+		})).To(Succeed())
+		Expect(encoder.Encode(uint8(0x37))).To(Succeed())
+
+		target := NewItem{
+			Added: 13,
+		}
+		target.Nested.Z = 13
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target.Added).To(BeZero())
+		Expect(target.Blob).To(Equal([]byte{0x01, 0x02}))
+		Expect(target.Count).To(Equal(uint16(7)))
+		Expect(target.Name).To(Equal("item"))
+		Expect(target.Nested.Y).To(Equal(float32(6)))
+		Expect(target.Nested.Z).To(BeZero())
+
+		var next uint8
+		Expect(decoder.Decode(&next)).To(Succeed())
+		Expect(next).To(Equal(uint8(0x37)))
+	})
+
+	Specify("kind mismatch", func() {
+		Expect(encoder.Encode(struct{ A uint16 }{A: 1})).To(Succeed())
+
+		var target struct{ A string }
+		Expect(decoder.Decode(&target)).ToNot(Succeed())
+	})
+
+	Specify("custom types", func() {
+		type Item struct {
+			Custom  testEncodable
+			Removed testEncodable
+			Value   uint8
+		}
+		Expect(encoder.Encode(Item{Value: 0x13})).To(Succeed())
+
+		var target struct {
+			Custom *testDecodable
+			Value  uint8
+		}
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target.Custom).To(Equal(&testDecodable{value: 0x39}))
+		Expect(target.Value).To(Equal(uint8(0x13)))
+	})
+})
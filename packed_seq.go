@@ -0,0 +1,168 @@
+package gblob
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"math"
+	"reflect"
+)
+
+// unsizedSeqCount is written instead of the element count of a sequence
+// whose length is not known upfront. Each element of such a sequence is
+// preceded by a uint8 one and the sequence is terminated by a uint8 zero.
+const unsizedSeqCount = math.MaxUint64
+
+// EncodeSeq encodes the specified number of elements from the specified
+// sequence. The output is the same as if a []T with those elements had been
+// encoded, so it can be decoded either as a slice or through DecodeSeq.
+//
+// An error is returned if count is negative or if the sequence does not
+// produce exactly count elements.
+func EncodeSeq[T any](e *PackedEncoder, count int, seq iter.Seq[T]) error {
+	if count < 0 {
+		return fmt.Errorf("invalid sequence count: %d", count)
+	}
+	if err := e.encodeSeqSchema(reflect.TypeFor[[]T]()); err != nil {
+		return err
+	}
+	if err := e.out.WriteUint64(uint64(count)); err != nil {
+		return err
+	}
+	var written int
+	for elem := range seq {
+		if written == count {
+			return fmt.Errorf("sequence has more than %d elements", count)
+		}
+		if err := e.encodeValue(reflect.ValueOf(&elem).Elem()); err != nil {
+			return err
+		}
+		written++
+	}
+	if written != count {
+		return fmt.Errorf("sequence has %d elements instead of %d", written, count)
+	}
+	return nil
+}
+
+// EncodeUnsizedSeq encodes all elements from the specified sequence, whose
+// length does not need to be known upfront. Instead of a count, each element
+// is preceded by a marker and the sequence is followed by a terminator, so
+// the output can only be decoded through DecodeSeq.
+func EncodeUnsizedSeq[T any](e *PackedEncoder, seq iter.Seq[T]) error {
+	if err := e.encodeSeqSchema(reflect.TypeFor[[]T]()); err != nil {
+		return err
+	}
+	if err := e.out.WriteUint64(unsizedSeqCount); err != nil {
+		return err
+	}
+	for elem := range seq {
+		if err := e.out.WriteUint8(1); err != nil {
+			return err
+		}
+		if err := e.encodeValue(reflect.ValueOf(&elem).Elem()); err != nil {
+			return err
+		}
+	}
+	return e.out.WriteUint8(0)
+}
+
+func (e *PackedEncoder) encodeSeqSchema(t reflect.Type) error {
+	if !e.selfDescribing {
+		return nil
+	}
+	schema, err := SchemaOf(t)
+	if err != nil {
+		return err
+	}
+	return schema.EncodePacked(e.out)
+}
+
+// DecodeSeq returns a sequence that decodes the elements of a []T one at a
+// time, without allocating the whole slice. It accepts the output of both
+// EncodeSeq and EncodeUnsizedSeq, as well as that of encoding a []T.
+//
+// If decoding fails, the error is yielded and the sequence stops. If the
+// caller stops early, the decoder is left in the middle of the sequence.
+// When the decoder is configured to reuse memory, each element is decoded
+// into the memory of the previous one.
+func DecodeSeq[T any](d *PackedDecoder) iter.Seq2[T, error] {
+	return func(yield func(T, error) bool) {
+		var zero T
+		schema, err := d.decodeSeqSchema()
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		count, err := d.in.ReadUint64()
+		if err != nil {
+			yield(zero, err)
+			return
+		}
+		unsized := count == unsizedSeqCount
+		var elem T
+		for i := uint64(0); unsized || i < count; i++ {
+			if unsized {
+				marker, err := d.in.ReadUint8()
+				if err != nil {
+					yield(zero, err)
+					return
+				}
+				if marker == 0 {
+					return
+				}
+				if marker != 1 {
+					yield(zero, fmt.Errorf("element %d: invalid sequence marker %d", i, marker))
+					return
+				}
+			}
+			if !d.reuse {
+				elem = zero
+			}
+			if schema != nil {
+				err = d.decodeSchemaValue(schema.Elem, reflect.ValueOf(&elem))
+			} else {
+				err = d.decodeValue(reflect.ValueOf(&elem))
+			}
+			if err != nil {
+				yield(zero, fmt.Errorf("element %d: %w", i, err))
+				return
+			}
+			if !yield(elem, nil) {
+				return
+			}
+		}
+	}
+}
+
+// readSliceCount reads the element count of a slice. Sequences that were
+// encoded by EncodeUnsizedSeq have no count and are rejected, since they can
+// only be decoded through DecodeSeq.
+func (d *PackedDecoder) readSliceCount() (uint64, error) {
+	count, err := d.in.ReadUint64()
+	if err != nil {
+		return 0, err
+	}
+	if count == unsizedSeqCount {
+		return 0, errors.New("unsized sequence needs to be decoded through DecodeSeq")
+	}
+	if count > math.MaxInt {
+		return 0, fmt.Errorf("slice length %d exceeds maximum: %w", count, io.ErrUnexpectedEOF)
+	}
+	return count, nil
+}
+
+func (d *PackedDecoder) decodeSeqSchema() (*Schema, error) {
+	if !d.selfDescribing {
+		return nil, nil
+	}
+	schema := new(Schema)
+	if err := schema.DecodePacked(d.in); err != nil {
+		return nil, err
+	}
+	if schema.Kind != KindSlice {
+		return nil, fmt.Errorf("cannot decode %v as sequence", schema.Kind)
+	}
+	return schema, nil
+}
@@ -0,0 +1,155 @@
+package gblob_test
+
+import (
+	"bytes"
+	"io"
+	"slices"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+)
+
+var _ = Describe("Seq", func() {
+	type record struct {
+		ID   uint32
+		Name string
+	}
+
+	var (
+		buffer  *bytes.Buffer
+		encoder *gblob.PackedEncoder
+		decoder *gblob.PackedDecoder
+		records []record
+	)
+
+	collect := func(seq func(func(record, error) bool)) ([]record, error) {
+		var result []record
+		for elem, err := range seq {
+			if err != nil {
+				return result, err
+			}
+			result = append(result, elem)
+		}
+		return result, nil
+	}
+
+	BeforeEach(func() {
+		buffer = new(bytes.Buffer)
+		encoder = gblob.NewLittleEndianPackedEncoder(buffer)
+		decoder = gblob.NewLittleEndianPackedDecoder(buffer)
+		records = []record{
+			{ID: 1, Name: "first"},
+			{ID: 2, Name: "second"},
+			{ID: 3, Name: "third"},
+		}
+	})
+
+	It("encodes a sized sequence like a slice", func() {
+		Expect(gblob.EncodeSeq(encoder, len(records), slices.Values(records))).To(Succeed())
+
+		var expected bytes.Buffer
+		Expect(gblob.NewLittleEndianPackedEncoder(&expected).Encode(records)).To(Succeed())
+		Expect(buffer.Bytes()).To(Equal(expected.Bytes()))
+	})
+
+	It("rejects sequences with a different count", func() {
+		Expect(gblob.EncodeSeq(encoder, 2, slices.Values(records))).ToNot(Succeed())
+		Expect(gblob.EncodeSeq(encoder, 4, slices.Values(records))).ToNot(Succeed())
+	})
+
+	It("rejects a negative count", func() {
+		Expect(gblob.EncodeSeq(encoder, -1, slices.Values(records))).ToNot(Succeed())
+		Expect(buffer.Len()).To(BeZero())
+	})
+
+	It("decodes an encoded slice", func() {
+		Expect(encoder.Encode(records)).To(Succeed())
+
+		result, err := collect(gblob.DecodeSeq[record](decoder))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(records))
+	})
+
+	It("decodes an unsized sequence", func() {
+		Expect(gblob.EncodeUnsizedSeq(encoder, slices.Values(records))).To(Succeed())
+		Expect(encoder.Encode(uint8(0xAB))).To(Succeed())
+
+		result, err := collect(gblob.DecodeSeq[record](decoder))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(records))
+
+		var trailer uint8
+		Expect(decoder.Decode(&trailer)).To(Succeed())
+		Expect(trailer).To(Equal(uint8(0xAB)))
+	})
+
+	It("rejects an unsized sequence as a slice", func() {
+		Expect(gblob.EncodeUnsizedSeq(encoder, slices.Values(records))).To(Succeed())
+
+		var target []record
+		Expect(decoder.Decode(&target)).To(MatchError(ContainSubstring("DecodeSeq")))
+
+		buffer.Reset()
+		Expect(gblob.EncodeUnsizedSeq(encoder, slices.Values([]uint8{1, 2}))).To(Succeed())
+		var data []uint8
+		Expect(decoder.Decode(&data)).To(MatchError(ContainSubstring("DecodeSeq")))
+	})
+
+	It("rejects an unsized sequence as a slice in self-describing mode", func() {
+		encoder.SetSelfDescribing(true)
+		decoder.SetSelfDescribing(true)
+		Expect(gblob.EncodeUnsizedSeq(encoder, slices.Values(records))).To(Succeed())
+
+		var target []record
+		Expect(decoder.Decode(&target)).To(MatchError(ContainSubstring("DecodeSeq")))
+	})
+
+	It("rejects invalid markers of an unsized sequence", func() {
+		Expect(gblob.EncodeUnsizedSeq(encoder, slices.Values(records))).To(Succeed())
+		buffer.Bytes()[8] = 0x02
+
+		result, err := collect(gblob.DecodeSeq[record](decoder))
+		Expect(err).To(MatchError(ContainSubstring("invalid sequence marker 2")))
+		Expect(result).To(BeEmpty())
+	})
+
+	It("supports stopping early", func() {
+		Expect(encoder.Encode(records)).To(Succeed())
+
+		var result []record
+		for elem, err := range gblob.DecodeSeq[record](decoder) {
+			Expect(err).ToNot(HaveOccurred())
+			result = append(result, elem)
+			if len(result) == 2 {
+				break
+			}
+		}
+		Expect(result).To(Equal(records[:2]))
+	})
+
+	It("yields decoding errors", func() {
+		Expect(encoder.Encode(records)).To(Succeed())
+		buffer.Truncate(buffer.Len() - 2)
+
+		result, err := collect(gblob.DecodeSeq[record](decoder))
+		Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+		Expect(result).To(Equal(records[:2]))
+	})
+
+	It("supports self-describing mode", func() {
+		encoder.SetSelfDescribing(true)
+		decoder.SetSelfDescribing(true)
+		Expect(gblob.EncodeUnsizedSeq(encoder, slices.Values(records))).To(Succeed())
+		Expect(gblob.EncodeSeq(encoder, len(records), slices.Values(records))).To(Succeed())
+
+		result, err := collect(gblob.DecodeSeq[record](decoder))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(records))
+
+		var target []record
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(records))
+	})
+})
@@ -0,0 +1,335 @@
+package gblob
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+var kindTypes = [...]reflect.Type{
+	KindBool:    reflect.TypeFor[bool](),
+	KindUint8:   reflect.TypeFor[uint8](),
+	KindInt8:    reflect.TypeFor[int8](),
+	KindUint16:  reflect.TypeFor[uint16](),
+	KindInt16:   reflect.TypeFor[int16](),
+	KindUint32:  reflect.TypeFor[uint32](),
+	KindInt32:   reflect.TypeFor[int32](),
+	KindUint64:  reflect.TypeFor[uint64](),
+	KindInt64:   reflect.TypeFor[int64](),
+	KindFloat32: reflect.TypeFor[float32](),
+	KindFloat64: reflect.TypeFor[float64](),
+	KindString:  reflect.TypeFor[string](),
+}
+
+// DecodeSchema decodes a value that is laid out according to the specified
+// schema and returns it as a generic tree, without the need for a Go type.
+//
+// Scalar values are represented by the Go type that matches their Kind
+// (e.g. uint16 for KindUint16). Arrays and slices are represented by []any,
+// except for slices of KindUint8, which are represented by []byte. Maps are
+// represented by map[any]any and structs by map[string]any, keyed by field
+// name. Values of KindCustom are represented by their raw []byte encoding and
+// can only be decoded in self-describing mode, where they are length-prefixed.
+//
+// In self-describing mode, the Schema that precedes the value is used
+// instead, in which case the specified schema can be nil. Decoding into an
+// empty interface with Decode has the same effect.
+func (d *PackedDecoder) DecodeSchema(schema *Schema) (any, error) {
+	if d.selfDescribing {
+		schema = new(Schema)
+		if err := schema.DecodePacked(d.in); err != nil {
+			return nil, err
+		}
+	}
+	return d.decodeTree(schema)
+}
+
+func (d *PackedDecoder) decodeTree(schema *Schema) (any, error) {
+	switch schema.Kind {
+	case KindBool:
+		v, err := d.in.ReadUint8()
+		return v > 0x00, err
+	case KindUint8:
+		return d.in.ReadUint8()
+	case KindInt8:
+		return d.in.ReadInt8()
+	case KindUint16:
+		return d.in.ReadUint16()
+	case KindInt16:
+		return d.in.ReadInt16()
+	case KindUint32:
+		return d.in.ReadUint32()
+	case KindInt32:
+		return d.in.ReadInt32()
+	case KindUint64:
+		return d.in.ReadUint64()
+	case KindInt64:
+		return d.in.ReadInt64()
+	case KindFloat32:
+		return d.in.ReadFloat32()
+	case KindFloat64:
+		return d.in.ReadFloat64()
+	case KindString:
+		return d.readString("")
+	case KindArray:
+		return d.decodeTreeElements(schema.Elem, schema.Length)
+	case KindSlice:
+		count, err := d.readSliceCount()
+		if err != nil {
+			return nil, err
+		}
+		if schema.Elem.Kind == KindUint8 { // fast track
+			return d.readData(count)
+		}
+		return d.decodeTreeElements(schema.Elem, int(count))
+	case KindMap:
+		count, err := d.in.ReadUint64()
+		if err != nil {
+			return nil, err
+		}
+		result := make(map[any]any, min(count, preallocLimit))
+		for range int(count) {
+			key, err := d.decodeTree(schema.Key)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.TypeOf(key).Comparable() {
+				return nil, fmt.Errorf("unsupported map key kind: %v", schema.Key.Kind)
+			}
+			value, err := d.decodeTree(schema.Elem)
+			if err != nil {
+				return nil, err
+			}
+			result[key] = value
+		}
+		return result, nil
+	case KindStruct:
+		result := make(map[string]any, len(schema.Fields))
+		for _, field := range schema.Fields {
+			value, err := d.decodeTree(field.Schema)
+			if err != nil {
+				return nil, fmt.Errorf("field %s: %w", field.Name, err)
+			}
+			result[field.Name] = value
+		}
+		return result, nil
+	case KindCustom:
+		if !d.selfDescribing {
+			return nil, fmt.Errorf("cannot decode %v (%s) without self-describing mode", schema.Kind, schema.Name)
+		}
+		count, err := d.in.ReadUint64()
+		if err != nil {
+			return nil, err
+		}
+		return d.readData(count)
+	default:
+		return nil, fmt.Errorf("unsupported schema kind: %v", schema.Kind)
+	}
+}
+
+func (d *PackedDecoder) decodeTreeElements(schema *Schema, count int) ([]any, error) {
+	if count < 0 {
+		return nil, fmt.Errorf("invalid element count: %d", count)
+	}
+	result := make([]any, 0, min(count, preallocLimit))
+	for range count {
+		value, err := d.decodeTree(schema)
+		if err != nil {
+			return nil, err
+		}
+		result = append(result, value)
+	}
+	return result, nil
+}
+
+// EncodeSchema encodes a generic tree, as returned by
+// PackedDecoder.DecodeSchema, according to the specified schema. The output
+// is the same as if a Go value of the described type had been encoded.
+//
+// Numeric values can be of any Go numeric type, as long as they fit into the
+// Kind of the schema without loss. Arrays and slices can be of any slice
+// type and maps of any map type. Struct fields that are missing from the
+// map[string]any are encoded as zero values, whereas entries that do not
+// match a field result in an error.
+//
+// In self-describing mode, the schema is written before the value.
+func (e *PackedEncoder) EncodeSchema(schema *Schema, tree any) error {
+	if e.selfDescribing {
+		if err := schema.EncodePacked(e.out); err != nil {
+			return err
+		}
+	}
+	return e.encodeTree(schema, tree)
+}
+
+func (e *PackedEncoder) encodeTree(schema *Schema, tree any) error {
+	if size := schema.Kind.Size(); size > 0 || schema.Kind == KindString {
+		value, err := treeScalar(schema.Kind, tree)
+		if err != nil {
+			return err
+		}
+		return e.encodeKindValue(value)
+	}
+	value := reflect.ValueOf(tree)
+	switch schema.Kind {
+	case KindArray:
+		if tree == nil {
+			for range schema.Length {
+				if err := e.encodeTree(schema.Elem, nil); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		if kind := value.Kind(); kind != reflect.Slice && kind != reflect.Array {
+			return fmt.Errorf("cannot encode %T as %v", tree, schema.Kind)
+		}
+		if value.Len() != schema.Length {
+			return fmt.Errorf("cannot encode %d elements as array of length %d", value.Len(), schema.Length)
+		}
+		return e.encodeTreeElements(schema.Elem, value)
+	case KindSlice:
+		if tree == nil {
+			return e.out.WriteUint64(0)
+		}
+		if kind := value.Kind(); kind != reflect.Slice && kind != reflect.Array {
+			return fmt.Errorf("cannot encode %T as %v", tree, schema.Kind)
+		}
+		if err := e.out.WriteUint64(uint64(value.Len())); err != nil {
+			return err
+		}
+		if data, ok := tree.([]byte); ok && schema.Elem.Kind == KindUint8 { // fast track
+			return e.out.WriteBytes(data)
+		}
+		return e.encodeTreeElements(schema.Elem, value)
+	case KindMap:
+		if tree == nil {
+			return e.out.WriteUint64(0)
+		}
+		if value.Kind() != reflect.Map {
+			return fmt.Errorf("cannot encode %T as %v", tree, schema.Kind)
+		}
+		if err := e.out.WriteUint64(uint64(value.Len())); err != nil {
+			return err
+		}
+		entries := value.MapRange()
+		for entries.Next() {
+			if err := e.encodeTree(schema.Key, entries.Key().Interface()); err != nil {
+				return err
+			}
+			if err := e.encodeTree(schema.Elem, entries.Value().Interface()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case KindStruct:
+		fields, ok := tree.(map[string]any)
+		if !ok && tree != nil {
+			return fmt.Errorf("cannot encode %T as %v", tree, schema.Kind)
+		}
+		for name := range fields {
+			if !hasSchemaField(schema, name) {
+				return fmt.Errorf("unknown field: %s", name)
+			}
+		}
+		for _, field := range schema.Fields {
+			if err := e.encodeTree(field.Schema, fields[field.Name]); err != nil {
+				return fmt.Errorf("field %s: %w", field.Name, err)
+			}
+		}
+		return nil
+	case KindCustom:
+		data, ok := tree.([]byte)
+		if !ok {
+			return fmt.Errorf("cannot encode %T as %v (%s)", tree, schema.Kind, schema.Name)
+		}
+		if e.selfDescribing {
+			if err := e.out.WriteUint64(uint64(len(data))); err != nil {
+				return err
+			}
+		}
+		return e.out.WriteBytes(data)
+	default:
+		return fmt.Errorf("unsupported schema kind: %v", schema.Kind)
+	}
+}
+
+func (e *PackedEncoder) encodeTreeElements(schema *Schema, value reflect.Value) error {
+	for i := range value.Len() {
+		if err := e.encodeTree(schema, value.Index(i).Interface()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func hasSchemaField(schema *Schema, name string) bool {
+	for _, field := range schema.Fields {
+		if field.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// treeScalar converts the specified tree value into a value of the Go type
+// that matches the specified scalar kind, making sure that no information is
+// lost. A nil tree value results in the zero value.
+func treeScalar(kind Kind, tree any) (reflect.Value, error) {
+	target := reflect.New(kindTypes[kind]).Elem()
+	if tree == nil {
+		return target, nil
+	}
+	source := reflect.ValueOf(tree)
+	mismatch := func() (reflect.Value, error) {
+		return reflect.Value{}, fmt.Errorf("cannot encode %T(%v) as %v", tree, tree, kind)
+	}
+	switch {
+	case target.Kind() == reflect.Bool || target.Kind() == reflect.String:
+		if source.Kind() != target.Kind() {
+			return mismatch()
+		}
+		target.Set(source.Convert(target.Type()))
+	case source.CanInt():
+		v := source.Int()
+		switch {
+		case target.CanInt() && !target.OverflowInt(v):
+			target.SetInt(v)
+		case target.CanUint() && v >= 0 && !target.OverflowUint(uint64(v)):
+			target.SetUint(uint64(v))
+		case target.CanFloat():
+			target.SetFloat(float64(v))
+		default:
+			return mismatch()
+		}
+	case source.CanUint():
+		v := source.Uint()
+		switch {
+		case target.CanInt() && v <= math.MaxInt64 && !target.OverflowInt(int64(v)):
+			target.SetInt(int64(v))
+		case target.CanUint() && !target.OverflowUint(v):
+			target.SetUint(v)
+		case target.CanFloat():
+			target.SetFloat(float64(v))
+		default:
+			return mismatch()
+		}
+	case source.CanFloat():
+		v := source.Float()
+		switch {
+		case target.CanFloat() && (math.IsInf(v, 0) || math.IsNaN(v) || !target.OverflowFloat(v)):
+			target.SetFloat(v)
+		case v != math.Trunc(v):
+			return mismatch()
+		case target.CanInt() && v >= math.MinInt64 && v < math.MaxInt64 && !target.OverflowInt(int64(v)):
+			target.SetInt(int64(v))
+		case target.CanUint() && v >= 0 && v < math.MaxUint64 && !target.OverflowUint(uint64(v)):
+			target.SetUint(uint64(v))
+		default:
+			return mismatch()
+		}
+	default:
+		return mismatch()
+	}
+	return target, nil
+}
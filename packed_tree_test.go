@@ -0,0 +1,176 @@
+package gblob_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+)
+
+var _ = Describe("Generic tree encoding", func() {
+	type Nested struct {
+		X float32
+		Y int16
+	}
+
+	type Item struct {
+		ID     uint32
+		Name   string
+		Data   []byte
+		Points []Nested
+		Pair   [2]bool
+		Lookup map[string]int64
+		Custom msgpackCustom
+	}
+
+	var (
+		buffer *bytes.Buffer
+		schema *gblob.Schema
+		source Item
+		tree   map[string]any
+	)
+
+	BeforeEach(func() {
+		buffer = new(bytes.Buffer)
+
+		var err error
+		schema, err = gblob.SchemaFor[Item]()
+		Expect(err).ToNot(HaveOccurred())
+
+		source = Item{
+			ID:     0xF1CA7632,
+			Name:   "item",
+			Data:   []byte{0x01, 0x02},
+			Points: []Nested{{X: 1.5, Y: -1}},
+			Pair:   [2]bool{true, false},
+			Lookup: map[string]int64{"a": -5},
+			Custom: msgpackCustom{Value: 0x1234},
+		}
+		tree = map[string]any{
+			"ID":     uint32(0xF1CA7632),
+			"Name":   "item",
+			"Data":   []byte{0x01, 0x02},
+			"Points": []any{map[string]any{"X": float32(1.5), "Y": int16(-1)}},
+			"Pair":   []any{true, false},
+			"Lookup": map[any]any{"a": int64(-5)},
+			"Custom": []byte{0x34, 0x12},
+		}
+	})
+
+	Specify("DecodeSchema", func() {
+		encoder := gblob.NewLittleEndianPackedEncoder(buffer)
+		encoder.SetSelfDescribing(true)
+		Expect(encoder.Encode(source)).To(Succeed())
+
+		decoder := gblob.NewLittleEndianPackedDecoder(buffer)
+		decoder.SetSelfDescribing(true)
+		Expect(decoder.DecodeSchema(nil)).To(Equal(tree))
+	})
+
+	Specify("Decode into any", func() {
+		encoder := gblob.NewLittleEndianPackedEncoder(buffer)
+		encoder.SetSelfDescribing(true)
+		Expect(encoder.Encode(source)).To(Succeed())
+
+		decoder := gblob.NewLittleEndianPackedDecoder(buffer)
+		decoder.SetSelfDescribing(true)
+		var target any
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(tree))
+	})
+
+	Specify("EncodeSchema", func() {
+		encoder := gblob.NewLittleEndianPackedEncoder(buffer)
+		Expect(encoder.EncodeSchema(schema, map[string]any{
+			"ID":     uint32(0xF1CA7632),
+			"Name":   "item",
+			"Data":   []byte{0x01, 0x02},
+			"Points": []any{map[string]any{"X": float32(1.5), "Y": int16(-1)}},
+			"Pair":   []bool{true, false},
+			"Lookup": map[string]any{"a": -5},
+			"Custom": []byte{0x34, 0x12},
+		})).To(Succeed())
+
+		decoder := gblob.NewLittleEndianPackedDecoder(buffer)
+		var target Item
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(source))
+	})
+
+	Specify("EncodeSchema with self-describing mode", func() {
+		encoder := gblob.NewLittleEndianPackedEncoder(buffer)
+		encoder.SetSelfDescribing(true)
+		Expect(encoder.EncodeSchema(schema, tree)).To(Succeed())
+
+		decoder := gblob.NewLittleEndianPackedDecoder(buffer)
+		decoder.SetSelfDescribing(true)
+		var target Item
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(source))
+	})
+
+	Specify("EncodeSchema with missing and converted values", func() {
+		encoder := gblob.NewLittleEndianPackedEncoder(buffer)
+		Expect(encoder.EncodeSchema(schema, map[string]any{
+			"ID":     float64(7),
+			"Points": []any{map[string]any{"X": 2, "Y": uint8(3)}},
+			"Custom": []byte{0x00, 0x00},
+		})).To(Succeed())
+
+		decoder := gblob.NewLittleEndianPackedDecoder(buffer)
+		var target Item
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(Item{
+			ID:     7,
+			Data:   []byte{},
+			Points: []Nested{{X: 2.0, Y: 3}},
+			Lookup: map[string]int64{},
+		}))
+	})
+
+	DescribeTable("EncodeSchema errors",
+		func(tree any) {
+			encoder := gblob.NewLittleEndianPackedEncoder(buffer)
+			Expect(encoder.EncodeSchema(schema, tree)).ToNot(Succeed())
+		},
+		Entry("unknown field", map[string]any{"Unknown": 1}),
+		Entry("overflow", map[string]any{"ID": -1}),
+		Entry("fraction", map[string]any{"ID": 1.5}),
+		Entry("kind mismatch", map[string]any{"Name": 1}),
+		Entry("array length", map[string]any{"Pair": []any{true}}),
+		Entry("not a struct", []any{}),
+	)
+
+	DescribeTable("DecodeSchema with malformed input",
+		func(schema *gblob.Schema, data []byte) {
+			buffer.Write(data)
+			decoder := gblob.NewLittleEndianPackedDecoder(buffer)
+			_, err := decoder.DecodeSchema(schema)
+			Expect(err).To(HaveOccurred())
+		},
+		Entry("huge slice count",
+			&gblob.Schema{Kind: gblob.KindSlice, Elem: &gblob.Schema{Kind: gblob.KindBool}},
+			[]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10, 0x01},
+		),
+		Entry("huge map count",
+			&gblob.Schema{Kind: gblob.KindMap, Key: &gblob.Schema{Kind: gblob.KindBool}, Elem: &gblob.Schema{Kind: gblob.KindBool}},
+			[]byte{0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10, 0x01},
+		),
+		Entry("huge array length",
+			&gblob.Schema{Kind: gblob.KindArray, Length: 1 << 60, Elem: &gblob.Schema{Kind: gblob.KindBool}},
+			[]byte{0x01},
+		),
+		Entry("negative array length",
+			&gblob.Schema{Kind: gblob.KindArray, Length: -1, Elem: &gblob.Schema{Kind: gblob.KindBool}},
+			[]byte{0x01},
+		),
+	)
+
+	Specify("custom values need self-describing mode", func() {
+		decoder := gblob.NewLittleEndianPackedDecoder(buffer)
+		_, err := decoder.DecodeSchema(&gblob.Schema{Kind: gblob.KindCustom, Name: "x"})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,521 @@
+package gblob
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var protoFieldsCache sync.Map // map[reflect.Type]protoFieldsEntry
+
+// NewProtoEncoder creates a new ProtoEncoder that writes to the specified
+// out Writer.
+func NewProtoEncoder(out io.Writer) *ProtoEncoder {
+	return &ProtoEncoder{
+		out: out,
+	}
+}
+
+// ProtoEncoder encodes Go structs in protobuf wire format.
+//
+// Fields are described through `protobuf:"<encoding>,<number>,..."` struct
+// tags, in the same format that is used by generated protobuf Go code. The
+// supported encodings are varint, zigzag32, zigzag64, fixed32, fixed64 and
+// bytes. Map fields additionally need protobuf_key and protobuf_val tags.
+//
+// Scalar fields with zero values are omitted, as is the case with proto3.
+// Pointer fields are omitted only when nil.
+type ProtoEncoder struct {
+	out io.Writer
+}
+
+// Encode encodes the specified message, which needs to be a struct or a
+// pointer to a struct.
+func (e *ProtoEncoder) Encode(message any) error {
+	value := reflect.ValueOf(message)
+	for value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("unsupported message type: %v", value.Type())
+	}
+	var buffer bytes.Buffer
+	if err := encodeProtoMessage(NewProtoWriter(&buffer), value); err != nil {
+		return err
+	}
+	_, err := e.out.Write(buffer.Bytes())
+	return err
+}
+
+// NewProtoDecoder creates a new ProtoDecoder that reads from the specified
+// in Reader.
+func NewProtoDecoder(in io.Reader) *ProtoDecoder {
+	return &ProtoDecoder{
+		in: in,
+	}
+}
+
+// ProtoDecoder decodes Go structs from protobuf wire format.
+//
+// See ProtoEncoder for the supported struct tags. Fields that are not known
+// to the target type are skipped.
+type ProtoDecoder struct {
+	in io.Reader
+}
+
+// Decode decodes the whole input into the specified target, which needs to
+// be a pointer to a struct. Since protobuf messages are not delimited, the
+// input is read until its end.
+func (d *ProtoDecoder) Decode(target any) error {
+	value := reflect.ValueOf(target)
+	if value.Kind() != reflect.Pointer || value.IsNil() {
+		return fmt.Errorf("target needs to be a non-nil pointer")
+	}
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		value = value.Elem()
+	}
+	if value.Kind() != reflect.Struct {
+		return fmt.Errorf("unsupported message type: %v", value.Type())
+	}
+	data, err := io.ReadAll(d.in)
+	if err != nil {
+		return err
+	}
+	return decodeProtoMessage(data, value)
+}
+
+type protoField struct {
+	index    int
+	number   uint32
+	encoding string
+	packed   bool
+	key      *protoField
+	value    *protoField
+}
+
+type protoFieldsEntry struct {
+	fields []protoField
+	err    error
+}
+
+func protoFieldsOf(t reflect.Type) ([]protoField, error) {
+	if entry, ok := protoFieldsCache.Load(t); ok {
+		return entry.(protoFieldsEntry).fields, entry.(protoFieldsEntry).err
+	}
+	fields, err := evalProtoFields(t)
+	protoFieldsCache.Store(t, protoFieldsEntry{
+		fields: fields,
+		err:    err,
+	})
+	return fields, err
+}
+
+func evalProtoFields(t reflect.Type) ([]protoField, error) {
+	var result []protoField
+	for i := range t.NumField() {
+		structField := t.Field(i)
+		tag, ok := structField.Tag.Lookup("protobuf")
+		if !ok {
+			continue
+		}
+		field, err := parseProtoTag(tag)
+		if err != nil {
+			return nil, fmt.Errorf("field %v.%s: %w", t, structField.Name, err)
+		}
+		field.index = i
+		if structField.Type.Kind() == reflect.Map {
+			key, err := parseProtoTag(structField.Tag.Get("protobuf_key"))
+			if err != nil {
+				return nil, fmt.Errorf("field %v.%s key: %w", t, structField.Name, err)
+			}
+			value, err := parseProtoTag(structField.Tag.Get("protobuf_val"))
+			if err != nil {
+				return nil, fmt.Errorf("field %v.%s value: %w", t, structField.Name, err)
+			}
+			field.key = &key
+			field.value = &value
+		}
+		result = append(result, field)
+	}
+	return result, nil
+}
+
+func parseProtoTag(tag string) (protoField, error) {
+	parts := strings.Split(tag, ",")
+	if len(parts) < 2 {
+		return protoField{}, fmt.Errorf("invalid protobuf tag: %q", tag)
+	}
+	number, err := strconv.ParseUint(parts[1], 10, 29)
+	if err != nil || number == 0 {
+		return protoField{}, fmt.Errorf("invalid field number: %q", parts[1])
+	}
+	field := protoField{
+		number:   uint32(number),
+		encoding: parts[0],
+	}
+	if _, err := protoWireTypeOf(field.encoding); err != nil {
+		return protoField{}, err
+	}
+	for _, option := range parts[2:] {
+		if option == "packed" {
+			field.packed = true
+		}
+	}
+	return field, nil
+}
+
+func protoWireTypeOf(encoding string) (ProtoWireType, error) {
+	switch encoding {
+	case "varint", "zigzag32", "zigzag64":
+		return ProtoVarint, nil
+	case "fixed32":
+		return ProtoFixed32, nil
+	case "fixed64":
+		return ProtoFixed64, nil
+	case "bytes":
+		return ProtoBytes, nil
+	default:
+		return 0, fmt.Errorf("unsupported protobuf encoding: %q", encoding)
+	}
+}
+
+func isProtoBytesValue(t reflect.Type) bool {
+	return t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8
+}
+
+func encodeProtoMessage(writer *ProtoWriter, value reflect.Value) error {
+	fields, err := protoFieldsOf(value.Type())
+	if err != nil {
+		return err
+	}
+	for _, field := range fields {
+		if err := encodeProtoField(writer, field, value.Field(field.index)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func encodeProtoField(writer *ProtoWriter, field protoField, value reflect.Value) error {
+	wireType, _ := protoWireTypeOf(field.encoding)
+	switch {
+	case value.Kind() == reflect.Map:
+		entries := value.MapRange()
+		for entries.Next() {
+			var buffer bytes.Buffer
+			entryWriter := NewProtoWriter(&buffer)
+			if err := encodeProtoEntry(entryWriter, *field.key, entries.Key()); err != nil {
+				return err
+			}
+			if err := encodeProtoEntry(entryWriter, *field.value, entries.Value()); err != nil {
+				return err
+			}
+			if err := writer.WriteTag(field.number, ProtoBytes); err != nil {
+				return err
+			}
+			if err := writer.WriteBytes(buffer.Bytes()); err != nil {
+				return err
+			}
+		}
+		return nil
+	case value.Kind() == reflect.Slice && !isProtoBytesValue(value.Type()):
+		if value.Len() == 0 {
+			return nil
+		}
+		if field.packed && wireType != ProtoBytes {
+			var buffer bytes.Buffer
+			packedWriter := NewProtoWriter(&buffer)
+			for i := range value.Len() {
+				if err := encodeProtoValue(packedWriter, field.encoding, value.Index(i)); err != nil {
+					return err
+				}
+			}
+			if err := writer.WriteTag(field.number, ProtoBytes); err != nil {
+				return err
+			}
+			return writer.WriteBytes(buffer.Bytes())
+		}
+		for i := range value.Len() {
+			if err := encodeProtoEntry(writer, field, value.Index(i)); err != nil {
+				return err
+			}
+		}
+		return nil
+	case value.Kind() == reflect.Pointer:
+		if value.IsNil() {
+			return nil
+		}
+		return encodeProtoEntry(writer, field, value)
+	case value.Kind() != reflect.Struct && value.IsZero():
+		return nil
+	default:
+		return encodeProtoEntry(writer, field, value)
+	}
+}
+
+func encodeProtoEntry(writer *ProtoWriter, field protoField, value reflect.Value) error {
+	wireType, _ := protoWireTypeOf(field.encoding)
+	if err := writer.WriteTag(field.number, wireType); err != nil {
+		return err
+	}
+	return encodeProtoValue(writer, field.encoding, value)
+}
+
+func encodeProtoValue(writer *ProtoWriter, encoding string, value reflect.Value) error {
+	for value.Kind() == reflect.Pointer {
+		value = value.Elem()
+	}
+	switch kind := value.Kind(); encoding {
+	case "varint":
+		switch kind {
+		case reflect.Bool:
+			if value.Bool() {
+				return writer.WriteVarint(1)
+			}
+			return writer.WriteVarint(0)
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return writer.WriteVarint(uint64(value.Int()))
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			return writer.WriteVarint(value.Uint())
+		}
+	case "zigzag32", "zigzag64":
+		switch kind {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			return writer.WriteZigZag(value.Int())
+		}
+	case "fixed32":
+		switch kind {
+		case reflect.Int32:
+			return writer.WriteFixed32(uint32(value.Int()))
+		case reflect.Uint32:
+			return writer.WriteFixed32(uint32(value.Uint()))
+		case reflect.Float32:
+			return writer.WriteFixed32(math.Float32bits(float32(value.Float())))
+		}
+	case "fixed64":
+		switch kind {
+		case reflect.Int64:
+			return writer.WriteFixed64(uint64(value.Int()))
+		case reflect.Uint64:
+			return writer.WriteFixed64(value.Uint())
+		case reflect.Float64:
+			return writer.WriteFixed64(math.Float64bits(value.Float()))
+		}
+	case "bytes":
+		switch {
+		case kind == reflect.String:
+			return writer.WriteString(value.String())
+		case isProtoBytesValue(value.Type()):
+			return writer.WriteBytes(value.Bytes())
+		case kind == reflect.Struct:
+			var buffer bytes.Buffer
+			if err := encodeProtoMessage(NewProtoWriter(&buffer), value); err != nil {
+				return err
+			}
+			return writer.WriteBytes(buffer.Bytes())
+		}
+	}
+	return fmt.Errorf("unsupported protobuf encoding %q for %v", encoding, value.Type())
+}
+
+func decodeProtoMessage(data []byte, value reflect.Value) error {
+	fields, err := protoFieldsOf(value.Type())
+	if err != nil {
+		return err
+	}
+	reader := NewProtoReader(bytes.NewReader(data))
+	for {
+		number, wireType, err := reader.ReadTag()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return err
+		}
+		position := -1
+		for i, field := range fields {
+			if field.number == number {
+				position = i
+				break
+			}
+		}
+		if position < 0 {
+			if err := reader.SkipField(wireType); err != nil {
+				return err
+			}
+			continue
+		}
+		field := fields[position]
+		if err := decodeProtoField(reader, field, wireType, value.Field(field.index)); err != nil {
+			return fmt.Errorf("field %d: %w", number, err)
+		}
+	}
+}
+
+func decodeProtoField(reader *ProtoReader, field protoField, wireType ProtoWireType, value reflect.Value) error {
+	expectedType, _ := protoWireTypeOf(field.encoding)
+	switch {
+	case value.Kind() == reflect.Map:
+		if wireType != ProtoBytes {
+			return fmt.Errorf("unexpected wire type %d", wireType)
+		}
+		data, err := reader.ReadBytes()
+		if err != nil {
+			return err
+		}
+		if value.IsNil() {
+			value.Set(reflect.MakeMap(value.Type()))
+		}
+		entryKey := reflect.New(value.Type().Key()).Elem()
+		entryValue := reflect.New(value.Type().Elem()).Elem()
+		entryReader := NewProtoReader(bytes.NewReader(data))
+		for {
+			number, entryType, err := entryReader.ReadTag()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					break
+				}
+				return err
+			}
+			switch number {
+			case field.key.number:
+				err = decodeProtoField(entryReader, *field.key, entryType, entryKey)
+			case field.value.number:
+				err = decodeProtoField(entryReader, *field.value, entryType, entryValue)
+			default:
+				err = entryReader.SkipField(entryType)
+			}
+			if err != nil {
+				return err
+			}
+		}
+		value.SetMapIndex(entryKey, entryValue)
+		return nil
+	case value.Kind() == reflect.Slice && !isProtoBytesValue(value.Type()):
+		if wireType == ProtoBytes && expectedType != ProtoBytes {
+			data, err := reader.ReadBytes()
+			if err != nil {
+				return err
+			}
+			packedReader := NewProtoReader(bytes.NewReader(data))
+			for {
+				element := reflect.New(value.Type().Elem()).Elem()
+				if err := decodeProtoValue(packedReader, field.encoding, element); err != nil {
+					if errors.Is(err, io.EOF) {
+						return nil
+					}
+					return err
+				}
+				value.Set(reflect.Append(value, element))
+			}
+		}
+		if wireType != expectedType {
+			return fmt.Errorf("unexpected wire type %d", wireType)
+		}
+		element := reflect.New(value.Type().Elem()).Elem()
+		if err := decodeProtoValue(reader, field.encoding, element); err != nil {
+			return err
+		}
+		value.Set(reflect.Append(value, element))
+		return nil
+	default:
+		if wireType != expectedType {
+			return fmt.Errorf("unexpected wire type %d", wireType)
+		}
+		return decodeProtoValue(reader, field.encoding, value)
+	}
+}
+
+func decodeProtoValue(reader *ProtoReader, encoding string, value reflect.Value) error {
+	for value.Kind() == reflect.Pointer {
+		if value.IsNil() {
+			value.Set(reflect.New(value.Type().Elem()))
+		}
+		value = value.Elem()
+	}
+	switch kind := value.Kind(); encoding {
+	case "varint":
+		v, err := reader.ReadVarint()
+		if err != nil {
+			return err
+		}
+		switch kind {
+		case reflect.Bool:
+			value.SetBool(v != 0)
+			return nil
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			value.SetInt(int64(v))
+			return nil
+		case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+			value.SetUint(v)
+			return nil
+		}
+	case "zigzag32", "zigzag64":
+		v, err := reader.ReadZigZag()
+		if err != nil {
+			return err
+		}
+		switch kind {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+			value.SetInt(v)
+			return nil
+		}
+	case "fixed32":
+		v, err := reader.ReadFixed32()
+		if err != nil {
+			return err
+		}
+		switch kind {
+		case reflect.Int32:
+			value.SetInt(int64(int32(v)))
+			return nil
+		case reflect.Uint32:
+			value.SetUint(uint64(v))
+			return nil
+		case reflect.Float32:
+			value.SetFloat(float64(math.Float32frombits(v)))
+			return nil
+		}
+	case "fixed64":
+		v, err := reader.ReadFixed64()
+		if err != nil {
+			return err
+		}
+		switch kind {
+		case reflect.Int64:
+			value.SetInt(int64(v))
+			return nil
+		case reflect.Uint64:
+			value.SetUint(v)
+			return nil
+		case reflect.Float64:
+			value.SetFloat(math.Float64frombits(v))
+			return nil
+		}
+	case "bytes":
+		data, err := reader.ReadBytes()
+		if err != nil {
+			return err
+		}
+		switch {
+		case kind == reflect.String:
+			value.SetString(string(data))
+			return nil
+		case isProtoBytesValue(value.Type()):
+			value.SetBytes(data)
+			return nil
+		case kind == reflect.Struct:
+			return decodeProtoMessage(data, value)
+		}
+	}
+	return fmt.Errorf("unsupported protobuf encoding %q for %v", encoding, value.Type())
+}
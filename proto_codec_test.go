@@ -0,0 +1,200 @@
+package gblob_test
+
+import (
+	"bytes"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+)
+
+type protoTest1 struct {
+	A int32 `protobuf:"varint,1,opt,name=a"`
+}
+
+type protoTest2 struct {
+	B string `protobuf:"bytes,2,opt,name=b"`
+}
+
+type protoTest3 struct {
+	C protoTest1 `protobuf:"bytes,3,opt,name=c"`
+}
+
+type protoScalars struct {
+	Bool     bool    `protobuf:"varint,1,opt,name=bool"`
+	Int32    int32   `protobuf:"varint,2,opt,name=int32"`
+	Uint64   uint64  `protobuf:"varint,3,opt,name=uint64"`
+	Sint32   int32   `protobuf:"zigzag32,4,opt,name=sint32"`
+	Sint64   int64   `protobuf:"zigzag64,5,opt,name=sint64"`
+	Fixed32  uint32  `protobuf:"fixed32,6,opt,name=fixed32"`
+	Sfixed64 int64   `protobuf:"fixed64,7,opt,name=sfixed64"`
+	Float    float32 `protobuf:"fixed32,8,opt,name=float"`
+	Double   float64 `protobuf:"fixed64,9,opt,name=double"`
+	Bytes    []byte  `protobuf:"bytes,10,opt,name=bytes"`
+}
+
+type protoRepeated struct {
+	Packed   []int32  `protobuf:"varint,4,rep,packed,name=packed"`
+	Unpacked []uint32 `protobuf:"varint,5,rep,name=unpacked"`
+	Names    []string `protobuf:"bytes,6,rep,name=names"`
+}
+
+type protoComposite struct {
+	ID       *int64           `protobuf:"varint,1,opt,name=id"`
+	Children []*protoTest1    `protobuf:"bytes,2,rep,name=children"`
+	Labels   map[string]int32 `protobuf:"bytes,3,rep,name=labels" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"`
+	Ignored  string
+}
+
+var _ = Describe("Proto codec", func() {
+	var (
+		buffer  *bytes.Buffer
+		encoder *gblob.ProtoEncoder
+		decoder *gblob.ProtoDecoder
+	)
+
+	BeforeEach(func() {
+		buffer = new(bytes.Buffer)
+		encoder = gblob.NewProtoEncoder(buffer)
+		decoder = gblob.NewProtoDecoder(buffer)
+	})
+
+	Specify("varint field", func() {
+		Expect(encoder.Encode(protoTest1{A: 150})).To(Succeed())
+		Expect(buffer.Bytes()).To(Equal([]uint8{0x08, 0x96, 0x01}))
+
+		var target protoTest1
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(protoTest1{A: 150}))
+	})
+
+	Specify("string field", func() {
+		Expect(encoder.Encode(&protoTest2{B: "testing"})).To(Succeed())
+		Expect(buffer.Bytes()).To(Equal([]uint8{
+			0x12, 0x07, 't', 'e', 's', 't', 'i', 'n', 'g',
+		}))
+
+		var target protoTest2
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(protoTest2{B: "testing"}))
+	})
+
+	Specify("embedded message", func() {
+		Expect(encoder.Encode(protoTest3{C: protoTest1{A: 150}})).To(Succeed())
+		Expect(buffer.Bytes()).To(Equal([]uint8{0x1A, 0x03, 0x08, 0x96, 0x01}))
+
+		var target protoTest3
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(protoTest3{C: protoTest1{A: 150}}))
+	})
+
+	Specify("zero values are omitted", func() {
+		Expect(encoder.Encode(protoScalars{})).To(Succeed())
+		Expect(buffer.Len()).To(BeZero())
+	})
+
+	Specify("scalars", func() {
+		source := protoScalars{
+			Bool:     true,
+			Int32:    -1,
+			Uint64:   300,
+			Sint32:   -2,
+			Sint64:   1,
+			Fixed32:  0x12345678,
+			Sfixed64: -1,
+			Float:    1.0,
+			Double:   -2.0,
+			Bytes:    []byte{0xAB, 0xCD},
+		}
+		Expect(encoder.Encode(source)).To(Succeed())
+		Expect(buffer.Bytes()).To(Equal([]uint8{
+			0x08, 0x01,
+			0x10, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01,
+			0x18, 0xAC, 0x02,
+			0x20, 0x03,
+			0x28, 0x02,
+			0x35, 0x78, 0x56, 0x34, 0x12,
+			0x39, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+			0x45, 0x00, 0x00, 0x80, 0x3F,
+			0x49, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0xC0,
+			0x52, 0x02, 0xAB, 0xCD,
+		}))
+
+		var target protoScalars
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(source))
+	})
+
+	Specify("repeated fields", func() {
+		source := protoRepeated{
+			Packed:   []int32{3, 270, 86942},
+			Unpacked: []uint32{1, 2},
+			Names:    []string{"a", "bc"},
+		}
+		Expect(encoder.Encode(source)).To(Succeed())
+		Expect(buffer.Bytes()).To(Equal([]uint8{
+			0x22, 0x06, 0x03, 0x8E, 0x02, 0x9E, 0xA7, 0x05,
+			0x28, 0x01,
+			0x28, 0x02,
+			0x32, 0x01, 'a',
+			0x32, 0x02, 'b', 'c',
+		}))
+
+		var target protoRepeated
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(source))
+	})
+
+	Specify("packed and unpacked forms are both accepted", func() {
+		buffer.Write([]uint8{
+			0x20, 0x03, // unpacked element of packed field
+			0x2A, 0x02, 0x01, 0x02, // packed elements of unpacked field
+		})
+		var target protoRepeated
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(protoRepeated{
+			Packed:   []int32{3},
+			Unpacked: []uint32{1, 2},
+		}))
+	})
+
+	Specify("composite fields", func() {
+		id := int64(0)
+		source := protoComposite{
+			ID:       &id,
+			Children: []*protoTest1{{A: 1}, {A: 2}},
+			Labels:   map[string]int32{"x": 5},
+			Ignored:  "ignored",
+		}
+		Expect(encoder.Encode(source)).To(Succeed())
+		Expect(buffer.Bytes()).To(Equal([]uint8{
+			0x08, 0x00,
+			0x12, 0x02, 0x08, 0x01,
+			0x12, 0x02, 0x08, 0x02,
+			0x1A, 0x05, 0x0A, 0x01, 'x', 0x10, 0x05,
+		}))
+
+		var target protoComposite
+		Expect(decoder.Decode(&target)).To(Succeed())
+		source.Ignored = ""
+		Expect(target).To(Equal(source))
+	})
+
+	Specify("unknown fields are skipped", func() {
+		buffer.Write([]uint8{
+			0x12, 0x07, 't', 'e', 's', 't', 'i', 'n', 'g',
+			0x08, 0x96, 0x01,
+			0x1D, 0x01, 0x02, 0x03, 0x04,
+		})
+		var target protoTest1
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(protoTest1{A: 150}))
+	})
+
+	Specify("wire type mismatch", func() {
+		buffer.Write([]uint8{0x0A, 0x01, 0x00})
+		var target protoTest1
+		Expect(decoder.Decode(&target)).ToNot(Succeed())
+	})
+})
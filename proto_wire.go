@@ -0,0 +1,230 @@
+package gblob
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"math"
+)
+
+// ErrVarintOverflow indicates that a varint is longer than 64 bits.
+var ErrVarintOverflow = errors.New("varint overflow")
+
+// protoMaxGroupDepth is the deepest nesting of groups that SkipField skips,
+// which matches the recursion limit of the protobuf implementations.
+const protoMaxGroupDepth = 100
+
+// ProtoWireType specifies how a protobuf field value is stored.
+type ProtoWireType uint8
+
+const (
+	// ProtoVarint is used for int32, int64, uint32, uint64, sint32, sint64,
+	// bool and enum values.
+	ProtoVarint ProtoWireType = 0
+
+	// ProtoFixed64 is used for fixed64, sfixed64 and double values.
+	ProtoFixed64 ProtoWireType = 1
+
+	// ProtoBytes is used for string, bytes, embedded messages and packed
+	// repeated fields.
+	ProtoBytes ProtoWireType = 2
+
+	// ProtoStartGroup marks the start of a deprecated group.
+	ProtoStartGroup ProtoWireType = 3
+
+	// ProtoEndGroup marks the end of a deprecated group.
+	ProtoEndGroup ProtoWireType = 4
+
+	// ProtoFixed32 is used for fixed32, sfixed32 and float values.
+	ProtoFixed32 ProtoWireType = 5
+)
+
+// NewProtoWriter returns a new ProtoWriter that writes protobuf wire format
+// primitives to the specified out Writer.
+func NewProtoWriter(out io.Writer) *ProtoWriter {
+	return &ProtoWriter{
+		out: NewLittleEndianWriter(out),
+	}
+}
+
+// ProtoWriter writes primitives of the protobuf wire format.
+type ProtoWriter struct {
+	out TypedWriter
+}
+
+// WriteTag writes a field key that consists of the specified field number
+// and wire type.
+func (w *ProtoWriter) WriteTag(number uint32, wireType ProtoWireType) error {
+	return w.WriteVarint(uint64(number)<<3 | uint64(wireType))
+}
+
+// WriteVarint writes the specified value as a base 128 varint.
+func (w *ProtoWriter) WriteVarint(value uint64) error {
+	for value >= 0x80 {
+		if err := w.out.WriteUint8(uint8(value) | 0x80); err != nil {
+			return err
+		}
+		value >>= 7
+	}
+	return w.out.WriteUint8(uint8(value))
+}
+
+// WriteZigZag writes the specified signed value as a ZigZag-encoded varint,
+// as used by sint32 and sint64 fields.
+func (w *ProtoWriter) WriteZigZag(value int64) error {
+	return w.WriteVarint(uint64(value<<1) ^ uint64(value>>63))
+}
+
+// WriteFixed32 writes the specified value as four Little Endian bytes.
+func (w *ProtoWriter) WriteFixed32(value uint32) error {
+	return w.out.WriteUint32(value)
+}
+
+// WriteFixed64 writes the specified value as eight Little Endian bytes.
+func (w *ProtoWriter) WriteFixed64(value uint64) error {
+	return w.out.WriteUint64(value)
+}
+
+// WriteBytes writes the specified data preceded by its varint length.
+func (w *ProtoWriter) WriteBytes(data []byte) error {
+	if err := w.WriteVarint(uint64(len(data))); err != nil {
+		return err
+	}
+	return w.out.WriteBytes(data)
+}
+
+// WriteString writes the specified string preceded by its varint length.
+func (w *ProtoWriter) WriteString(value string) error {
+	return w.WriteBytes([]byte(value))
+}
+
+// NewProtoReader returns a new ProtoReader that reads protobuf wire format
+// primitives from the specified in Reader.
+func NewProtoReader(in io.Reader) *ProtoReader {
+	return &ProtoReader{
+		in: NewLittleEndianReader(in),
+	}
+}
+
+// ProtoReader reads primitives of the protobuf wire format.
+type ProtoReader struct {
+	in TypedReader
+}
+
+// ReadTag reads a field key and returns its field number and wire type.
+// It returns io.EOF if the input ends before the key.
+func (r *ProtoReader) ReadTag() (uint32, ProtoWireType, error) {
+	key, err := r.ReadVarint()
+	if err != nil {
+		return 0, 0, err
+	}
+	if key>>3 > 1<<29-1 {
+		return 0, 0, fmt.Errorf("invalid field number: %d", key>>3)
+	}
+	return uint32(key >> 3), ProtoWireType(key & 0x07), nil
+}
+
+// ReadVarint reads a base 128 varint. It returns io.EOF if the input ends
+// before the varint and io.ErrUnexpectedEOF if it ends in the middle of it.
+func (r *ProtoReader) ReadVarint() (uint64, error) {
+	var result uint64
+	for shift := 0; shift < 64; shift += 7 {
+		value, err := r.in.ReadUint8()
+		if err != nil {
+			if shift > 0 && errors.Is(err, io.EOF) {
+				return 0, io.ErrUnexpectedEOF
+			}
+			return 0, err
+		}
+		result |= uint64(value&0x7F) << shift
+		if value < 0x80 {
+			return result, nil
+		}
+	}
+	return 0, ErrVarintOverflow
+}
+
+// ReadZigZag reads a ZigZag-encoded varint, as used by sint32 and sint64
+// fields.
+func (r *ProtoReader) ReadZigZag() (int64, error) {
+	value, err := r.ReadVarint()
+	return int64(value>>1) ^ -int64(value&1), err
+}
+
+// ReadFixed32 reads four Little Endian bytes.
+func (r *ProtoReader) ReadFixed32() (uint32, error) {
+	return r.in.ReadUint32()
+}
+
+// ReadFixed64 reads eight Little Endian bytes.
+func (r *ProtoReader) ReadFixed64() (uint64, error) {
+	return r.in.ReadUint64()
+}
+
+// ReadBytes reads data that is preceded by its varint length. It returns
+// io.ErrUnexpectedEOF if the input ends before the data.
+func (r *ProtoReader) ReadBytes() ([]byte, error) {
+	count, err := r.readLength()
+	if err != nil {
+		return nil, err
+	}
+	return readChunked(r.in, count)
+}
+
+// ReadString reads a string that is preceded by its varint length.
+func (r *ProtoReader) ReadString() (string, error) {
+	data, err := r.ReadBytes()
+	return string(data), err
+}
+
+// SkipField skips the value of a field with the specified wire type.
+func (r *ProtoReader) SkipField(wireType ProtoWireType) error {
+	return r.skipField(wireType, 0)
+}
+
+func (r *ProtoReader) skipField(wireType ProtoWireType, depth int) error {
+	switch wireType {
+	case ProtoVarint:
+		_, err := r.ReadVarint()
+		return err
+	case ProtoFixed64:
+		return r.in.SkipBytes(8)
+	case ProtoFixed32:
+		return r.in.SkipBytes(4)
+	case ProtoBytes:
+		count, err := r.readLength()
+		if err != nil {
+			return err
+		}
+		return eofAsUnexpected(r.in.SkipBytes(count))
+	case ProtoStartGroup:
+		if depth >= protoMaxGroupDepth {
+			return fmt.Errorf("group nesting exceeds maximum depth of %d", protoMaxGroupDepth)
+		}
+		for {
+			_, nestedType, err := r.ReadTag()
+			if err != nil {
+				return err
+			}
+			if nestedType == ProtoEndGroup {
+				return nil
+			}
+			if err := r.skipField(nestedType, depth+1); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported wire type: %d", wireType)
+	}
+}
+
+func (r *ProtoReader) readLength() (int, error) {
+	count, err := r.ReadVarint()
+	if err != nil {
+		return 0, eofAsUnexpected(err)
+	}
+	if count > math.MaxInt {
+		return 0, fmt.Errorf("length %d exceeds maximum: %w", count, io.ErrUnexpectedEOF)
+	}
+	return int(count), nil
+}
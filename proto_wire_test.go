@@ -0,0 +1,152 @@
+package gblob_test
+
+import (
+	"bytes"
+	"io"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+)
+
+var _ = Describe("Proto wire", func() {
+	var (
+		buffer *bytes.Buffer
+		writer *gblob.ProtoWriter
+		reader *gblob.ProtoReader
+	)
+
+	BeforeEach(func() {
+		buffer = new(bytes.Buffer)
+		writer = gblob.NewProtoWriter(buffer)
+		reader = gblob.NewProtoReader(buffer)
+	})
+
+	Specify("WriteVarint", func() {
+		Expect(writer.WriteVarint(1)).To(Succeed())
+		Expect(writer.WriteVarint(150)).To(Succeed())
+		Expect(writer.WriteVarint(0xFFFFFFFFFFFFFFFF)).To(Succeed())
+		Expect(buffer.Bytes()).To(Equal([]uint8{
+			0x01,
+			0x96, 0x01,
+			0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01,
+		}))
+	})
+
+	Specify("ReadVarint", func() {
+		buffer.Write([]uint8{
+			0x01,
+			0x96, 0x01,
+			0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01,
+		})
+		Expect(reader.ReadVarint()).To(Equal(uint64(1)))
+		Expect(reader.ReadVarint()).To(Equal(uint64(150)))
+		Expect(reader.ReadVarint()).To(Equal(uint64(0xFFFFFFFFFFFFFFFF)))
+		_, err := reader.ReadVarint()
+		Expect(err).To(MatchError(io.EOF))
+	})
+
+	Specify("ReadVarint truncated", func() {
+		buffer.Write([]uint8{0x96})
+		_, err := reader.ReadVarint()
+		Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+	})
+
+	Specify("ReadVarint overflow", func() {
+		buffer.Write(bytes.Repeat([]uint8{0xFF}, 11))
+		_, err := reader.ReadVarint()
+		Expect(err).To(MatchError(gblob.ErrVarintOverflow))
+	})
+
+	Specify("WriteZigZag", func() {
+		Expect(writer.WriteZigZag(0)).To(Succeed())
+		Expect(writer.WriteZigZag(-1)).To(Succeed())
+		Expect(writer.WriteZigZag(1)).To(Succeed())
+		Expect(writer.WriteZigZag(-2)).To(Succeed())
+		Expect(writer.WriteZigZag(2147483647)).To(Succeed())
+		Expect(buffer.Bytes()).To(Equal([]uint8{
+			0x00,
+			0x01,
+			0x02,
+			0x03,
+			0xFE, 0xFF, 0xFF, 0xFF, 0x0F,
+		}))
+	})
+
+	Specify("ReadZigZag", func() {
+		buffer.Write([]uint8{0x00, 0x01, 0x02, 0x03, 0xFF, 0xFF, 0xFF, 0xFF, 0x0F})
+		Expect(reader.ReadZigZag()).To(Equal(int64(0)))
+		Expect(reader.ReadZigZag()).To(Equal(int64(-1)))
+		Expect(reader.ReadZigZag()).To(Equal(int64(1)))
+		Expect(reader.ReadZigZag()).To(Equal(int64(-2)))
+		Expect(reader.ReadZigZag()).To(Equal(int64(-2147483648)))
+	})
+
+	Specify("fixed values", func() {
+		Expect(writer.WriteFixed32(0x12345678)).To(Succeed())
+		Expect(writer.WriteFixed64(0x0102030405060708)).To(Succeed())
+		Expect(buffer.Bytes()).To(Equal([]uint8{
+			0x78, 0x56, 0x34, 0x12,
+			0x08, 0x07, 0x06, 0x05, 0x04, 0x03, 0x02, 0x01,
+		}))
+		Expect(reader.ReadFixed32()).To(Equal(uint32(0x12345678)))
+		Expect(reader.ReadFixed64()).To(Equal(uint64(0x0102030405060708)))
+	})
+
+	Specify("tags and length-delimited values", func() {
+		Expect(writer.WriteTag(2, gblob.ProtoBytes)).To(Succeed())
+		Expect(writer.WriteString("testing")).To(Succeed())
+		Expect(writer.WriteTag(16, gblob.ProtoVarint)).To(Succeed())
+		Expect(buffer.Bytes()).To(Equal([]uint8{
+			0x12, 0x07, 't', 'e', 's', 't', 'i', 'n', 'g',
+			0x80, 0x01,
+		}))
+
+		number, wireType, err := reader.ReadTag()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(number).To(Equal(uint32(2)))
+		Expect(wireType).To(Equal(gblob.ProtoBytes))
+		Expect(reader.ReadString()).To(Equal("testing"))
+		number, wireType, err = reader.ReadTag()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(number).To(Equal(uint32(16)))
+		Expect(wireType).To(Equal(gblob.ProtoVarint))
+	})
+
+	Specify("SkipField", func() {
+		buffer.Write([]uint8{
+			0x96, 0x01, // varint
+			0x01, 0x02, 0x03, 0x04, 0x05, 0x06, 0x07, 0x08, // fixed64
+			0x02, 'a', 'b', // bytes
+			0x08, 0x01, 0x0C, // group with field 1 and end
+			0x01, 0x02, 0x03, 0x04, // fixed32
+			0x2A,
+		})
+		Expect(reader.SkipField(gblob.ProtoVarint)).To(Succeed())
+		Expect(reader.SkipField(gblob.ProtoFixed64)).To(Succeed())
+		Expect(reader.SkipField(gblob.ProtoBytes)).To(Succeed())
+		Expect(reader.SkipField(gblob.ProtoStartGroup)).To(Succeed())
+		Expect(reader.SkipField(gblob.ProtoFixed32)).To(Succeed())
+		Expect(reader.ReadVarint()).To(Equal(uint64(42)))
+	})
+
+	DescribeTable("corrupted length",
+		func(data []uint8) {
+			buffer.Write(data)
+			_, err := reader.ReadBytes()
+			Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+
+			reader = gblob.NewProtoReader(bytes.NewBuffer(data))
+			Expect(reader.SkipField(gblob.ProtoBytes)).To(MatchError(io.ErrUnexpectedEOF))
+		},
+		Entry("beyond maximum", []uint8{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01}),
+		Entry("beyond input", []uint8{0xFF, 0xFF, 0xFF, 0xFF, 0x0F, 'a', 'b'}),
+		Entry("missing", []uint8{}),
+	)
+
+	Specify("SkipField nested groups", func() {
+		buffer.Write(bytes.Repeat([]uint8{0x0B}, 1000))
+		Expect(reader.SkipField(gblob.ProtoStartGroup)).To(MatchError(ContainSubstring("maximum depth")))
+	})
+})
@@ -0,0 +1,457 @@
+package gblob
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+)
+
+const (
+	// maxSchemaDepth is the deepest nesting of schemas that is decoded.
+	maxSchemaDepth = 1000
+
+	// maxSchemaFields is the largest number of struct fields that is
+	// decoded.
+	maxSchemaFields = 1 << 16
+
+	// maxSchemaNameLength is the longest type or field name that is
+	// decoded.
+	maxSchemaNameLength = 1 << 16
+)
+
+// Kind represents the kind of value that is described by a Schema.
+type Kind uint8
+
+const (
+	// KindInvalid represents an unknown or unsupported kind.
+	KindInvalid Kind = iota
+
+	// KindBool represents a bool, stored as a single byte.
+	KindBool
+
+	// KindUint8 represents a uint8.
+	KindUint8
+
+	// KindInt8 represents an int8.
+	KindInt8
+
+	// KindUint16 represents a uint16.
+	KindUint16
+
+	// KindInt16 represents an int16.
+	KindInt16
+
+	// KindUint32 represents a uint32.
+	KindUint32
+
+	// KindInt32 represents an int32.
+	KindInt32
+
+	// KindUint64 represents a uint64.
+	KindUint64
+
+	// KindInt64 represents an int64.
+	KindInt64
+
+	// KindFloat32 represents a float32.
+	KindFloat32
+
+	// KindFloat64 represents a float64.
+	KindFloat64
+
+	// KindString represents a string, preceded by its uint64 length.
+	KindString
+
+	// KindArray represents an array of fixed length.
+	KindArray
+
+	// KindSlice represents a slice, preceded by its uint64 length.
+	KindSlice
+
+	// KindMap represents a map, preceded by its uint64 entry count.
+	KindMap
+
+	// KindStruct represents a struct, whose fields are stored in order.
+	KindStruct
+
+	// KindCustom represents a type that implements PackedEncodable. Its
+	// encoding is opaque and is preceded by its uint64 byte length.
+	KindCustom
+)
+
+var kindNames = [...]string{
+	KindInvalid: "invalid",
+	KindBool:    "bool",
+	KindUint8:   "uint8",
+	KindInt8:    "int8",
+	KindUint16:  "uint16",
+	KindInt16:   "int16",
+	KindUint32:  "uint32",
+	KindInt32:   "int32",
+	KindUint64:  "uint64",
+	KindInt64:   "int64",
+	KindFloat32: "float32",
+	KindFloat64: "float64",
+	KindString:  "string",
+	KindArray:   "array",
+	KindSlice:   "slice",
+	KindMap:     "map",
+	KindStruct:  "struct",
+	KindCustom:  "custom",
+}
+
+// String returns a string representation of the kind.
+func (k Kind) String() string {
+	if int(k) < len(kindNames) {
+		return kindNames[k]
+	}
+	return fmt.Sprintf("Kind(%d)", uint8(k))
+}
+
+// Size returns the number of bytes that a value of this kind occupies in
+// packed form. It returns zero for kinds that do not have a fixed size.
+func (k Kind) Size() int {
+	switch k {
+	case KindBool, KindUint8, KindInt8:
+		return 1
+	case KindUint16, KindInt16:
+		return 2
+	case KindUint32, KindInt32, KindFloat32:
+		return 4
+	case KindUint64, KindInt64, KindFloat64:
+		return 8
+	default:
+		return 0
+	}
+}
+
+// Schema describes the packed layout of a Go type.
+//
+// Pointers are not represented, since they are encoded as the value that
+// they point to.
+type Schema struct {
+
+	// Kind specifies the kind of the value.
+	Kind Kind
+
+	// Name holds the name of the Go type for KindCustom.
+	Name string
+
+	// Length holds the number of elements for KindArray.
+	Length int
+
+	// Key describes the keys for KindMap.
+	Key *Schema
+
+	// Elem describes the elements for KindArray, KindSlice and KindMap.
+	Elem *Schema
+
+	// Fields describes the fields for KindStruct, in encoding order.
+	Fields []SchemaField
+}
+
+var _ PackedEncodable = (*Schema)(nil)
+var _ PackedDecodable = (*Schema)(nil)
+
+// SchemaField describes a single field of a struct.
+type SchemaField struct {
+
+	// Name is the name of the Go field.
+	Name string
+
+	// Schema describes the value of the field.
+	Schema *Schema
+}
+
+// SchemaOf returns the Schema that describes the packed layout of the
+// specified Go type. Recursive types are not supported.
+func SchemaOf(t reflect.Type) (*Schema, error) {
+	return schemaOf(t, make(map[reflect.Type]struct{}))
+}
+
+// SchemaFor returns the Schema that describes the packed layout of the
+// type T.
+func SchemaFor[T any]() (*Schema, error) {
+	return SchemaOf(reflect.TypeFor[T]())
+}
+
+func schemaOf(t reflect.Type, visiting map[reflect.Type]struct{}) (*Schema, error) {
+	if t.Implements(encodableType) || t.Implements(lazyEncodableType) {
+		return &Schema{
+			Kind: KindCustom,
+			Name: t.String(),
+		}, nil
+	}
+	if _, ok := visiting[t]; ok {
+		return nil, fmt.Errorf("recursive type: %v", t)
+	}
+	visiting[t] = struct{}{}
+	defer delete(visiting, t)
+
+	if kind := schemaKindOf(t.Kind()); kind.Size() > 0 {
+		return &Schema{
+			Kind: kind,
+		}, nil
+	}
+	switch kind := t.Kind(); kind {
+	case reflect.Pointer:
+		return schemaOf(t.Elem(), visiting)
+	case reflect.String:
+		return &Schema{
+			Kind: KindString,
+		}, nil
+	case reflect.Array:
+		elem, err := schemaOf(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{
+			Kind:   KindArray,
+			Length: t.Len(),
+			Elem:   elem,
+		}, nil
+	case reflect.Slice:
+		elem, err := schemaOf(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{
+			Kind: KindSlice,
+			Elem: elem,
+		}, nil
+	case reflect.Map:
+		key, err := schemaOf(t.Key(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		elem, err := schemaOf(t.Elem(), visiting)
+		if err != nil {
+			return nil, err
+		}
+		return &Schema{
+			Kind: KindMap,
+			Key:  key,
+			Elem: elem,
+		}, nil
+	case reflect.Struct:
+		fields := make([]SchemaField, t.NumField())
+		for i := range fields {
+			field := t.Field(i)
+			fieldSchema, err := schemaOf(field.Type, visiting)
+			if err != nil {
+				return nil, err
+			}
+			fields[i] = SchemaField{
+				Name:   field.Name,
+				Schema: fieldSchema,
+			}
+		}
+		return &Schema{
+			Kind:   KindStruct,
+			Fields: fields,
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported type: %v", kind)
+	}
+}
+
+// FixedSize returns the number of bytes that a value described by this
+// schema occupies in packed form and true, or zero and false if the size
+// depends on the value.
+func (s *Schema) FixedSize() (int, bool) {
+	if size := s.Kind.Size(); size > 0 {
+		return size, true
+	}
+	switch s.Kind {
+	case KindArray:
+		size, ok := s.Elem.FixedSize()
+		if !ok || size > 0 && s.Length > math.MaxInt/size {
+			return 0, false
+		}
+		return size * s.Length, true
+	case KindStruct:
+		total := 0
+		for _, field := range s.Fields {
+			size, ok := field.Schema.FixedSize()
+			if !ok || size > math.MaxInt-total {
+				return 0, false
+			}
+			total += size
+		}
+		return total, true
+	default:
+		return 0, false
+	}
+}
+
+// EncodePacked encodes the schema into the specified writer.
+func (s *Schema) EncodePacked(writer TypedWriter) error {
+	if err := writer.WriteUint8(uint8(s.Kind)); err != nil {
+		return err
+	}
+	switch s.Kind {
+	case KindCustom:
+		return writeString(writer, s.Name)
+	case KindArray:
+		if err := writer.WriteUint64(uint64(s.Length)); err != nil {
+			return err
+		}
+		return s.Elem.EncodePacked(writer)
+	case KindSlice:
+		return s.Elem.EncodePacked(writer)
+	case KindMap:
+		if err := s.Key.EncodePacked(writer); err != nil {
+			return err
+		}
+		return s.Elem.EncodePacked(writer)
+	case KindStruct:
+		if err := writer.WriteUint64(uint64(len(s.Fields))); err != nil {
+			return err
+		}
+		for _, field := range s.Fields {
+			if err := writeString(writer, field.Name); err != nil {
+				return err
+			}
+			if err := field.Schema.EncodePacked(writer); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return nil
+	}
+}
+
+// DecodePacked decodes the schema from the specified reader.
+func (s *Schema) DecodePacked(reader TypedReader) error {
+	return s.decodePacked(reader, 0)
+}
+
+func (s *Schema) decodePacked(reader TypedReader, depth int) error {
+	if depth > maxSchemaDepth {
+		return fmt.Errorf("schema nesting exceeds maximum depth of %d", maxSchemaDepth)
+	}
+	kind, err := reader.ReadUint8()
+	if err != nil {
+		return err
+	}
+	*s = Schema{
+		Kind: Kind(kind),
+	}
+	if s.Kind.Size() > 0 || s.Kind == KindString {
+		return nil
+	}
+	switch s.Kind {
+	case KindCustom:
+		s.Name, err = readString(reader)
+		return err
+	case KindArray:
+		length, err := reader.ReadUint64()
+		if err != nil {
+			return err
+		}
+		if length > math.MaxInt {
+			return fmt.Errorf("array length %d exceeds maximum", length)
+		}
+		s.Length = int(length)
+		s.Elem = new(Schema)
+		return s.Elem.decodePacked(reader, depth+1)
+	case KindSlice:
+		s.Elem = new(Schema)
+		return s.Elem.decodePacked(reader, depth+1)
+	case KindMap:
+		s.Key = new(Schema)
+		if err := s.Key.decodePacked(reader, depth+1); err != nil {
+			return err
+		}
+		s.Elem = new(Schema)
+		return s.Elem.decodePacked(reader, depth+1)
+	case KindStruct:
+		count, err := reader.ReadUint64()
+		if err != nil {
+			return err
+		}
+		if count > maxSchemaFields {
+			return fmt.Errorf("field count %d exceeds maximum of %d", count, maxSchemaFields)
+		}
+		s.Fields = make([]SchemaField, count)
+		for i := range s.Fields {
+			name, err := readString(reader)
+			if err != nil {
+				return err
+			}
+			fieldSchema := new(Schema)
+			if err := fieldSchema.decodePacked(reader, depth+1); err != nil {
+				return err
+			}
+			s.Fields[i] = SchemaField{
+				Name:   name,
+				Schema: fieldSchema,
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported schema kind: %v", s.Kind)
+	}
+}
+
+func schemaKindOf(kind reflect.Kind) Kind {
+	switch kind {
+	case reflect.Bool:
+		return KindBool
+	case reflect.Uint8:
+		return KindUint8
+	case reflect.Int8:
+		return KindInt8
+	case reflect.Uint16:
+		return KindUint16
+	case reflect.Int16:
+		return KindInt16
+	case reflect.Uint32:
+		return KindUint32
+	case reflect.Int32:
+		return KindInt32
+	case reflect.Uint64:
+		return KindUint64
+	case reflect.Int64:
+		return KindInt64
+	case reflect.Float32:
+		return KindFloat32
+	case reflect.Float64:
+		return KindFloat64
+	case reflect.String:
+		return KindString
+	case reflect.Array:
+		return KindArray
+	case reflect.Slice:
+		return KindSlice
+	case reflect.Map:
+		return KindMap
+	case reflect.Struct:
+		return KindStruct
+	default:
+		return KindInvalid
+	}
+}
+
+func writeString(writer TypedWriter, value string) error {
+	if err := writer.WriteUint64(uint64(len(value))); err != nil {
+		return err
+	}
+	return writer.WriteBytes([]byte(value))
+}
+
+func readString(reader TypedReader) (string, error) {
+	count, err := reader.ReadUint64()
+	if err != nil {
+		return "", err
+	}
+	if count > maxSchemaNameLength {
+		return "", fmt.Errorf("name length %d exceeds maximum of %d", count, maxSchemaNameLength)
+	}
+	data := make([]byte, count)
+	if err := reader.ReadBytes(data); err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
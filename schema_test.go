@@ -0,0 +1,168 @@
+package gblob_test
+
+import (
+	"bytes"
+	"io"
+	"reflect"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+)
+
+var _ = Describe("Schema", func() {
+	type Inner struct {
+		Values []float32
+	}
+
+	type Outer struct {
+		ID     uint32
+		Name   string
+		Inner  *Inner
+		Lookup map[string]int16
+		Pair   [2]bool
+		Custom testEncodable
+	}
+
+	Specify("SchemaOf", func() {
+		schema, err := gblob.SchemaFor[Outer]()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(schema).To(Equal(&gblob.Schema{
+			Kind: gblob.KindStruct,
+			Fields: []gblob.SchemaField{
+				{Name: "ID", Schema: &gblob.Schema{Kind: gblob.KindUint32}},
+				{Name: "Name", Schema: &gblob.Schema{Kind: gblob.KindString}},
+				{Name: "Inner", Schema: &gblob.Schema{
+					Kind: gblob.KindStruct,
+					Fields: []gblob.SchemaField{
+						{Name: "Values", Schema: &gblob.Schema{
+							Kind: gblob.KindSlice,
+							Elem: &gblob.Schema{Kind: gblob.KindFloat32},
+						}},
+					},
+				}},
+				{Name: "Lookup", Schema: &gblob.Schema{
+					Kind: gblob.KindMap,
+					Key:  &gblob.Schema{Kind: gblob.KindString},
+					Elem: &gblob.Schema{Kind: gblob.KindInt16},
+				}},
+				{Name: "Pair", Schema: &gblob.Schema{
+					Kind:   gblob.KindArray,
+					Length: 2,
+					Elem:   &gblob.Schema{Kind: gblob.KindBool},
+				}},
+				{Name: "Custom", Schema: &gblob.Schema{
+					Kind: gblob.KindCustom,
+					Name: "gblob_test.testEncodable",
+				}},
+			},
+		}))
+	})
+
+	Specify("SchemaOf recursive type", func() {
+		type Node struct {
+			Children []Node
+		}
+		_, err := gblob.SchemaFor[Node]()
+		Expect(err).To(HaveOccurred())
+	})
+
+	Specify("SchemaOf unsupported type", func() {
+		_, err := gblob.SchemaOf(reflect.TypeFor[chan int]())
+		Expect(err).To(HaveOccurred())
+	})
+
+	Specify("FixedSize", func() {
+		schema, err := gblob.SchemaFor[struct {
+			A uint16
+			B [3]float64
+		}]()
+		Expect(err).ToNot(HaveOccurred())
+		size, ok := schema.FixedSize()
+		Expect(ok).To(BeTrue())
+		Expect(size).To(Equal(2 + 3*8))
+
+		schema, err = gblob.SchemaFor[Outer]()
+		Expect(err).ToNot(HaveOccurred())
+		_, ok = schema.FixedSize()
+		Expect(ok).To(BeFalse())
+	})
+
+	Specify("EncodePacked", func() {
+		schema, err := gblob.SchemaFor[struct {
+			A uint16
+			B []string
+		}]()
+		Expect(err).ToNot(HaveOccurred())
+
+		var buffer bytes.Buffer
+		Expect(gblob.NewLittleEndianPackedEncoder(&buffer).Encode(schema)).To(Succeed())
+		Expect(buffer.Bytes()).To(Equal([]uint8{
+			uint8(gblob.KindStruct),
+			0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // field count
+			0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 'A', // name
+			uint8(gblob.KindUint16),
+			0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 'B', // name
+			uint8(gblob.KindSlice),
+			uint8(gblob.KindString),
+		}))
+	})
+
+	Specify("round trip", func() {
+		schema, err := gblob.SchemaFor[Outer]()
+		Expect(err).ToNot(HaveOccurred())
+
+		var buffer bytes.Buffer
+		Expect(gblob.NewBigEndianPackedEncoder(&buffer).Encode(schema)).To(Succeed())
+
+		var target *gblob.Schema
+		Expect(gblob.NewBigEndianPackedDecoder(&buffer).Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(schema))
+	})
+
+	DescribeTable("corrupted input",
+		func(data []byte) {
+			var target gblob.Schema
+			Expect(target.DecodePacked(gblob.NewLittleEndianBufferReader(data))).ToNot(Succeed())
+		},
+		Entry("field count", []byte{
+			uint8(gblob.KindStruct),
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10, // field count
+		}),
+		Entry("name length", []byte{
+			uint8(gblob.KindCustom),
+			0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, // name length
+		}),
+		Entry("array length", []byte{
+			uint8(gblob.KindArray),
+			0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, // length
+			uint8(gblob.KindUint8),
+		}),
+		Entry("nesting", bytes.Repeat([]byte{uint8(gblob.KindSlice)}, 100000)),
+	)
+
+	It("skips values of a corrupted slice length", func() {
+		var buffer bytes.Buffer
+		encoder := gblob.NewLittleEndianPackedEncoder(&buffer)
+		encoder.SetSelfDescribing(true)
+		Expect(encoder.Encode(struct {
+			Values []uint64
+			Name   string
+		}{
+			Values: []uint64{1},
+			Name:   "name",
+		})).To(Succeed())
+		data := buffer.Bytes()
+		position := bytes.Index(data, []byte{0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x01})
+		Expect(position).To(BeNumerically(">", 0))
+		gblob.LittleEndianBlock(data).SetUint64(position, 1<<62)
+
+		decoder := gblob.NewLittleEndianBufferPackedDecoder(data)
+		decoder.SetSelfDescribing(true)
+		var target struct {
+			Name string
+		}
+		Expect(decoder.Decode(&target)).To(MatchError(io.ErrUnexpectedEOF))
+	})
+})
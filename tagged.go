@@ -0,0 +1,299 @@
+package gblob
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+	"sync"
+)
+
+var (
+	taggedType = reflect.TypeFor[PackedTagged]()
+
+	taggedFieldsCache sync.Map // map[reflect.Type]taggedFieldsEntry
+)
+
+// PackedTagged is an interface that can be implemented by struct types that
+// want to be encoded with tagged fields instead of positionally.
+//
+// Only fields that have a `gblob:"<number>"` struct tag are encoded, where
+// the number is a unique positive integer. Each field is preceded by a uint32
+// key that holds the field number and the WireType of the value. Values that
+// are not of a fixed size are preceded by their uint64 byte length. The end of
+// the struct is marked by a zero key.
+//
+// This allows newer writers to add fields that older readers skip and older
+// writers to omit fields that newer readers leave at zero. Nil pointer fields
+// are omitted. Nested values that are not tagged use the positional
+// encoding.
+//
+// Tagged encoding is not used in self-describing mode.
+type PackedTagged interface {
+
+	// PackedTagged marks the type as using tagged encoding.
+	PackedTagged()
+}
+
+// WireType specifies how a tagged field value is stored.
+type WireType uint8
+
+const (
+	// WireEnd marks the end of a tagged struct.
+	WireEnd WireType = iota
+
+	// WireFixed8 is used for bool, uint8 and int8 values.
+	WireFixed8
+
+	// WireFixed16 is used for uint16 and int16 values.
+	WireFixed16
+
+	// WireFixed32 is used for uint32, int32 and float32 values.
+	WireFixed32
+
+	// WireFixed64 is used for uint64, int64 and float64 values.
+	WireFixed64
+
+	// WireBytes is used for all other values, which are preceded by their
+	// uint64 byte length.
+	WireBytes
+)
+
+const wireTypeBits = 3
+
+// Size returns the number of bytes that a value of this wire type occupies,
+// or zero if the value is length-prefixed.
+func (w WireType) Size() int {
+	switch w {
+	case WireFixed8:
+		return 1
+	case WireFixed16:
+		return 2
+	case WireFixed32:
+		return 4
+	case WireFixed64:
+		return 8
+	default:
+		return 0
+	}
+}
+
+type taggedField struct {
+	number uint32
+	index  int
+	wire   WireType
+}
+
+type taggedFieldsEntry struct {
+	fields []taggedField
+	err    error
+}
+
+// taggedFieldPosition returns the position of the field with the specified
+// number within the specified fields, or -1 if there is no such field.
+func taggedFieldPosition(fields []taggedField, number uint32) int {
+	return slices.IndexFunc(fields, func(field taggedField) bool {
+		return field.number == number
+	})
+}
+
+func isTagged(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && (t.Implements(taggedType) || reflect.PointerTo(t).Implements(taggedType))
+}
+
+func taggedFieldsOf(t reflect.Type) ([]taggedField, error) {
+	if entry, ok := taggedFieldsCache.Load(t); ok {
+		return entry.(taggedFieldsEntry).fields, entry.(taggedFieldsEntry).err
+	}
+	fields, err := evalTaggedFields(t)
+	taggedFieldsCache.Store(t, taggedFieldsEntry{
+		fields: fields,
+		err:    err,
+	})
+	return fields, err
+}
+
+func evalTaggedFields(t reflect.Type) ([]taggedField, error) {
+	var result []taggedField
+	seen := make(map[uint32]string)
+	for i := range t.NumField() {
+		field := t.Field(i)
+		tag, ok := field.Tag.Lookup("gblob")
+		if !ok || tag == "-" {
+			continue
+		}
+		number, err := strconv.ParseUint(tag, 10, 29)
+		if err != nil || number == 0 {
+			return nil, fmt.Errorf("invalid field number %q of %v.%s", tag, t, field.Name)
+		}
+		if other, ok := seen[uint32(number)]; ok {
+			return nil, fmt.Errorf("duplicate field number %d of %v.%s and %v.%s", number, t, other, t, field.Name)
+		}
+		seen[uint32(number)] = field.Name
+		result = append(result, taggedField{
+			number: uint32(number),
+			index:  i,
+			wire:   wireTypeOf(field.Type),
+		})
+	}
+	return result, nil
+}
+
+func wireTypeOf(t reflect.Type) WireType {
+	for t.Kind() == reflect.Pointer && !t.Implements(encodableType) {
+		t = t.Elem()
+	}
+	if t.Implements(encodableType) || isVersioned(t) {
+		return WireBytes
+	}
+	switch schemaKindOf(t.Kind()).Size() {
+	case 1:
+		return WireFixed8
+	case 2:
+		return WireFixed16
+	case 4:
+		return WireFixed32
+	case 8:
+		return WireFixed64
+	default:
+		return WireBytes
+	}
+}
+
+func (e *PackedEncoder) encodeTaggedStruct(value reflect.Value) error {
+	fields, err := taggedFieldsOf(value.Type())
+	if err != nil {
+		return err
+	}
+	var (
+		buffer bytes.Buffer
+		nested *PackedEncoder
+	)
+	for _, field := range fields {
+		fieldValue := value.Field(field.index)
+		if fieldValue.Kind() == reflect.Pointer && fieldValue.IsNil() {
+			continue
+		}
+		if err := e.out.WriteUint32(field.number<<wireTypeBits | uint32(field.wire)); err != nil {
+			return err
+		}
+		if field.wire != WireBytes {
+			if err := e.encodeValue(fieldValue); err != nil {
+				return err
+			}
+			continue
+		}
+		if nested == nil {
+			writer, err := newOrderedWriter(&buffer, e.order)
+			if err != nil {
+				return err
+			}
+			nested = &PackedEncoder{
+				out:   writer,
+				order: e.order,
+			}
+		}
+		buffer.Reset()
+		if err := nested.encodeValue(fieldValue); err != nil {
+			return err
+		}
+		if err := e.out.WriteUint64(uint64(buffer.Len())); err != nil {
+			return err
+		}
+		if err := e.out.WriteBytes(buffer.Bytes()); err != nil {
+			return err
+		}
+	}
+	return e.out.WriteUint32(uint32(WireEnd))
+}
+
+func (d *PackedDecoder) decodeTaggedStruct(value reflect.Value) error {
+	fields, err := taggedFieldsOf(value.Type())
+	if err != nil {
+		return err
+	}
+	decoded := make([]bool, len(fields))
+	for {
+		key, err := d.in.ReadUint32()
+		if err != nil {
+			return err
+		}
+		wire := WireType(key & (1<<wireTypeBits - 1))
+		if wire == WireEnd {
+			break
+		}
+		number := key >> wireTypeBits
+		position := taggedFieldPosition(fields, number)
+		if position < 0 {
+			if err := d.skipWireValue(wire); err != nil {
+				return err
+			}
+			continue
+		}
+		field := fields[position]
+		if field.wire != wire {
+			return fmt.Errorf("field %d of %v: expected wire type %d, got %d", number, value.Type(), field.wire, wire)
+		}
+		if err := d.decodeWireValue(wire, value.Field(field.index)); err != nil {
+			return err
+		}
+		decoded[position] = true
+	}
+	for i, field := range fields {
+		if !decoded[i] {
+			value.Field(field.index).SetZero()
+		}
+	}
+	return nil
+}
+
+// decodeWireValue decodes a field value. Length-prefixed values are decoded
+// within their declared length and any remaining bytes, such as those of
+// fields that were added to a nested value, are skipped.
+func (d *PackedDecoder) decodeWireValue(wire WireType, value reflect.Value) error {
+	if wire != WireBytes {
+		return d.decodeValue(value)
+	}
+	count, err := d.readWireLength()
+	if err != nil {
+		return err
+	}
+	limited := &limitedReader{
+		in:        d.in,
+		remaining: count,
+	}
+	d.in = limited.typed()
+	err = d.decodeValue(value)
+	d.in = limited.in
+	if err != nil {
+		return err
+	}
+	return d.in.SkipBytes(limited.remaining)
+}
+
+func (d *PackedDecoder) skipWireValue(wire WireType) error {
+	if size := wire.Size(); size > 0 {
+		return d.in.SkipBytes(size)
+	}
+	if wire != WireBytes {
+		return fmt.Errorf("unsupported wire type: %d", wire)
+	}
+	count, err := d.readWireLength()
+	if err != nil {
+		return err
+	}
+	return d.in.SkipBytes(count)
+}
+
+func (d *PackedDecoder) readWireLength() (int, error) {
+	count, err := d.in.ReadUint64()
+	if err != nil {
+		return 0, err
+	}
+	if count > math.MaxInt {
+		return 0, fmt.Errorf("wire value length %d exceeds maximum", count)
+	}
+	return int(count), nil
+}
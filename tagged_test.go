@@ -0,0 +1,212 @@
+package gblob_test
+
+import (
+	"bytes"
+	"io"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/mokiat/gblob"
+)
+
+type taggedPoint struct {
+	X float32 `gblob:"1"`
+	Y float32 `gblob:"2"`
+}
+
+type taggedMessageV1 struct {
+	ID   uint16   `gblob:"1"`
+	Name string   `gblob:"2"`
+	Tags []string `gblob:"4"`
+}
+
+func (taggedMessageV1) PackedTagged() {}
+
+type taggedMessageV2 struct {
+	ID       uint16            `gblob:"1"`
+	Position *taggedPoint      `gblob:"3"`
+	Tags     []string          `gblob:"4"`
+	Extra    map[string]uint32 `gblob:"5"`
+	Nested   *taggedMessageV1  `gblob:"6"`
+	Ignored  uint8
+}
+
+func (taggedMessageV2) PackedTagged() {}
+
+type taggedPoint3D struct {
+	X float32
+	Y float32
+	Z float32
+}
+
+type taggedMessageV3 struct {
+	ID       uint16        `gblob:"1"`
+	Position taggedPoint3D `gblob:"3"`
+	Tags     []string      `gblob:"4"`
+}
+
+func (taggedMessageV3) PackedTagged() {}
+
+type taggedInvalid struct {
+	A uint8 `gblob:"1"`
+	B uint8 `gblob:"1"`
+}
+
+func (taggedInvalid) PackedTagged() {}
+
+var _ = Describe("Tagged encoding", func() {
+	var (
+		buffer  *bytes.Buffer
+		encoder *gblob.PackedEncoder
+		decoder *gblob.PackedDecoder
+	)
+
+	BeforeEach(func() {
+		buffer = new(bytes.Buffer)
+		encoder = gblob.NewLittleEndianPackedEncoder(buffer)
+		decoder = gblob.NewLittleEndianPackedDecoder(buffer)
+	})
+
+	Specify("Encode", func() {
+		Expect(encoder.Encode(taggedMessageV1{
+			ID:   0x1234,
+			Name: "ab",
+		})).To(Succeed())
+		Expect(buffer.Bytes()).To(Equal([]uint8{
+			0x0A, 0x00, 0x00, 0x00, // key (1, fixed16)
+			0x34, 0x12, // value
+			0x15, 0x00, 0x00, 0x00, // key (2, bytes)
+			0x0A, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // byte length
+			0x02, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 'a', 'b', // value
+			0x25, 0x00, 0x00, 0x00, // key (4, bytes)
+			0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // byte length
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // value
+			0x00, 0x00, 0x00, 0x00, // end
+		}))
+	})
+
+	Specify("same type", func() {
+		source := taggedMessageV2{
+			ID:       7,
+			Position: &taggedPoint{X: 1.5, Y: -2.5},
+			Tags:     []string{"a", "b"},
+			Extra:    map[string]uint32{"k": 3},
+			Nested: &taggedMessageV1{
+				ID:   9,
+				Name: "nested",
+				Tags: []string{},
+			},
+		}
+		Expect(encoder.Encode(source)).To(Succeed())
+
+		var target taggedMessageV2
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(source))
+	})
+
+	Specify("newer writer", func() {
+		Expect(encoder.Encode(taggedMessageV2{
+			ID:       7,
+			Position: &taggedPoint{X: 1.5, Y: -2.5},
+			Tags:     []string{"a", "b"},
+			Extra:    map[string]uint32{"k": 3},
+		})).To(Succeed())
+		Expect(encoder.Encode(uint8(0x37))).To(Succeed())
+
+		target := taggedMessageV1{
+			Name: "previous",
+		}
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(taggedMessageV1{
+			ID:   7,
+			Tags: []string{"a", "b"},
+		}))
+
+		var next uint8
+		Expect(decoder.Decode(&next)).To(Succeed())
+		Expect(next).To(Equal(uint8(0x37)))
+	})
+
+	Specify("older writer", func() {
+		Expect(encoder.Encode(&taggedMessageV1{
+			ID:   7,
+			Name: "name",
+			Tags: []string{"a"},
+		})).To(Succeed())
+
+		target := taggedMessageV2{
+			Position: &taggedPoint{X: 1},
+			Ignored:  13,
+		}
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(taggedMessageV2{
+			ID:      7,
+			Tags:    []string{"a"},
+			Ignored: 13,
+		}))
+	})
+
+	Specify("nested in positional", func() {
+		type Container struct {
+			Count   uint8
+			Message taggedMessageV1
+		}
+		source := Container{
+			Count: 3,
+			Message: taggedMessageV1{
+				ID:   1,
+				Name: "x",
+				Tags: []string{},
+			},
+		}
+		Expect(encoder.Encode(source)).To(Succeed())
+
+		var target Container
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(source))
+	})
+
+	Specify("nested value that gained fields", func() {
+		Expect(encoder.Encode(taggedMessageV3{
+			ID:       7,
+			Position: taggedPoint3D{X: 1.5, Y: -2.5, Z: 4.0},
+			Tags:     []string{"a"},
+		})).To(Succeed())
+
+		var target taggedMessageV2
+		Expect(decoder.Decode(&target)).To(Succeed())
+		Expect(target).To(Equal(taggedMessageV2{
+			ID:       7,
+			Position: &taggedPoint{X: 1.5, Y: -2.5},
+			Tags:     []string{"a"},
+		}))
+	})
+
+	Specify("nested value that exceeds its length", func() {
+		buffer.Write([]uint8{
+			0x1D, 0x00, 0x00, 0x00, // key (3, bytes)
+			0x04, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // byte length
+			0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, // value
+			0x00, 0x00, 0x00, 0x00, // end
+		})
+
+		var target taggedMessageV2
+		Expect(decoder.Decode(&target)).To(MatchError(io.ErrUnexpectedEOF))
+	})
+
+	Specify("unknown field with excessive length", func() {
+		buffer.Write([]uint8{
+			0x3D, 0x00, 0x00, 0x00, // key (7, bytes)
+			0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, // byte length
+			0x00, 0x00, 0x00, 0x00, // end
+		})
+
+		var target taggedMessageV2
+		Expect(decoder.Decode(&target)).To(MatchError(ContainSubstring("exceeds maximum")))
+	})
+
+	Specify("invalid field numbers", func() {
+		Expect(encoder.Encode(taggedInvalid{})).To(MatchError(ContainSubstring("duplicate field number")))
+	})
+})
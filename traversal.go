@@ -0,0 +1,54 @@
+package gblob
+
+import "reflect"
+
+// valueHook identifies the hook through which a value is encoded or decoded
+// instead of by its kind.
+//
+// The PackedEncoder and PackedDecoder, as well as the codecs that follow their
+// traversal, look up hooks through encodeHookOf and decodeHookOf, so that all
+// of them apply the hooks in the same order.
+type valueHook uint8
+
+const (
+	// hookNone means that the value is traversed by its kind.
+	hookNone valueHook = iota
+
+	// hookLazy is used for Lazy and LazySlice values.
+	hookLazy
+
+	// hookPacked is used for PackedEncodable and PackedDecodable values.
+	hookPacked
+
+	// hookVersioned is used for PackedVersioned values.
+	hookVersioned
+)
+
+// encodeHookOf returns the hook through which a value of the specified type
+// is encoded.
+func encodeHookOf(t reflect.Type) valueHook {
+	switch {
+	case t.Kind() == reflect.Struct && t.Implements(lazyEncodableType):
+		return hookLazy
+	case t.Implements(encodableType):
+		return hookPacked
+	case t.Kind() != reflect.Pointer && isVersioned(t):
+		return hookVersioned
+	default:
+		return hookNone
+	}
+}
+
+// decodeHookOf returns the hook through which the specified value is decoded.
+func decodeHookOf(value reflect.Value) valueHook {
+	switch {
+	case value.Kind() == reflect.Struct && value.CanAddr() && value.Addr().Type().Implements(lazyDecodableType):
+		return hookLazy
+	case value.Type().Implements(decodableType):
+		return hookPacked
+	case value.Kind() != reflect.Pointer && isVersioned(value.Type()):
+		return hookVersioned
+	default:
+		return hookNone
+	}
+}
@@ -1,6 +1,22 @@
 package gblob
 
-import "io"
+import (
+	"fmt"
+	"io"
+	"slices"
+)
+
+const (
+	// chunkedReadSize is the maximum number of bytes that readChunked reads
+	// at once.
+	chunkedReadSize = 64 * 1024
+
+	// preallocLimit is the largest number of elements that is allocated
+	// upfront for a collection whose length is read from a stream. Larger
+	// collections grow as their elements are decoded, so that a corrupted
+	// length cannot cause a large allocation.
+	preallocLimit = 1024
+)
 
 // TypedReader represents a reader that can parse specific Go types from
 // a byte sequence.
@@ -66,9 +82,19 @@ func NewBigEndianReader(in io.Reader) TypedReader {
 	}
 }
 
+// NewNativeEndianReader returns an implementation of TypedReader that reads
+// from the specified in Reader in the byte order of the host.
+func NewNativeEndianReader(in io.Reader) TypedReader {
+	return &typedReader[NativeEndianBlock]{
+		in:     in,
+		buffer: make(NativeEndianBlock, 8), // 64 bit max
+	}
+}
+
 type typedReader[T blockBuffer] struct {
 	in     io.Reader
 	buffer T
+	offset int64
 }
 
 func (r *typedReader[T]) ReadUint8() (uint8, error) {
@@ -122,20 +148,330 @@ func (r *typedReader[T]) ReadFloat64() (float64, error) {
 }
 
 func (r *typedReader[T]) ReadBytes(target []byte) error {
-	_, err := io.ReadFull(r.in, target)
+	n, err := io.ReadFull(r.in, target)
+	r.offset += int64(n)
 	return err
 }
 
 func (r *typedReader[T]) SkipBytes(count int) error {
 	if seeker, ok := r.in.(io.Seeker); ok {
 		_, err := seeker.Seek(int64(count), io.SeekCurrent)
+		if err == nil {
+			r.offset += int64(count)
+		}
 		return err
 	} else {
-		_, err := io.CopyN(io.Discard, r.in, int64(count))
+		n, err := io.CopyN(io.Discard, r.in, int64(count))
+		r.offset += n
 		return err
 	}
 }
 
+func (r *typedReader[T]) position() (int64, bool) {
+	return r.offset, true
+}
+
 func (r *typedReader[T]) fillBuffer(count int) error {
 	return r.ReadBytes(r.buffer[:count])
 }
+
+// NewLittleEndianBufferReader returns an implementation of TypedReader that
+// reads from the specified data buffer in Little Endian order.
+func NewLittleEndianBufferReader(data []byte) TypedReader {
+	return &bufferReader[LittleEndianBlock]{
+		data: data,
+	}
+}
+
+// NewBigEndianBufferReader returns an implementation of TypedReader that
+// reads from the specified data buffer in Big Endian order.
+func NewBigEndianBufferReader(data []byte) TypedReader {
+	return &bufferReader[BigEndianBlock]{
+		data: data,
+	}
+}
+
+// NewNativeEndianBufferReader returns an implementation of TypedReader that
+// reads from the specified data buffer in the byte order of the host.
+func NewNativeEndianBufferReader(data []byte) TypedReader {
+	return &bufferReader[NativeEndianBlock]{
+		data: data,
+	}
+}
+
+// sliceReader is implemented by readers that can return a portion of their
+// source without copying it.
+type sliceReader interface {
+
+	// readSlice returns the next count bytes of the source. The result
+	// aliases the source and has its capacity limited to its length.
+	readSlice(count int) ([]byte, error)
+}
+
+// positionReader is implemented by readers that keep track of the number of
+// bytes that have been consumed from their source.
+type positionReader interface {
+
+	// position returns the number of bytes consumed so far. It reports
+	// false if the position is not known.
+	position() (int64, bool)
+}
+
+// offsetReader is a TypedReader over a data buffer that was taken from a
+// larger input. It reports positions relative to the start of that input,
+// unless base is negative because the position of the data is not known.
+type offsetReader struct {
+	TypedReader
+	base int64
+}
+
+func (r offsetReader) position() (int64, bool) {
+	offset, ok := r.TypedReader.(positionReader).position()
+	if !ok || r.base < 0 {
+		return 0, false
+	}
+	return r.base + offset, true
+}
+
+func (r offsetReader) readSlice(count int) ([]byte, error) {
+	return r.TypedReader.(sliceReader).readSlice(count)
+}
+
+// limitedReader is a TypedReader that allows at most a fixed number of bytes
+// to be read from an underlying TypedReader.
+type limitedReader struct {
+	in        TypedReader
+	remaining int
+}
+
+// typed returns the limitedReader as a TypedReader that is a sliceReader if
+// the underlying reader is one.
+func (r *limitedReader) typed() TypedReader {
+	if _, ok := r.in.(sliceReader); ok {
+		return limitedSliceReader{r}
+	}
+	return r
+}
+
+func (r *limitedReader) ReadUint8() (uint8, error) {
+	if err := r.take(1); err != nil {
+		return 0, err
+	}
+	return r.in.ReadUint8()
+}
+
+func (r *limitedReader) ReadInt8() (int8, error) {
+	if err := r.take(1); err != nil {
+		return 0, err
+	}
+	return r.in.ReadInt8()
+}
+
+func (r *limitedReader) ReadUint16() (uint16, error) {
+	if err := r.take(2); err != nil {
+		return 0, err
+	}
+	return r.in.ReadUint16()
+}
+
+func (r *limitedReader) ReadInt16() (int16, error) {
+	if err := r.take(2); err != nil {
+		return 0, err
+	}
+	return r.in.ReadInt16()
+}
+
+func (r *limitedReader) ReadUint32() (uint32, error) {
+	if err := r.take(4); err != nil {
+		return 0, err
+	}
+	return r.in.ReadUint32()
+}
+
+func (r *limitedReader) ReadInt32() (int32, error) {
+	if err := r.take(4); err != nil {
+		return 0, err
+	}
+	return r.in.ReadInt32()
+}
+
+func (r *limitedReader) ReadUint64() (uint64, error) {
+	if err := r.take(8); err != nil {
+		return 0, err
+	}
+	return r.in.ReadUint64()
+}
+
+func (r *limitedReader) ReadInt64() (int64, error) {
+	if err := r.take(8); err != nil {
+		return 0, err
+	}
+	return r.in.ReadInt64()
+}
+
+func (r *limitedReader) ReadFloat32() (float32, error) {
+	if err := r.take(4); err != nil {
+		return 0, err
+	}
+	return r.in.ReadFloat32()
+}
+
+func (r *limitedReader) ReadFloat64() (float64, error) {
+	if err := r.take(8); err != nil {
+		return 0, err
+	}
+	return r.in.ReadFloat64()
+}
+
+func (r *limitedReader) ReadBytes(target []byte) error {
+	if err := r.take(len(target)); err != nil {
+		return err
+	}
+	return r.in.ReadBytes(target)
+}
+
+func (r *limitedReader) SkipBytes(count int) error {
+	if err := r.take(count); err != nil {
+		return err
+	}
+	return r.in.SkipBytes(count)
+}
+
+func (r *limitedReader) position() (int64, bool) {
+	if reader, ok := r.in.(positionReader); ok {
+		return reader.position()
+	}
+	return 0, false
+}
+
+func (r *limitedReader) take(count int) error {
+	if count < 0 {
+		return fmt.Errorf("invalid byte count: %d", count)
+	}
+	if count > r.remaining {
+		return io.ErrUnexpectedEOF
+	}
+	r.remaining -= count
+	return nil
+}
+
+// limitedSliceReader is a limitedReader over a sliceReader.
+type limitedSliceReader struct {
+	*limitedReader
+}
+
+func (r limitedSliceReader) readSlice(count int) ([]byte, error) {
+	if err := r.take(count); err != nil {
+		return nil, err
+	}
+	return r.in.(sliceReader).readSlice(count)
+}
+
+type bufferReader[T blockBuffer] struct {
+	data   []byte
+	offset int
+}
+
+func (r *bufferReader[T]) ReadUint8() (uint8, error) {
+	block, err := r.readBlock(1)
+	return block.Uint8(0), err
+}
+
+func (r *bufferReader[T]) ReadInt8() (int8, error) {
+	block, err := r.readBlock(1)
+	return block.Int8(0), err
+}
+
+func (r *bufferReader[T]) ReadUint16() (uint16, error) {
+	block, err := r.readBlock(2)
+	return block.Uint16(0), err
+}
+
+func (r *bufferReader[T]) ReadInt16() (int16, error) {
+	block, err := r.readBlock(2)
+	return block.Int16(0), err
+}
+
+func (r *bufferReader[T]) ReadUint32() (uint32, error) {
+	block, err := r.readBlock(4)
+	return block.Uint32(0), err
+}
+
+func (r *bufferReader[T]) ReadInt32() (int32, error) {
+	block, err := r.readBlock(4)
+	return block.Int32(0), err
+}
+
+func (r *bufferReader[T]) ReadUint64() (uint64, error) {
+	block, err := r.readBlock(8)
+	return block.Uint64(0), err
+}
+
+func (r *bufferReader[T]) ReadInt64() (int64, error) {
+	block, err := r.readBlock(8)
+	return block.Int64(0), err
+}
+
+func (r *bufferReader[T]) ReadFloat32() (float32, error) {
+	block, err := r.readBlock(4)
+	return block.Float32(0), err
+}
+
+func (r *bufferReader[T]) ReadFloat64() (float64, error) {
+	block, err := r.readBlock(8)
+	return block.Float64(0), err
+}
+
+func (r *bufferReader[T]) ReadBytes(target []byte) error {
+	data, err := r.readSlice(len(target))
+	copy(target, data)
+	return err
+}
+
+func (r *bufferReader[T]) SkipBytes(count int) error {
+	_, err := r.readSlice(count)
+	return err
+}
+
+func (r *bufferReader[T]) position() (int64, bool) {
+	return int64(r.offset), true
+}
+
+func (r *bufferReader[T]) readSlice(count int) ([]byte, error) {
+	remaining := len(r.data) - r.offset
+	switch {
+	case count >= 0 && count <= remaining:
+		data := r.data[r.offset : r.offset+count : r.offset+count]
+		r.offset += count
+		return data, nil
+	case remaining == 0:
+		return nil, io.EOF
+	default:
+		r.offset = len(r.data)
+		return nil, io.ErrUnexpectedEOF
+	}
+}
+
+func (r *bufferReader[T]) readBlock(count int) (T, error) {
+	data, err := r.readSlice(count)
+	if err != nil {
+		return make(T, 8), err // 64 bit max
+	}
+	return T(data), nil
+}
+
+// readChunked reads the specified number of bytes from a stream. The result
+// is allocated as data arrives, so that a corrupted length results in
+// io.ErrUnexpectedEOF instead of a large allocation upfront.
+func readChunked(in TypedReader, length int) ([]byte, error) {
+	data := make([]byte, 0, min(length, chunkedReadSize))
+	for len(data) < length {
+		count := min(length-len(data), chunkedReadSize)
+		data = slices.Grow(data, count)
+		if err := in.ReadBytes(data[len(data) : len(data)+count]); err != nil {
+			return nil, eofAsUnexpected(err)
+		}
+		data = data[:len(data)+count]
+	}
+	return data, nil
+}
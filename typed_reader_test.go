@@ -2,6 +2,8 @@ package gblob_test
 
 import (
 	"bytes"
+	"encoding/binary"
+	"io"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -346,3 +348,71 @@ var _ = Describe("BigEndianReader", func() {
 		Expect(target).To(Equal([]uint8{0x34, 0x65}))
 	})
 })
+
+var _ = Describe("NativeEndianReader", func() {
+	var (
+		buffer *bytes.Buffer
+		reader gblob.TypedReader
+	)
+
+	BeforeEach(func() {
+		buffer = new(bytes.Buffer)
+		reader = gblob.NewNativeEndianReader(buffer)
+	})
+
+	Specify("ReadUint32", func() {
+		buffer.Write(binary.NativeEndian.AppendUint32(nil, 0x34217123))
+
+		value, err := reader.ReadUint32()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(value).To(Equal(uint32(0x34217123)))
+	})
+
+	Specify("ReadFloat64", func() {
+		buffer.Write(binary.NativeEndian.AppendUint64(nil, 0x401599999999999A))
+
+		value, err := reader.ReadFloat64()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(value).To(BeNumerically("~", 5.4, 0.00000001))
+	})
+})
+
+var _ = Describe("BufferReader", func() {
+	var reader gblob.TypedReader
+
+	BeforeEach(func() {
+		reader = gblob.NewBigEndianBufferReader([]uint8{
+			0x34, 0x65,
+			0x11, 0x34, 0x65,
+			0x40, 0x15, 0x99, 0x99, 0x99, 0x99, 0x99, 0x9A,
+		})
+	})
+
+	Specify("ReadUint16", func() {
+		value, err := reader.ReadUint16()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(value).To(Equal(uint16(0x3465)))
+	})
+
+	Specify("SkipBytes and ReadBytes", func() {
+		Expect(reader.SkipBytes(3)).To(Succeed())
+
+		target := make([]uint8, 2)
+		Expect(reader.ReadBytes(target)).To(Succeed())
+		Expect(target).To(Equal([]uint8{0x34, 0x65}))
+
+		value, err := reader.ReadFloat64()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(value).To(BeNumerically("~", 5.4, 0.00000001))
+	})
+
+	Specify("end of buffer", func() {
+		Expect(reader.SkipBytes(12)).To(Succeed())
+
+		_, err := reader.ReadUint16()
+		Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+
+		_, err = reader.ReadUint8()
+		Expect(err).To(MatchError(io.EOF))
+	})
+})
@@ -60,9 +60,19 @@ func NewBigEndianWriter(out io.Writer) TypedWriter {
 	}
 }
 
+// NewNativeEndianWriter returns an implementation of TypedWriter that writes
+// to the specified out Writer in the byte order of the host.
+func NewNativeEndianWriter(out io.Writer) TypedWriter {
+	return &typedWriter[NativeEndianBlock]{
+		out:    out,
+		buffer: make(NativeEndianBlock, 8), // 64 bit max
+	}
+}
+
 type typedWriter[T blockBuffer] struct {
 	out    io.Writer
 	buffer T
+	offset int64
 }
 
 func (w *typedWriter[T]) WriteUint8(value uint8) error {
@@ -126,10 +136,23 @@ func (w *typedWriter[T]) WriteFloat64(value float64) error {
 
 // WriteBytes writes len(bytes) from source to the target.
 func (w *typedWriter[T]) WriteBytes(source []byte) error {
-	_, err := w.out.Write(source)
+	n, err := w.out.Write(source)
+	w.offset += int64(n)
 	return err
 }
 
+func (w *typedWriter[T]) position() int64 {
+	return w.offset
+}
+
+// positionWriter is implemented by writers that keep track of the number of
+// bytes that have been written to their target.
+type positionWriter interface {
+
+	// position returns the number of bytes written so far.
+	position() int64
+}
+
 func (w *typedWriter[T]) flushBuffer(count int) error {
 	return w.WriteBytes(w.buffer[:count])
 }
@@ -2,6 +2,7 @@ package gblob_test
 
 import (
 	"bytes"
+	"encoding/binary"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -226,3 +227,25 @@ var _ = Describe("BigEndianWriter", func() {
 		}))
 	})
 })
+
+var _ = Describe("NativeEndianWriter", func() {
+	var (
+		buffer *bytes.Buffer
+		writer gblob.TypedWriter
+	)
+
+	BeforeEach(func() {
+		buffer = new(bytes.Buffer)
+		writer = gblob.NewNativeEndianWriter(buffer)
+	})
+
+	Specify("WriteUint32", func() {
+		Expect(writer.WriteUint32(0x34217123)).To(Succeed())
+		Expect(buffer.Bytes()).To(Equal(binary.NativeEndian.AppendUint32(nil, 0x34217123)))
+	})
+
+	Specify("WriteFloat64", func() {
+		Expect(writer.WriteFloat64(5.4)).To(Succeed())
+		Expect(binary.NativeEndian.Uint64(buffer.Bytes())).To(Equal(uint64(0x401599999999999A)))
+	})
+})